@@ -5,9 +5,13 @@ import (
 	"net/http"
 	"strconv"
 
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
 	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/config"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/metrics"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
 	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store"
 	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/url"
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
 	"github.com/ONSdigital/go-ns/server"
 	"github.com/ONSdigital/log.go/log"
 	"github.com/gorilla/mux"
@@ -33,24 +37,46 @@ type DownloadsGenerator interface {
 	Generate(ctx context.Context, datasetID, instanceID, edition, version string) error
 }
 
+// FTBClient cross-tabulates the observations of a version using the FTB table engine
+type FTBClient interface {
+	Query(ctx context.Context, query *models.CrossTabQuery) (*models.CrossTab, error)
+}
+
 // FTBDatasetAPI manages requests against a dataset
 type FTBDatasetAPI struct {
-	dataStore  store.DataStore
-	host       string
-	Router     *mux.Router
-	urlBuilder *url.Builder
+	dataStore              store.DataStore
+	defaultLimit           int
+	defaultOffset          int
+	enablePrivateEndpoints bool
+	ftbClient              FTBClient
+	host                   string
+	maxLimit               int
+	redirectLatest         bool
+	Router                 *mux.Router
+	serviceAuthToken       string
+	urlBuilder             *url.Builder
 }
 
 // CreateAndInitialiseFTBDatasetAPI create a new FTBDatasetAPI instance based on the configuration provided.
-func CreateAndInitialiseFTBDatasetAPI(ctx context.Context, cfg config.Configuration, dataStore store.DataStore, urlBuilder *url.Builder, errorChan chan error) {
+func CreateAndInitialiseFTBDatasetAPI(ctx context.Context, cfg config.Configuration, hc *healthcheck.HealthCheck, m *metrics.Metrics, dataStore store.DataStore, ftbClient FTBClient, urlBuilder *url.Builder, errorChan chan error) {
 	router := mux.NewRouter()
-	api := NewFTBDatasetAPI(ctx, cfg, router, dataStore, urlBuilder)
+	router.Use(m.Middleware)
+	router.HandleFunc("/health", hc.Handler)
+	router.Handle("/metrics", m.Handler())
+	api := NewFTBDatasetAPI(ctx, cfg, router, dataStore, ftbClient, urlBuilder)
+
+	// As with the other router middleware, requests that do not match a route are counted by wrapping their handlers
+	api.Router.NotFoundHandler = m.Middleware(api.Router.NotFoundHandler)
+	api.Router.MethodNotAllowedHandler = m.Middleware(api.Router.MethodNotAllowedHandler)
 
 	httpServer = server.New(cfg.BindAddr, api.Router)
 
 	// Disable this here to allow main to manage graceful shutdown of the entire app.
 	httpServer.HandleOSSignals = false
 
+	// Request IDs and access logs are handled by the router middleware
+	httpServer.MiddlewareOrder = nil
+
 	go func() {
 		log.Event(ctx, "Starting ftb dataset api...", log.INFO)
 		if err := httpServer.ListenAndServe(); err != nil {
@@ -61,36 +87,102 @@ func CreateAndInitialiseFTBDatasetAPI(ctx context.Context, cfg config.Configurat
 }
 
 // NewFTBDatasetAPI create a new FTB Dataset API instance and register the API routes based on the application configuration.
-func NewFTBDatasetAPI(ctx context.Context, cfg config.Configuration, router *mux.Router, dataStore store.DataStore, urlBuilder *url.Builder) *FTBDatasetAPI {
+func NewFTBDatasetAPI(ctx context.Context, cfg config.Configuration, router *mux.Router, dataStore store.DataStore, ftbClient FTBClient, urlBuilder *url.Builder) *FTBDatasetAPI {
 	api := &FTBDatasetAPI{
-		dataStore:  dataStore,
-		host:       cfg.FTBDatasetAPIURL,
-		Router:     router,
-		urlBuilder: urlBuilder,
+		dataStore:              dataStore,
+		defaultLimit:           cfg.DefaultLimit,
+		defaultOffset:          cfg.DefaultOffset,
+		enablePrivateEndpoints: cfg.EnablePrivateEndpoints,
+		ftbClient:              ftbClient,
+		host:                   cfg.FTBDatasetAPIURL,
+		maxLimit:               cfg.DefaultMaxLimit,
+		redirectLatest:         cfg.RedirectLatest,
+		Router:                 router,
+		serviceAuthToken:       cfg.ServiceAuthToken,
+		urlBuilder:             urlBuilder,
 	}
 
-	log.Event(ctx, "enabling only public endpoints for dataset api", log.INFO)
+	// Requests that do not match a route skip the router middleware, so their handlers are wrapped in it
+	api.Router.Use(requestIDHandler, accessLogHandler, deadlineHandler(cfg.RequestTimeout))
+	api.Router.NotFoundHandler = requestIDHandler(accessLogHandler(http.HandlerFunc(notFound)))
+	api.Router.MethodNotAllowedHandler = requestIDHandler(accessLogHandler(http.HandlerFunc(methodNotAllowed)))
+
+	if cfg.EnablePrivateEndpoints {
+		log.Event(ctx, "enabling authenticated private endpoints for dataset api", log.INFO)
+		api.enablePrivateDatasetEndpoints(ctx)
+	} else {
+		log.Event(ctx, "enabling only public endpoints for dataset api", log.INFO)
+	}
 	api.enablePublicEndpoints(ctx)
 
 	return api
 }
 
-// enablePublicEndpoints register only the public GET endpoints.
+// enablePublicEndpoints register the GET endpoints. In public mode they return only published resources, and in
+// private mode they return the next revision of each resource.
 func (api *FTBDatasetAPI) enablePublicEndpoints(ctx context.Context) {
 	api.get("/datasets", api.getDatasets)
 	api.get("/datasets/{dataset_id}", api.getDataset)
 	api.get("/datasets/{dataset_id}/editions", api.getEditions)
-	api.get("/datasets/{dataset_id}/editions/{edition}", api.getEdition)
-	api.get("/datasets/{dataset_id}/editions/{edition}/versions", api.getVersions)
-	api.get("/datasets/{dataset_id}/editions/{edition}/versions/{version}", api.getVersion)
-	api.get("/datasets/{dataset_id}/editions/{edition}/versions/{version}/metadata", api.getMetadata)
-	api.get("/datasets/{dataset_id}/editions/{edition}/versions/{version}/dimensions", api.getDimensions)
-	api.get("/datasets/{dataset_id}/editions/{edition}/versions/{version}/dimensions/{dimension}/options", api.getDimensionOptions)
+	api.get("/datasets/{dataset_id}/editions/{edition}", api.resolveLatest(api.getEdition))
+	api.get("/datasets/{dataset_id}/editions/{edition}/versions", api.resolveLatest(api.getVersions))
+	api.get("/datasets/{dataset_id}/editions/{edition}/versions/{version}", api.resolveLatest(api.getVersion))
+	api.get("/datasets/{dataset_id}/editions/{edition}/versions/{version}/metadata", api.resolveLatest(api.getMetadata))
+	api.get("/datasets/{dataset_id}/editions/{edition}/versions/{version}/dimensions", api.resolveLatest(api.getDimensions))
+	api.get("/datasets/{dataset_id}/editions/{edition}/versions/{version}/dimensions/{dimension}/options", api.resolveLatest(api.getDimensionOptions))
+	api.get("/datasets/{dataset_id}/editions/{edition}/versions/{version}/observations", api.resolveLatest(api.getObservations))
+	api.get("/search", api.search)
+}
+
+// enablePrivateDatasetEndpoints register the endpoints used to create and update resources.
+func (api *FTBDatasetAPI) enablePrivateDatasetEndpoints(ctx context.Context) {
+	api.post("/datasets/{dataset_id}", api.addDataset)
+	api.put("/datasets/{dataset_id}", api.putDataset)
+	api.put("/datasets/{dataset_id}/editions/{edition}/versions/{version}", api.putVersion)
+	api.post("/datasets/{dataset_id}/editions/{edition}/versions/{version}/tables", api.addTable)
 }
 
 // get register a GET http.HandlerFunc.
 func (api *FTBDatasetAPI) get(path string, handler http.HandlerFunc) {
-	api.Router.HandleFunc(path, handler).Methods("GET")
+	api.Router.HandleFunc(path, api.authenticate(handler)).Methods("GET")
+}
+
+// post register a POST http.HandlerFunc.
+func (api *FTBDatasetAPI) post(path string, handler http.HandlerFunc) {
+	api.Router.HandleFunc(path, api.authenticate(handler)).Methods("POST")
+}
+
+// put register a PUT http.HandlerFunc.
+func (api *FTBDatasetAPI) put(path string, handler http.HandlerFunc) {
+	api.Router.HandleFunc(path, api.authenticate(handler)).Methods("PUT")
+}
+
+// getPaginationParameters reads the offset and limit query parameters from the request, falling back
+// to the configured defaults. Negative values and limits above the configured maximum are rejected.
+func (api *FTBDatasetAPI) getPaginationParameters(r *http.Request, logData log.Data) (offset int, limit int, err error) {
+	offset = api.defaultOffset
+	limit = api.defaultLimit
+
+	if offsetParameter := r.URL.Query().Get("offset"); offsetParameter != "" {
+		logData["offset"] = offsetParameter
+		if offset, err = strconv.Atoi(offsetParameter); err != nil || offset < 0 {
+			return 0, 0, errs.ErrInvalidQueryParameter
+		}
+	}
+
+	if limitParameter := r.URL.Query().Get("limit"); limitParameter != "" {
+		logData["limit"] = limitParameter
+		if limit, err = strconv.Atoi(limitParameter); err != nil || limit < 0 {
+			return 0, 0, errs.ErrInvalidQueryParameter
+		}
+	}
+
+	if limit > api.maxLimit {
+		logData["max_limit"] = api.maxLimit
+		return 0, 0, errs.ErrInvalidQueryParameter
+	}
+
+	return offset, limit, nil
 }
 
 func setJSONContentType(w http.ResponseWriter) {
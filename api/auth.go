@@ -0,0 +1,63 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	"github.com/ONSdigital/go-ns/common"
+	"github.com/ONSdigital/log.go/log"
+)
+
+// authenticate wraps a handler so that, in private mode, it is only called for requests carrying the service auth
+// token as a bearer token. In public mode the handler is returned unchanged.
+func (api *FTBDatasetAPI) authenticate(handler http.HandlerFunc) http.HandlerFunc {
+	if !api.enablePrivateEndpoints {
+		return handler
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		logData := log.Data{"method": r.Method, "path": r.URL.Path}
+
+		header := r.Header.Get(common.AuthHeaderKey)
+		if header == "" {
+			log.Event(ctx, "request to private endpoint has no authorization header", log.ERROR, log.Error(errs.ErrNoAuthHeader), logData)
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			handleAPIErr(ctx, w, r, errs.ErrNoAuthHeader, logData)
+			return
+		}
+
+		if !api.isServiceAuthToken(header) {
+			log.Event(ctx, "request to private endpoint has an invalid bearer token", log.ERROR, log.Error(errs.ErrUnauthorised), logData)
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			handleAPIErr(ctx, w, r, errs.ErrUnauthorised, logData)
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+// isServiceAuthToken checks that the value of an authorization header is a bearer token matching the service auth
+// token, comparing the tokens in constant time
+func (api *FTBDatasetAPI) isServiceAuthToken(header string) bool {
+	prefix := len(common.BearerPrefix)
+	if len(header) <= prefix || !strings.EqualFold(header[:prefix], common.BearerPrefix) {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(header[prefix:]), []byte(api.serviceAuthToken)) == 1
+}
+
+// state returns the state a resource must be in to be returned. In public mode only published resources are
+// returned, and in private mode the next revision of a resource is returned whatever its state.
+func (api *FTBDatasetAPI) state() string {
+	if api.enablePrivateEndpoints {
+		return ""
+	}
+
+	return models.PublishedState
+}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/config"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store"
+	storetest "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store/datastoretest"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/url"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const testServiceAuthToken = "a-service-auth-token"
+
+// newPublicAPI returns an API in public mode backed by storer, with a service auth token for previewing collections
+func newPublicAPI(storer store.Storer) *FTBDatasetAPI {
+	cfg, err := config.Get()
+	So(err, ShouldBeNil)
+
+	public := *cfg
+	public.ServiceAuthToken = testServiceAuthToken
+	return NewFTBDatasetAPI(context.Background(), public, mux.NewRouter(), store.DataStore{Backend: storer}, nil, url.NewBuilder(cfg.WebsiteURL))
+}
+
+// newPrivateAPI returns an API in private mode backed by storer. The shared configuration is copied so that it is
+// left in public mode for other tests.
+func newPrivateAPI(storer store.Storer) *FTBDatasetAPI {
+	cfg, err := config.Get()
+	So(err, ShouldBeNil)
+
+	private := *cfg
+	private.EnablePrivateEndpoints = true
+	private.ServiceAuthToken = testServiceAuthToken
+	return NewFTBDatasetAPI(context.Background(), private, mux.NewRouter(), store.DataStore{Backend: storer}, nil, url.NewBuilder(cfg.WebsiteURL))
+}
+
+func TestPublicAndPrivateModes(t *testing.T) {
+
+	Convey("Given a published dataset and edition with unpublished next revisions", t, func() {
+		datasetDoc := func() *models.DatasetUpdate {
+			return &models.DatasetUpdate{
+				ID:      "People",
+				Current: &models.Dataset{Title: "People", State: models.PublishedState},
+				Next:    &models.Dataset{Title: "People by age", State: models.CreatedState},
+			}
+		}
+
+		var authorised bool
+		var editionState string
+		mockedDataStore := &storetest.StorerMock{
+			GetDatasetFunc: func(ctx context.Context, ID string) (*models.DatasetUpdate, error) {
+				return datasetDoc(), nil
+			},
+			GetDatasetsFunc: func(ctx context.Context, offset, limit int, isAuthorised bool) (*models.DatasetUpdateResults, error) {
+				authorised = isAuthorised
+				return &models.DatasetUpdateResults{Count: 1, Items: []models.DatasetUpdate{*datasetDoc()}, Limit: limit, TotalCount: 1}, nil
+			},
+			CheckDatasetExistsFunc: func(ctx context.Context, ID, state string) error {
+				return nil
+			},
+			GetEditionFunc: func(ctx context.Context, ID, editionID, state string) (*models.EditionUpdate, error) {
+				editionState = state
+				return &models.EditionUpdate{
+					ID:      "8a6b7f4e",
+					Current: &models.Edition{Edition: "2011", State: models.PublishedState},
+					Next:    &models.Edition{Edition: "2011", State: models.EditionConfirmedState},
+				}, nil
+			},
+		}
+
+		Convey("When the API is in public mode", func() {
+			api := newPublicAPI(mockedDataStore)
+
+			Convey("Then the current revision of the dataset is returned without authentication", func() {
+				w := httptest.NewRecorder()
+				api.Router.ServeHTTP(w, httptest.NewRequest("GET", "/datasets/People", nil))
+				So(w.Code, ShouldEqual, http.StatusOK)
+
+				var dataset models.Dataset
+				So(json.Unmarshal(w.Body.Bytes(), &dataset), ShouldBeNil)
+				So(dataset.ID, ShouldEqual, "People")
+				So(dataset.Title, ShouldEqual, "People")
+			})
+
+			Convey("Then only published datasets are listed", func() {
+				w := httptest.NewRecorder()
+				api.Router.ServeHTTP(w, httptest.NewRequest("GET", "/datasets", nil))
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(authorised, ShouldBeFalse)
+
+				var results models.DatasetResults
+				So(json.Unmarshal(w.Body.Bytes(), &results), ShouldBeNil)
+				So(results.Count, ShouldEqual, 1)
+				So(results.Items[0].Title, ShouldEqual, "People")
+			})
+
+			Convey("Then the current revision of a published edition is returned", func() {
+				w := httptest.NewRecorder()
+				api.Router.ServeHTTP(w, httptest.NewRequest("GET", "/datasets/People/editions/2011", nil))
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(editionState, ShouldEqual, models.PublishedState)
+
+				var edition models.Edition
+				So(json.Unmarshal(w.Body.Bytes(), &edition), ShouldBeNil)
+				So(edition.State, ShouldEqual, models.PublishedState)
+			})
+
+			Convey("Then a dataset that has not been published is not found", func() {
+				mockedDataStore.GetDatasetFunc = func(ctx context.Context, ID string) (*models.DatasetUpdate, error) {
+					return &models.DatasetUpdate{ID: ID, Next: &models.Dataset{State: models.CreatedState}}, nil
+				}
+
+				w := httptest.NewRecorder()
+				api.Router.ServeHTTP(w, httptest.NewRequest("GET", "/datasets/People", nil))
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+			})
+		})
+
+		Convey("When the API is in private mode", func() {
+			api := newPrivateAPI(mockedDataStore)
+
+			Convey("Then a request without an authorization header is rejected", func() {
+				w := httptest.NewRecorder()
+				api.Router.ServeHTTP(w, httptest.NewRequest("GET", "/datasets/People", nil))
+				So(w.Code, ShouldEqual, http.StatusUnauthorized)
+				So(w.Header().Get("WWW-Authenticate"), ShouldEqual, "Bearer")
+				So(w.Body.String(), ShouldContainSubstring, errs.ErrNoAuthHeader.Error())
+				So(mockedDataStore.GetDatasetCalls(), ShouldHaveLength, 0)
+			})
+
+			Convey("Then a request with the wrong bearer token is rejected", func() {
+				for _, header := range []string{"Bearer the-wrong-token", testServiceAuthToken, "Basic " + testServiceAuthToken} {
+					r := httptest.NewRequest("GET", "/datasets/People", nil)
+					r.Header.Set("Authorization", header)
+					w := httptest.NewRecorder()
+					api.Router.ServeHTTP(w, r)
+					So(w.Code, ShouldEqual, http.StatusUnauthorized)
+					So(w.Body.String(), ShouldContainSubstring, errs.ErrUnauthorised.Error())
+				}
+				So(mockedDataStore.GetDatasetCalls(), ShouldHaveLength, 0)
+			})
+
+			Convey("Then an authenticated request is given the next revision of the dataset", func() {
+				r := httptest.NewRequest("GET", "/datasets/People", nil)
+				r.Header.Set("Authorization", "Bearer "+testServiceAuthToken)
+				w := httptest.NewRecorder()
+				api.Router.ServeHTTP(w, r)
+				So(w.Code, ShouldEqual, http.StatusOK)
+
+				var dataset models.Dataset
+				So(json.Unmarshal(w.Body.Bytes(), &dataset), ShouldBeNil)
+				So(dataset.Title, ShouldEqual, "People by age")
+			})
+
+			Convey("Then an authenticated request lists unpublished datasets and editions", func() {
+				r := httptest.NewRequest("GET", "/datasets", nil)
+				r.Header.Set("Authorization", "Bearer "+testServiceAuthToken)
+				api.Router.ServeHTTP(httptest.NewRecorder(), r)
+				So(authorised, ShouldBeTrue)
+
+				r = httptest.NewRequest("GET", "/datasets/People/editions/2011", nil)
+				r.Header.Set("Authorization", "bearer "+testServiceAuthToken)
+				w := httptest.NewRecorder()
+				api.Router.ServeHTTP(w, r)
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(editionState, ShouldEqual, "")
+
+				var edition models.Edition
+				So(json.Unmarshal(w.Body.Bytes(), &edition), ShouldBeNil)
+				So(edition.State, ShouldEqual, models.EditionConfirmedState)
+			})
+		})
+	})
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// writeBody writes the body of a successful response along with a strong ETag, computed from a hash of the body,
+// and a Last-Modified header when the time the resource was last updated is known. A 304 Not Modified is written
+// in place of the body when the conditional headers of the request show the client already has it.
+func writeBody(w http.ResponseWriter, r *http.Request, b []byte, lastModified time.Time) error {
+	etag := createETag(b)
+	w.Header().Set("ETag", etag)
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if isNotModified(r, etag, lastModified) {
+		w.Header().Del("Content-Type")
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	_, err := w.Write(b)
+	return err
+}
+
+func createETag(b []byte) string {
+	hash := sha256.Sum256(b)
+	return `"` + hex.EncodeToString(hash[:16]) + `"`
+}
+
+// isNotModified evaluates the If-None-Match and If-Modified-Since headers of a request as described in RFC 7232.
+// If-Modified-Since is ignored when If-None-Match is given, as an entity tag is a more accurate validator.
+func isNotModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if values := r.Header["If-None-Match"]; len(values) > 0 {
+		return etagMatches(strings.Join(values, ","), etag)
+	}
+
+	since := r.Header.Get("If-Modified-Since")
+	if since == "" || lastModified.IsZero() {
+		return false
+	}
+
+	t, err := http.ParseTime(since)
+	if err != nil {
+		return false
+	}
+
+	// Last-Modified only has a precision of seconds
+	return !lastModified.Truncate(time.Second).After(t)
+}
+
+// etagMatches reports whether a list of entity tags from an If-None-Match header matches the etag, using the weak
+// comparison that RFC 7232 requires for If-None-Match
+func etagMatches(list, etag string) bool {
+	for _, tag := range strings.Split(list, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// latest returns the latest of the times a resource and the resources it is made from were last updated
+func latest(times ...time.Time) time.Time {
+	var t time.Time
+	for _, candidate := range times {
+		if candidate.After(t) {
+			t = candidate
+		}
+	}
+
+	return t
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWriteBody(t *testing.T) {
+
+	Convey("Given a response body for a resource last updated at a known time", t, func() {
+		body := []byte(`{"id":"People"}`)
+		lastModified := time.Date(2020, 10, 1, 9, 0, 0, 500, time.UTC)
+		etag := createETag(body)
+
+		write := func(headers map[string]string) *httptest.ResponseRecorder {
+			r := httptest.NewRequest("GET", "/datasets/People", nil)
+			for name, value := range headers {
+				r.Header.Set(name, value)
+			}
+
+			w := httptest.NewRecorder()
+			setJSONContentType(w)
+			So(writeBody(w, r, body, lastModified), ShouldBeNil)
+			return w
+		}
+
+		Convey("When the request is not conditional", func() {
+			w := write(nil)
+
+			Convey("Then the body is written with its validators", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Body.Bytes(), ShouldResemble, body)
+				So(w.Header().Get("ETag"), ShouldEqual, etag)
+				So(w.Header().Get("Last-Modified"), ShouldEqual, "Thu, 01 Oct 2020 09:00:00 GMT")
+			})
+		})
+
+		Convey("When the request has a matching If-None-Match header", func() {
+			tests := []string{etag, `"other", ` + etag, "W/" + etag, "*"}
+
+			Convey("Then a 304 Not Modified is written without a body", func() {
+				for _, ifNoneMatch := range tests {
+					w := write(map[string]string{"If-None-Match": ifNoneMatch})
+					So(w.Code, ShouldEqual, http.StatusNotModified)
+					So(w.Body.Len(), ShouldEqual, 0)
+					So(w.Header().Get("ETag"), ShouldEqual, etag)
+					So(w.Header().Get("Content-Type"), ShouldBeEmpty)
+				}
+			})
+		})
+
+		Convey("When the request has an If-None-Match header that does not match", func() {
+			w := write(map[string]string{"If-None-Match": `"other"`, "If-Modified-Since": "Fri, 02 Oct 2020 09:00:00 GMT"})
+
+			Convey("Then the body is written and If-Modified-Since is ignored", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Body.Bytes(), ShouldResemble, body)
+			})
+		})
+
+		Convey("When the request has an If-Modified-Since header", func() {
+			tests := map[string]int{
+				"Thu, 01 Oct 2020 09:00:00 GMT": http.StatusNotModified,
+				"Fri, 02 Oct 2020 09:00:00 GMT": http.StatusNotModified,
+				"Thu, 01 Oct 2020 08:59:59 GMT": http.StatusOK,
+				"not a date":                    http.StatusOK,
+			}
+
+			Convey("Then a 304 Not Modified is only written when the resource has not changed since", func() {
+				for ifModifiedSince, expected := range tests {
+					So(write(map[string]string{"If-Modified-Since": ifModifiedSince}).Code, ShouldEqual, expected)
+				}
+			})
+		})
+
+		Convey("When the time the resource was last updated is not known", func() {
+			lastModified = time.Time{}
+			w := write(map[string]string{"If-Modified-Since": "Fri, 02 Oct 2020 09:00:00 GMT"})
+
+			Convey("Then the body is written without a Last-Modified header", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Last-Modified"), ShouldBeEmpty)
+				So(w.Header().Get("ETag"), ShouldEqual, etag)
+			})
+		})
+	})
+}
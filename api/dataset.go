@@ -1,9 +1,10 @@
 package api
 
 import (
-	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"time"
 
 	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
 	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
@@ -11,57 +12,67 @@ import (
 	"github.com/gorilla/mux"
 )
 
-var (
-	// errors that should return a 403 status
-	datasetsForbidden = map[error]bool{
-		errs.ErrDeletePublishedDatasetForbidden: true,
-		errs.ErrAddDatasetAlreadyExists:         true,
-	}
+func (api *FTBDatasetAPI) getDatasets(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logData := log.Data{}
 
-	// errors that should return a 204 status
-	datasetsNoContent = map[error]bool{
-		errs.ErrDeleteDatasetNotFound: true,
+	contentType := negotiateContentType(r, jsonContentType, csvContentType)
+	if contentType == "" {
+		logData["accept"] = r.Header.Get("Accept")
+		log.Event(ctx, "api endpoint getDatasets received an unsupported content type", log.ERROR, log.Error(errs.ErrNotAcceptable), logData)
+		handleAPIErr(ctx, w, r, errs.ErrNotAcceptable, logData)
+		return
 	}
 
-	// errors that should return a 400 status
-	datasetsBadRequest = map[error]bool{
-		errs.ErrAddUpdateDatasetBadRequest: true,
+	offset, limit, err := api.getPaginationParameters(r, logData)
+	if err != nil {
+		log.Event(ctx, "api endpoint getDatasets received invalid pagination parameters", log.ERROR, log.Error(err), logData)
+		handleAPIErr(ctx, w, r, err, logData)
+		return
 	}
 
-	// errors that should return a 404 status
-	resourcesNotFound = map[error]bool{
-		errs.ErrDatasetNotFound:  true,
-		errs.ErrEditionsNotFound: true,
-	}
-)
-
-func (api *FTBDatasetAPI) getDatasets(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-
-	datasets, err := api.dataStore.Backend.GetDatasets(ctx)
+	results, err := api.dataStore.Backend.GetDatasets(ctx, offset, limit, api.enablePrivateEndpoints)
 	if err != nil {
-		log.Event(ctx, "api endpoint getDatasets datastore.GetDatasets returned an error", log.ERROR, log.Error(err))
-		handleDatasetAPIErr(ctx, err, w, nil)
+		log.Event(ctx, "api endpoint getDatasets datastore.GetDatasets returned an error", log.ERROR, log.Error(err), logData)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
-	logData := log.Data{}
 
-	datasetsResponse := &models.DatasetUpdateResults{Items: datasets}
+	datasetsResponse := &models.DatasetResults{
+		Items:      []*models.Dataset{},
+		Limit:      results.Limit,
+		Offset:     results.Offset,
+		TotalCount: results.TotalCount,
+	}
+
+	var lastModified time.Time
+	for i := range results.Items {
+		if dataset := results.Items[i].Revision(api.enablePrivateEndpoints); dataset != nil {
+			datasetsResponse.Items = append(datasetsResponse.Items, dataset)
+			lastModified = latest(lastModified, dataset.LastUpdated)
+		}
+	}
+	datasetsResponse.Count = len(datasetsResponse.Items)
 
-	b, err := json.Marshal(datasetsResponse)
+	var b []byte
+	if contentType == csvContentType {
+		b, err = datasetsResponse.MarshalCSV()
+	} else {
+		b, err = json.Marshal(datasetsResponse)
+	}
 	if err != nil {
 		log.Event(ctx, "api endpoint getDatasets failed to marshal dataset resource into bytes", log.ERROR, log.Error(err), logData)
-		handleDatasetAPIErr(ctx, err, w, nil)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
-	setJSONContentType(w)
-	if _, err = w.Write(b); err != nil {
-		log.Event(ctx, "api endpoint getDatasets error writing response body", log.ERROR, log.Error(err))
-		handleDatasetAPIErr(ctx, err, w, nil)
+	setContentType(w, contentType)
+	if err = writeBody(w, r, b, lastModified); err != nil {
+		log.Event(ctx, "api endpoint getDatasets error writing response body", log.ERROR, log.Error(err), logData)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
-	log.Event(ctx, "api endpoint getDatasets request successful", log.INFO)
+	log.Event(ctx, "api endpoint getDatasets request successful", log.INFO, logData)
 }
 
 func (api *FTBDatasetAPI) getDataset(w http.ResponseWriter, r *http.Request) {
@@ -70,18 +81,18 @@ func (api *FTBDatasetAPI) getDataset(w http.ResponseWriter, r *http.Request) {
 	datasetID := vars["dataset_id"]
 	logData := log.Data{"dataset_id": datasetID}
 
-	log.Event(ctx, "got this far 1")
-	dataset, err := api.dataStore.Backend.GetDataset(datasetID)
+	datasetDoc, err := api.dataStore.Backend.GetDataset(ctx, datasetID)
 	if err != nil {
 		log.Event(ctx, "getDataset endpoint: dataStore.Backend.GetDataset returned an error", log.ERROR, log.Error(err), logData)
-		handleDatasetAPIErr(ctx, err, w, logData)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
-	// Check dataset exists
+	// In public mode a dataset that has not been published is not found
+	dataset := datasetDoc.Revision(api.enablePrivateEndpoints)
 	if dataset == nil {
 		log.Event(ctx, "getDataset endpoint: published or unpublished dataset not found", log.INFO, logData)
-		handleDatasetAPIErr(ctx, errs.ErrDatasetNotFound, w, logData)
+		handleAPIErr(ctx, w, r, errs.ErrDatasetNotFound, logData)
 		return
 	}
 
@@ -91,39 +102,157 @@ func (api *FTBDatasetAPI) getDataset(w http.ResponseWriter, r *http.Request) {
 	b, err = json.Marshal(dataset)
 	if err != nil {
 		log.Event(ctx, "getDataset endpoint: failed to marshal dataset resource into bytes", log.ERROR, log.Error(err), logData)
-		handleDatasetAPIErr(ctx, err, w, logData)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
 	setJSONContentType(w)
-	if _, err = w.Write(b); err != nil {
+	if err = writeBody(w, r, b, dataset.LastUpdated); err != nil {
 		log.Event(ctx, "getDataset endpoint: error writing bytes to response", log.ERROR, log.Error(err), logData)
-		handleDatasetAPIErr(ctx, err, w, logData)
+		handleAPIErr(ctx, w, r, err, logData)
 	}
 	log.Event(ctx, "getDataset endpoint: request successful", log.INFO, logData)
 }
 
-func handleDatasetAPIErr(ctx context.Context, err error, w http.ResponseWriter, data log.Data) {
-	if data == nil {
-		data = log.Data{}
-	}
-
-	var status int
-	switch {
-	case datasetsForbidden[err]:
-		status = http.StatusForbidden
-	case datasetsNoContent[err]:
-		status = http.StatusNoContent
-	case datasetsBadRequest[err]:
-		status = http.StatusBadRequest
-	case resourcesNotFound[err]:
-		status = http.StatusNotFound
-	default:
-		err = errs.ErrInternalServer
-		status = http.StatusInternalServerError
-	}
-
-	data["responseStatus"] = status
-	log.Event(ctx, "request unsuccessful", log.ERROR, log.Error(err), data)
-	http.Error(w, err.Error(), status)
+func (api *FTBDatasetAPI) addDataset(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	datasetID := vars["dataset_id"]
+	logData := log.Data{"dataset_id": datasetID}
+
+	b, err := func() ([]byte, error) {
+		_, err := api.dataStore.Backend.GetDataset(ctx, datasetID)
+		if err != nil {
+			if err != errs.ErrDatasetNotFound {
+				log.Event(ctx, "addDataset endpoint: error checking if dataset exists", log.ERROR, log.Error(err), logData)
+				return nil, err
+			}
+		} else {
+			log.Event(ctx, "addDataset endpoint: unable to create a dataset that already exists", log.ERROR, log.Error(errs.ErrAddDatasetAlreadyExists), logData)
+			return nil, errs.ErrAddDatasetAlreadyExists
+		}
+
+		dataset, err := models.CreateDataset(r.Body)
+		if err != nil {
+			log.Event(ctx, "addDataset endpoint: failed to model dataset resource based on request", log.ERROR, log.Error(err), logData)
+			return nil, errs.ErrAddUpdateDatasetBadRequest
+		}
+
+		if err = models.ValidateDataset(dataset); err != nil {
+			log.Event(ctx, "addDataset endpoint: dataset resource failed validation", log.ERROR, log.Error(err), logData)
+			return nil, errs.ErrAddUpdateDatasetBadRequest
+		}
+
+		dataset.State = models.CreatedState
+		dataset.ID = datasetID
+
+		if dataset.Links == nil {
+			dataset.Links = &models.DatasetLinks{}
+		}
+
+		dataset.Links.Editions = &models.LinkObject{
+			HRef: fmt.Sprintf("%s/datasets/%s/editions", api.host, datasetID),
+		}
+
+		dataset.Links.Self = &models.LinkObject{
+			HRef: fmt.Sprintf("%s/datasets/%s", api.host, datasetID),
+		}
+
+		// Remove latest version from new dataset resource, this cannot be added at this point
+		dataset.Links.LatestVersion = nil
+
+		dataset.LastUpdated = time.Now()
+
+		datasetDoc := &models.DatasetUpdate{
+			ID:   datasetID,
+			Next: dataset,
+		}
+
+		if err = api.dataStore.Backend.UpsertDataset(ctx, datasetID, datasetDoc); err != nil {
+			log.Event(ctx, "addDataset endpoint: failed to insert dataset resource to datastore", log.ERROR, log.Error(err), logData)
+			return nil, err
+		}
+
+		// the revision just created is returned, as it would be by getDataset in private mode
+		b, err := json.Marshal(dataset)
+		if err != nil {
+			log.Event(ctx, "addDataset endpoint: failed to marshal dataset resource into bytes", log.ERROR, log.Error(err), logData)
+			return nil, err
+		}
+
+		return b, nil
+	}()
+	if err != nil {
+		handleAPIErr(ctx, w, r, err, logData)
+		return
+	}
+
+	setJSONContentType(w)
+	w.WriteHeader(http.StatusCreated)
+	if _, err = w.Write(b); err != nil {
+		log.Event(ctx, "addDataset endpoint: error writing bytes to response", log.ERROR, log.Error(err), logData)
+	}
+	log.Event(ctx, "addDataset endpoint: request completed successfully", log.INFO, logData)
+}
+
+func (api *FTBDatasetAPI) putDataset(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	datasetID := vars["dataset_id"]
+	logData := log.Data{"dataset_id": datasetID}
+
+	b, err := func() ([]byte, error) {
+		dataset, err := models.CreateDataset(r.Body)
+		if err != nil {
+			log.Event(ctx, "putDataset endpoint: failed to model dataset resource based on request", log.ERROR, log.Error(err), logData)
+			return nil, errs.ErrAddUpdateDatasetBadRequest
+		}
+
+		if err = models.ValidateDataset(dataset); err != nil {
+			log.Event(ctx, "putDataset endpoint: dataset resource failed validation", log.ERROR, log.Error(err), logData)
+			return nil, errs.ErrAddUpdateDatasetBadRequest
+		}
+
+		currentDataset, err := api.dataStore.Backend.GetDataset(ctx, datasetID)
+		if err != nil {
+			log.Event(ctx, "putDataset endpoint: datastore.getDataset returned an error", log.ERROR, log.Error(err), logData)
+			return nil, err
+		}
+
+		var currentState string
+		if currentDataset.Next != nil {
+			currentState = currentDataset.Next.State
+		}
+
+		if err = api.dataStore.Backend.UpdateDataset(ctx, datasetID, dataset, currentState); err != nil {
+			log.Event(ctx, "putDataset endpoint: failed to update dataset resource", log.ERROR, log.Error(err), logData)
+			return nil, err
+		}
+
+		// the updated revision is returned, as it would be by getDataset in private mode
+		updatedDataset, err := api.dataStore.Backend.GetDataset(ctx, datasetID)
+		if err != nil {
+			log.Event(ctx, "putDataset endpoint: failed to get updated dataset resource", log.ERROR, log.Error(err), logData)
+			return nil, err
+		}
+
+		b, err := json.Marshal(updatedDataset.Revision(true))
+		if err != nil {
+			log.Event(ctx, "putDataset endpoint: failed to marshal dataset resource into bytes", log.ERROR, log.Error(err), logData)
+			return nil, err
+		}
+
+		return b, nil
+	}()
+	if err != nil {
+		handleAPIErr(ctx, w, r, err, logData)
+		return
+	}
+
+	setJSONContentType(w)
+	w.WriteHeader(http.StatusOK)
+	if _, err = w.Write(b); err != nil {
+		log.Event(ctx, "putDataset endpoint: error writing bytes to response", log.ERROR, log.Error(err), logData)
+	}
+	log.Event(ctx, "putDataset endpoint: request successful", log.INFO, logData)
 }
@@ -0,0 +1,385 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/memory"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	storetest "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store/datastoretest"
+	"github.com/ONSdigital/log.go/log"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetPaginationParameters(t *testing.T) {
+
+	Convey("Given an API with the default pagination configuration", t, func() {
+		api := newPublicAPI(&storetest.StorerMock{})
+		paginate := func(query string) (int, int, error) {
+			r := httptest.NewRequest("GET", "http://localhost:10400/datasets"+query, nil)
+			return api.getPaginationParameters(r, log.Data{})
+		}
+
+		Convey("When no offset or limit is given", func() {
+			offset, limit, err := paginate("")
+
+			Convey("Then the defaults are used", func() {
+				So(err, ShouldBeNil)
+				So(offset, ShouldEqual, api.defaultOffset)
+				So(limit, ShouldEqual, api.defaultLimit)
+			})
+		})
+
+		Convey("When an offset and a limit are given", func() {
+			offset, limit, err := paginate("?offset=40&limit=10")
+
+			Convey("Then they are used", func() {
+				So(err, ShouldBeNil)
+				So(offset, ShouldEqual, 40)
+				So(limit, ShouldEqual, 10)
+			})
+		})
+
+		Convey("When the limit is the maximum", func() {
+			_, limit, err := paginate("?limit=1000")
+
+			Convey("Then it is used", func() {
+				So(err, ShouldBeNil)
+				So(limit, ShouldEqual, api.maxLimit)
+			})
+		})
+
+		Convey("When the offset or limit is negative", func() {
+			Convey("Then they are invalid", func() {
+				_, _, err := paginate("?offset=-1")
+				So(err, ShouldEqual, errs.ErrInvalidQueryParameter)
+
+				_, _, err = paginate("?limit=-1")
+				So(err, ShouldEqual, errs.ErrInvalidQueryParameter)
+			})
+		})
+
+		Convey("When the offset or limit is not a number", func() {
+			Convey("Then they are invalid", func() {
+				_, _, err := paginate("?offset=ten")
+				So(err, ShouldEqual, errs.ErrInvalidQueryParameter)
+
+				_, _, err = paginate("?limit=1.5")
+				So(err, ShouldEqual, errs.ErrInvalidQueryParameter)
+			})
+		})
+
+		Convey("When the limit is above the maximum", func() {
+			logData := log.Data{}
+			r := httptest.NewRequest("GET", "http://localhost:10400/datasets?limit=1001", nil)
+			_, _, err := api.getPaginationParameters(r, logData)
+
+			Convey("Then it is invalid, and the maximum is logged", func() {
+				So(err, ShouldEqual, errs.ErrInvalidQueryParameter)
+				So(logData["max_limit"], ShouldEqual, api.maxLimit)
+			})
+		})
+	})
+}
+
+func TestGetDatasets(t *testing.T) {
+
+	Convey("Given three published datasets", t, func() {
+		var datasets []models.DatasetUpdate
+		for _, id := range []string{"Households", "People", "Regions"} {
+			datasets = append(datasets, models.DatasetUpdate{ID: id, Current: &models.Dataset{ID: id, State: models.PublishedState}})
+		}
+
+		mockedDataStore := &storetest.StorerMock{
+			GetDatasetsFunc: func(ctx context.Context, offset, limit int, isAuthorised bool) (*models.DatasetUpdateResults, error) {
+				items := []models.DatasetUpdate{}
+				for i := offset; i < len(datasets) && len(items) < limit; i++ {
+					items = append(items, datasets[i])
+				}
+				return &models.DatasetUpdateResults{Count: len(items), Items: items, Limit: limit, Offset: offset, TotalCount: len(datasets)}, nil
+			},
+		}
+		api := newPublicAPI(mockedDataStore)
+
+		get := func(query string) (*httptest.ResponseRecorder, *models.DatasetResults) {
+			r := httptest.NewRequest("GET", "http://localhost:10400/datasets"+query, nil)
+			w := httptest.NewRecorder()
+			api.Router.ServeHTTP(w, r)
+
+			var results models.DatasetResults
+			if w.Code == http.StatusOK {
+				So(json.Unmarshal(w.Body.Bytes(), &results), ShouldBeNil)
+			}
+			return w, &results
+		}
+
+		Convey("When they are requested without pagination parameters", func() {
+			w, results := get("")
+
+			Convey("Then they are all returned with the default offset and limit", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(mockedDataStore.GetDatasetsCalls(), ShouldHaveLength, 1)
+				So(mockedDataStore.GetDatasetsCalls()[0].Offset, ShouldEqual, api.defaultOffset)
+				So(mockedDataStore.GetDatasetsCalls()[0].Limit, ShouldEqual, api.defaultLimit)
+				So(results.Count, ShouldEqual, 3)
+				So(results.Limit, ShouldEqual, api.defaultLimit)
+				So(results.TotalCount, ShouldEqual, 3)
+			})
+		})
+
+		Convey("When a page of them is requested", func() {
+			w, results := get("?offset=1&limit=1")
+
+			Convey("Then only that page is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(results.Count, ShouldEqual, 1)
+				So(results.Items[0].ID, ShouldEqual, "People")
+				So(results.Offset, ShouldEqual, 1)
+				So(results.Limit, ShouldEqual, 1)
+				So(results.TotalCount, ShouldEqual, 3)
+			})
+		})
+
+		Convey("When the offset is past the last of them", func() {
+			w, results := get("?offset=5")
+
+			Convey("Then no datasets are returned, with the total count of them", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(results.Count, ShouldEqual, 0)
+				So(results.Items, ShouldBeEmpty)
+				So(results.Offset, ShouldEqual, 5)
+				So(results.TotalCount, ShouldEqual, 3)
+			})
+		})
+
+		Convey("When the pagination parameters are invalid", func() {
+			Convey("Then a bad request is returned without getting the datasets", func() {
+				for _, query := range []string{"?offset=-1", "?limit=-5", "?offset=first", "?limit=all", "?limit=1001"} {
+					w, _ := get(query)
+					So(w.Code, ShouldEqual, http.StatusBadRequest)
+				}
+				So(mockedDataStore.GetDatasetsCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+}
+
+func TestGetDatasetsRevisions(t *testing.T) {
+
+	Convey("Given a dataset with only a next revision between two with a current revision, in the memory store", t, func() {
+		ctx := context.Background()
+		memoryStore := memory.New()
+		for _, datasetDoc := range []*models.DatasetUpdate{
+			{ID: "Households", Current: &models.Dataset{Title: "Households", State: models.PublishedState}},
+			{ID: "People", Next: &models.Dataset{Title: "People", State: models.CreatedState}},
+			{ID: "Regions", Current: &models.Dataset{Title: "Regions", State: models.PublishedState}, Next: &models.Dataset{Title: "Regions", State: models.PublishedState}},
+		} {
+			So(memoryStore.UpsertDataset(ctx, datasetDoc.ID, datasetDoc), ShouldBeNil)
+		}
+
+		get := func(api *FTBDatasetAPI, query string) *models.DatasetResults {
+			r := httptest.NewRequest("GET", "http://localhost:10400/datasets"+query, nil)
+			r.Header.Set("Authorization", "Bearer "+testServiceAuthToken)
+			w := httptest.NewRecorder()
+			api.Router.ServeHTTP(w, r)
+			So(w.Code, ShouldEqual, http.StatusOK)
+
+			var results models.DatasetResults
+			So(json.Unmarshal(w.Body.Bytes(), &results), ShouldBeNil)
+			return &results
+		}
+
+		Convey("When the first page of one dataset is requested in public mode", func() {
+			results := get(newPublicAPI(memoryStore), "?limit=1")
+
+			Convey("Then it is full, and the dataset without a current revision is not counted", func() {
+				So(results.Count, ShouldEqual, 1)
+				So(results.Items[0].ID, ShouldEqual, "Households")
+				So(results.TotalCount, ShouldEqual, 2)
+			})
+		})
+
+		Convey("When the second page of one dataset is requested in public mode", func() {
+			results := get(newPublicAPI(memoryStore), "?offset=1&limit=1")
+
+			Convey("Then it holds the next dataset with a current revision", func() {
+				So(results.Count, ShouldEqual, 1)
+				So(results.Items[0].ID, ShouldEqual, "Regions")
+			})
+		})
+
+		Convey("When the datasets are requested in private mode", func() {
+			results := get(newPrivateAPI(memoryStore), "?limit=1")
+
+			Convey("Then only those with a next revision are listed and counted", func() {
+				So(results.Count, ShouldEqual, 1)
+				So(results.Items[0].ID, ShouldEqual, "People")
+				So(results.TotalCount, ShouldEqual, 2)
+			})
+		})
+	})
+}
+
+func TestAddDataset(t *testing.T) {
+
+	Convey("Given a dataset that does not exist", t, func() {
+		var existing *models.DatasetUpdate
+		mockedDataStore := &storetest.StorerMock{
+			GetDatasetFunc: func(ctx context.Context, ID string) (*models.DatasetUpdate, error) {
+				if existing == nil {
+					return nil, errs.ErrDatasetNotFound
+				}
+				return existing, nil
+			},
+			UpsertDatasetFunc: func(ctx context.Context, ID string, datasetDoc *models.DatasetUpdate) error {
+				return nil
+			},
+		}
+		api := newPrivateAPI(mockedDataStore)
+
+		post := func(body string) *httptest.ResponseRecorder {
+			r := httptest.NewRequest("POST", "http://localhost:10400/datasets/People", strings.NewReader(body))
+			r.Header.Set("Authorization", "Bearer "+testServiceAuthToken)
+			w := httptest.NewRecorder()
+			api.Router.ServeHTTP(w, r)
+			return w
+		}
+
+		Convey("When it is created", func() {
+			w := post(`{"title":"People","ftb_type":"ftb-table","links":{"latest_version":{"id":"1"}}}`)
+
+			Convey("Then it is stored as the next revision of a new dataset, and returned", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(mockedDataStore.UpsertDatasetCalls(), ShouldHaveLength, 1)
+
+				datasetDoc := mockedDataStore.UpsertDatasetCalls()[0].DatasetDoc
+				So(datasetDoc.ID, ShouldEqual, "People")
+				So(datasetDoc.Current, ShouldBeNil)
+				So(datasetDoc.Next.ID, ShouldEqual, "People")
+				So(datasetDoc.Next.Title, ShouldEqual, "People")
+				So(datasetDoc.Next.State, ShouldEqual, models.CreatedState)
+				So(datasetDoc.Next.Links.Self.HRef, ShouldEndWith, "/datasets/People")
+				So(datasetDoc.Next.Links.Editions.HRef, ShouldEndWith, "/datasets/People/editions")
+				So(datasetDoc.Next.Links.LatestVersion, ShouldBeNil)
+
+				var response map[string]interface{}
+				So(json.Unmarshal(w.Body.Bytes(), &response), ShouldBeNil)
+				So(response, ShouldNotContainKey, "next")
+				So(response["id"], ShouldEqual, "People")
+				So(response["title"], ShouldEqual, "People")
+				So(response["state"], ShouldEqual, models.CreatedState)
+			})
+		})
+
+		Convey("When it already exists", func() {
+			existing = &models.DatasetUpdate{ID: "People", Next: &models.Dataset{State: models.CreatedState}}
+			w := post(`{"title":"People"}`)
+
+			Convey("Then it is forbidden, and the dataset is left as it is", func() {
+				So(w.Code, ShouldEqual, http.StatusForbidden)
+				So(mockedDataStore.UpsertDatasetCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When the body is not valid JSON", func() {
+			w := post(`{"title":`)
+
+			Convey("Then a bad request is returned without storing a dataset", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(mockedDataStore.UpsertDatasetCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When the dataset is invalid", func() {
+			w := post(`{"title":"People","ftb_type":"ftb-cube"}`)
+
+			Convey("Then a bad request is returned without storing a dataset", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(mockedDataStore.UpsertDatasetCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+}
+
+func TestPutDataset(t *testing.T) {
+
+	Convey("Given a dataset with a next revision", t, func() {
+		next := models.Dataset{Title: "People", State: models.PublishedState}
+		mockedDataStore := &storetest.StorerMock{
+			GetDatasetFunc: func(ctx context.Context, ID string) (*models.DatasetUpdate, error) {
+				if ID != "People" {
+					return nil, errs.ErrDatasetNotFound
+				}
+				revision := next
+				return &models.DatasetUpdate{ID: ID, Next: &revision}, nil
+			},
+			UpdateDatasetFunc: func(ctx context.Context, ID string, dataset *models.Dataset, currentState string) error {
+				next.Title, next.State = dataset.Title, models.CreatedState
+				return nil
+			},
+		}
+		api := newPrivateAPI(mockedDataStore)
+
+		put := func(datasetID, body string) *httptest.ResponseRecorder {
+			r := httptest.NewRequest("PUT", "http://localhost:10400/datasets/"+datasetID, strings.NewReader(body))
+			r.Header.Set("Authorization", "Bearer "+testServiceAuthToken)
+			w := httptest.NewRecorder()
+			api.Router.ServeHTTP(w, r)
+			return w
+		}
+
+		Convey("When it is updated", func() {
+			w := put("People", `{"title":"People by age"}`)
+
+			Convey("Then the update is stored along with the state of the next revision", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(mockedDataStore.UpdateDatasetCalls(), ShouldHaveLength, 1)
+				So(mockedDataStore.UpdateDatasetCalls()[0].Dataset.Title, ShouldEqual, "People by age")
+				So(mockedDataStore.UpdateDatasetCalls()[0].CurrentState, ShouldEqual, models.PublishedState)
+			})
+
+			Convey("Then the updated revision is returned", func() {
+				So(w.Header().Get("Content-Type"), ShouldEqual, "application/json")
+
+				var response models.Dataset
+				So(json.Unmarshal(w.Body.Bytes(), &response), ShouldBeNil)
+				So(response.ID, ShouldEqual, "People")
+				So(response.Title, ShouldEqual, "People by age")
+				So(response.State, ShouldEqual, models.CreatedState)
+			})
+		})
+
+		Convey("When a dataset that does not exist is updated", func() {
+			w := put("Households", `{"title":"Households"}`)
+
+			Convey("Then it is not found", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+				So(mockedDataStore.UpdateDatasetCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When the body is not valid JSON", func() {
+			w := put("People", `title=People`)
+
+			Convey("Then a bad request is returned without getting the dataset", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(mockedDataStore.GetDatasetCalls(), ShouldHaveLength, 0)
+				So(mockedDataStore.UpdateDatasetCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When the update is invalid", func() {
+			w := put("People", `{"uri":"not a uri"}`)
+
+			Convey("Then a bad request is returned without updating the dataset", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(mockedDataStore.UpdateDatasetCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+}
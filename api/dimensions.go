@@ -1,76 +1,64 @@
 package api
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
 	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
 	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
 	"github.com/ONSdigital/log.go/log"
-	"github.com/globalsign/mgo/bson"
-	"github.com/gorilla/mux"
 )
 
 func (api *FTBDatasetAPI) getDimensions(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	vars := mux.Vars(r)
+	vars := routeVars(r)
 	datasetID := vars["dataset_id"]
 	edition := vars["edition"]
 	version := vars["version"]
 	logData := log.Data{"dataset_id": datasetID, "edition": edition, "version": version, "func": "getDimensions"}
 
-	var state string
-
-	versionDoc, err := api.dataStore.Backend.GetVersion(datasetID, edition, version, state)
+	versionDoc, err := api.getVisibleVersion(w, r, datasetID, edition, version)
 	if err != nil {
 		log.Event(ctx, "datastore.getversion returned an error", log.ERROR, log.Error(err), logData)
-		handleDimensionsErr(ctx, w, err, logData)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
 	if err = models.CheckState("version", versionDoc.State); err != nil {
 		logData["state"] = versionDoc.State
 		log.Event(ctx, "unpublished version has an invalid state", log.ERROR, log.Error(err), logData)
-		handleDimensionsErr(ctx, w, err, logData)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
-	dimensions, err := api.dataStore.Backend.GetDimensions(datasetID, versionDoc.ID)
+	dimensions, err := api.dataStore.Backend.GetDimensions(ctx, datasetID, versionDoc.ID)
 	if err != nil {
 		log.Event(ctx, "failed to get version dimensions", log.ERROR, log.Error(err), logData)
-		handleDimensionsErr(ctx, w, err, logData)
-		return
-	}
-
-	results, err := api.createListOfDimensions(versionDoc, dimensions)
-	if err != nil {
-		log.Event(ctx, "failed to convert bson to dimension", log.ERROR, log.Error(err), logData)
-		handleDimensionsErr(ctx, w, err, logData)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
-	listOfDimensions := &models.DatasetDimensionResults{Items: results}
+	listOfDimensions := &models.DatasetDimensionResults{Items: api.createListOfDimensions(versionDoc, dimensions)}
 
 	b, err := json.Marshal(listOfDimensions)
 	if err != nil {
 		log.Event(ctx, "failed to marshal list of dimension resources into bytes", log.ERROR, log.Error(err), logData)
-		handleDimensionsErr(ctx, w, err, logData)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
 	setJSONContentType(w)
-	_, err = w.Write(b)
-	if err != nil {
+	if err = writeBody(w, r, b, versionDoc.LastUpdated); err != nil {
 		log.Event(ctx, "error writing bytes to response", log.ERROR, log.Error(err), logData)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		handleAPIErr(ctx, w, r, err, logData)
 	}
 
 	log.Event(ctx, "getDimensions endpoint: request successful", log.INFO, logData)
 }
 
-func (api *FTBDatasetAPI) createListOfDimensions(versionDoc *models.Version, dimensions []bson.M) ([]models.Dimension, error) {
+func (api *FTBDatasetAPI) createListOfDimensions(versionDoc *models.Version, dimensions []models.DimensionOption) []models.Dimension {
 
 	// Get dimension description from the version document and add to hash map
 	dimensionDescriptions := make(map[string]string)
@@ -83,12 +71,7 @@ func (api *FTBDatasetAPI) createListOfDimensions(versionDoc *models.Version, dim
 	}
 
 	var results []models.Dimension
-	for _, dim := range dimensions {
-		opt, err := convertBSONToDimensionOption(dim["doc"])
-		if err != nil {
-			return nil, err
-		}
-
+	for _, opt := range dimensions {
 		dimension := models.Dimension{Name: opt.Name, Links: &models.DimensionLink{}}
 		dimension.Links.CodeList = opt.Links.CodeList
 		dimension.Links.Options = models.LinkObject{ID: opt.Name, HRef: fmt.Sprintf("%s/datasets/%s/editions/%s/versions/%s/dimensions/%s/options",
@@ -104,24 +87,12 @@ func (api *FTBDatasetAPI) createListOfDimensions(versionDoc *models.Version, dim
 		results = append(results, dimension)
 	}
 
-	return results, nil
-}
-
-func convertBSONToDimensionOption(data interface{}) (*models.DimensionOption, error) {
-	var dim models.DimensionOption
-	b, err := bson.Marshal(data)
-	if err != nil {
-		return nil, err
-	}
-
-	bson.Unmarshal(b, &dim)
-
-	return &dim, nil
+	return results
 }
 
 func (api *FTBDatasetAPI) getDimensionOptions(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	vars := mux.Vars(r)
+	vars := routeVars(r)
 	datasetID := vars["dataset_id"]
 	edition := vars["edition"]
 	versionID := vars["version"]
@@ -129,26 +100,60 @@ func (api *FTBDatasetAPI) getDimensionOptions(w http.ResponseWriter, r *http.Req
 
 	logData := log.Data{"dataset_id": datasetID, "edition": edition, "version": versionID, "dimension": dimension, "func": "getDimensionOptions"}
 
-	var state string
+	contentType := negotiateContentType(r, jsonContentType, csvContentType)
+	if contentType == "" {
+		logData["accept"] = r.Header.Get("Accept")
+		log.Event(ctx, "unsupported content type requested", log.ERROR, log.Error(errs.ErrNotAcceptable), logData)
+		handleAPIErr(ctx, w, r, errs.ErrNotAcceptable, logData)
+		return
+	}
 
-	version, err := api.dataStore.Backend.GetVersion(datasetID, edition, versionID, state)
+	// A list of option IDs takes precedence over pagination, so offset and limit are only read without one
+	ids := getOptionIDs(r)
+	if len(ids) > api.maxLimit {
+		logData["number_of_ids"] = len(ids)
+		logData["max_ids"] = api.maxLimit
+		log.Event(ctx, "too many option ids requested", log.ERROR, log.Error(errs.ErrTooManyQueryParameters), logData)
+		handleAPIErr(ctx, w, r, errs.ErrTooManyQueryParameters, logData)
+		return
+	}
+
+	var offset, limit int
+	if len(ids) == 0 {
+		var err error
+		offset, limit, err = api.getPaginationParameters(r, logData)
+		if err != nil {
+			log.Event(ctx, "invalid pagination parameters", log.ERROR, log.Error(err), logData)
+			handleAPIErr(ctx, w, r, err, logData)
+			return
+		}
+	} else {
+		logData["ids"] = ids
+	}
+
+	version, err := api.getVisibleVersion(w, r, datasetID, edition, versionID)
 	if err != nil {
 		log.Event(ctx, "failed to get version", log.ERROR, log.Error(err), logData)
-		handleDimensionsErr(ctx, w, err, logData)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
 	if err = models.CheckState("version", version.State); err != nil {
 		logData["version_state"] = version.State
 		log.Event(ctx, "unpublished version has an invalid state", log.ERROR, log.Error(err), logData)
-		handleDimensionsErr(ctx, w, err, logData)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
-	results, err := api.dataStore.Backend.GetDimensionOptions(version, dimension)
+	var results *models.DimensionOptionResults
+	if len(ids) == 0 {
+		results, err = api.dataStore.Backend.GetDimensionOptions(ctx, version, dimension, offset, limit)
+	} else {
+		results, err = api.dataStore.Backend.GetDimensionOptionsFromIDs(ctx, version, dimension, ids)
+	}
 	if err != nil {
 		log.Event(ctx, "failed to get a list of dimension options", log.ERROR, log.Error(err), logData)
-		handleDimensionsErr(ctx, w, err, logData)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
@@ -158,39 +163,45 @@ func (api *FTBDatasetAPI) getDimensionOptions(w http.ResponseWriter, r *http.Req
 		results.Items[i].Links.Version.ID = versionID
 	}
 
-	b, err := json.Marshal(results)
+	var b []byte
+	if contentType == csvContentType {
+		b, err = results.MarshalCSV()
+	} else {
+		b, err = json.Marshal(results)
+	}
 	if err != nil {
 		log.Event(ctx, "failed to marshal list of dimension option resources into bytes", log.ERROR, log.Error(err), logData)
-		handleDimensionsErr(ctx, w, err, logData)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
-	setJSONContentType(w)
-	_, err = w.Write(b)
-	if err != nil {
+	lastModified := version.LastUpdated
+	for _, option := range results.Items {
+		lastModified = latest(lastModified, option.LastUpdated)
+	}
+
+	setContentType(w, contentType)
+	if err = writeBody(w, r, b, lastModified); err != nil {
 		log.Event(ctx, "error writing bytes to response", log.ERROR, log.Error(err), logData)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		handleAPIErr(ctx, w, r, err, logData)
 	}
 
 	log.Event(ctx, "get dimension options", log.INFO, logData)
 }
 
-func handleDimensionsErr(ctx context.Context, w http.ResponseWriter, err error, data log.Data) {
-	if data == nil {
-		data = log.Data{}
-	}
-
-	var status int
-	response := err
-	switch {
-	case errs.NotFoundMap[err]:
-		status = http.StatusNotFound
-	default:
-		status = http.StatusInternalServerError
-		response = errs.ErrInternalServer
+// getOptionIDs returns the unique option IDs provided through the repeatable id query parameter,
+// in the order they were first requested
+func getOptionIDs(r *http.Request) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, id := range r.URL.Query()["id"] {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
 	}
 
-	data["response_status"] = status
-	log.Event(ctx, "request unsuccessful", log.ERROR, log.Error(err), data)
-	http.Error(w, response.Error(), status)
+	return ids
 }
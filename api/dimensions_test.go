@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	storetest "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store/datastoretest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetOptionIDs(t *testing.T) {
+
+	Convey("Given a request for options by id", t, func() {
+		ids := func(query string) []string {
+			return getOptionIDs(httptest.NewRequest("GET", "http://localhost:10400/options"+query, nil))
+		}
+
+		Convey("Then the ids are returned in the order they were requested", func() {
+			So(ids("?id=2&id=1"), ShouldResemble, []string{"2", "1"})
+		})
+
+		Convey("Then repeated ids are only returned once", func() {
+			So(ids("?id=1&id=2&id=1&id=%201"), ShouldResemble, []string{"1", "2"})
+		})
+
+		Convey("Then blank ids are ignored", func() {
+			So(ids("?id=&id=%20&id=1"), ShouldResemble, []string{"1"})
+			So(ids("?id=&id=%20"), ShouldBeEmpty)
+		})
+
+		Convey("Then there are none without an id parameter", func() {
+			So(ids("?offset=1&limit=2"), ShouldBeEmpty)
+		})
+	})
+}
+
+func TestGetDimensionOptions(t *testing.T) {
+
+	Convey("Given a published version with a SEX dimension", t, func() {
+		labels := map[string]string{"1": "Male", "2": "Female"}
+
+		mockedDataStore := &storetest.StorerMock{
+			GetVersionFunc: func(ctx context.Context, datasetID, editionID, version, state string) (*models.Version, error) {
+				return &models.Version{ID: "789", Edition: "2011", State: models.PublishedState}, nil
+			},
+			GetDimensionOptionsFunc: func(ctx context.Context, version *models.Version, dimension string, offset, limit int) (*models.DimensionOptionResults, error) {
+				items := []models.PublicDimensionOption{{Name: dimension, Option: "2", Label: labels["2"]}}
+				return &models.DimensionOptionResults{Count: len(items), Items: items, Limit: limit, Offset: offset, TotalCount: len(labels)}, nil
+			},
+			GetDimensionOptionsFromIDsFunc: func(ctx context.Context, version *models.Version, dimension string, ids []string) (*models.DimensionOptionResults, error) {
+				items := []models.PublicDimensionOption{}
+				for _, id := range ids {
+					if label, ok := labels[id]; ok {
+						items = append(items, models.PublicDimensionOption{Name: dimension, Option: id, Label: label})
+					}
+				}
+				return &models.DimensionOptionResults{Count: len(items), Items: items, Limit: len(ids), TotalCount: len(items)}, nil
+			},
+		}
+		api := newPublicAPI(mockedDataStore)
+
+		get := func(query string) (*httptest.ResponseRecorder, *models.DimensionOptionResults) {
+			r := httptest.NewRequest("GET", "http://localhost:10400/datasets/People/editions/2011/versions/1/dimensions/SEX/options"+query, nil)
+			w := httptest.NewRecorder()
+			api.Router.ServeHTTP(w, r)
+
+			var results models.DimensionOptionResults
+			if w.Code == http.StatusOK {
+				So(json.Unmarshal(w.Body.Bytes(), &results), ShouldBeNil)
+			}
+			return w, &results
+		}
+
+		Convey("When options are requested by id, with repeated and blank ids", func() {
+			w, results := get("?id=2&id=&id=1&id=2")
+
+			Convey("Then each option is looked up once, without paging through the options", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(mockedDataStore.GetDimensionOptionsCalls(), ShouldHaveLength, 0)
+				So(mockedDataStore.GetDimensionOptionsFromIDsCalls(), ShouldHaveLength, 1)
+				So(mockedDataStore.GetDimensionOptionsFromIDsCalls()[0].Ids, ShouldResemble, []string{"2", "1"})
+				So(results.Count, ShouldEqual, 2)
+				So(results.Items[0].Links.Version.ID, ShouldEqual, "1")
+			})
+		})
+
+		Convey("When options are requested by id along with pagination parameters", func() {
+			w, results := get("?id=1&offset=-1&limit=bad")
+
+			Convey("Then the ids take precedence, so the parameters are not read", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(mockedDataStore.GetDimensionOptionsCalls(), ShouldHaveLength, 0)
+				So(results.Offset, ShouldEqual, 0)
+				So(results.Limit, ShouldEqual, 1)
+			})
+		})
+
+		Convey("When an id without an option is requested", func() {
+			w, results := get("?id=1&id=3")
+
+			Convey("Then the limit is the number of ids, and the total count the number of options found", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(results.Count, ShouldEqual, 1)
+				So(results.Limit, ShouldEqual, 2)
+				So(results.TotalCount, ShouldEqual, 1)
+			})
+		})
+
+		Convey("When more ids are requested than the maximum limit", func() {
+			api.maxLimit = 2
+			w, _ := get("?id=1&id=2&id=3")
+
+			Convey("Then a bad request is returned without getting the version", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(mockedDataStore.GetVersionCalls(), ShouldHaveLength, 0)
+				So(mockedDataStore.GetDimensionOptionsFromIDsCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When as many ids are requested as the maximum limit, counting repeated ids once", func() {
+			api.maxLimit = 2
+			w, _ := get("?id=1&id=2&id=1")
+
+			Convey("Then the options are returned", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+			})
+		})
+
+		Convey("When options are requested without ids", func() {
+			w, results := get("?offset=1&limit=1")
+
+			Convey("Then a page of the options is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(mockedDataStore.GetDimensionOptionsFromIDsCalls(), ShouldHaveLength, 0)
+				So(mockedDataStore.GetDimensionOptionsCalls(), ShouldHaveLength, 1)
+				So(results.Offset, ShouldEqual, 1)
+				So(results.Limit, ShouldEqual, 1)
+				So(results.TotalCount, ShouldEqual, 2)
+			})
+		})
+	})
+}
@@ -3,57 +3,65 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"time"
 
 	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
 	"github.com/ONSdigital/log.go/log"
-	"github.com/gorilla/mux"
 )
 
 func (api *FTBDatasetAPI) getEditions(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	vars := mux.Vars(r)
+	vars := routeVars(r)
 	datasetID := vars["dataset_id"]
 	logData := log.Data{"dataset_id": datasetID}
 
-	var state string
+	state := api.state()
 	logData["state"] = state
 
-	if err := api.dataStore.Backend.CheckDatasetExists(datasetID, state); err != nil {
-		log.Event(ctx, "getEditions endpoint: unable to find dataset", log.ERROR, log.Error(err), logData)
-		if err == errs.ErrDatasetNotFound {
-			http.Error(w, err.Error(), http.StatusNotFound)
+	query := &models.EditionsQuery{State: r.URL.Query().Get("state")}
+	if query.State != "" {
+		logData["state_filter"] = query.State
+		if err := models.CheckState("edition", query.State); err != nil {
+			log.Event(ctx, "getEditions endpoint: invalid state filter", log.ERROR, log.Error(err), logData)
+			handleAPIErr(ctx, w, r, errs.ErrInvalidQueryParameter, logData)
 			return
 		}
+	}
 
-		http.Error(w, errs.ErrInternalServer.Error(), http.StatusInternalServerError)
+	if err := api.dataStore.Backend.CheckDatasetExists(ctx, datasetID, state); err != nil {
+		log.Event(ctx, "getEditions endpoint: unable to find dataset", log.ERROR, log.Error(err), logData)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
-	results, err := api.dataStore.Backend.GetEditions(ctx, datasetID, state)
+	results, err := api.dataStore.Backend.GetEditions(ctx, datasetID, state, query)
 	if err != nil {
 		log.Event(ctx, "getEditions endpoint: unable to find editions for dataset", log.ERROR, log.Error(err), logData)
-		if err == errs.ErrEditionNotFound {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-
-		http.Error(w, errs.ErrInternalServer.Error(), http.StatusInternalServerError)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
-	// User has valid authentication to get raw edition document
-	b, err := json.Marshal(results)
+	editions := &models.EditionResults{Items: []*models.Edition{}}
+	var lastModified time.Time
+	for _, item := range results.Items {
+		if edition := item.Revision(api.enablePrivateEndpoints); edition != nil {
+			editions.Items = append(editions.Items, edition)
+			lastModified = latest(lastModified, edition.LastUpdated)
+		}
+	}
+
+	b, err := json.Marshal(editions)
 	if err != nil {
 		log.Event(ctx, "getEditions endpoint: failed to marshal a list of edition resources into bytes", log.ERROR, log.Error(err), logData)
-		http.Error(w, errs.ErrInternalServer.Error(), http.StatusInternalServerError)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
 	setJSONContentType(w)
-	_, err = w.Write(b)
-	if err != nil {
+	if err = writeBody(w, r, b, lastModified); err != nil {
 		log.Event(ctx, "getEditions endpoint: failed writing bytes to response", log.ERROR, log.Error(err), logData)
-		http.Error(w, errs.ErrInternalServer.Error(), http.StatusInternalServerError)
+		handleAPIErr(ctx, w, r, err, logData)
 	}
 
 	log.Event(ctx, "getEditions endpoint: request successful", log.INFO, logData)
@@ -61,51 +69,48 @@ func (api *FTBDatasetAPI) getEditions(w http.ResponseWriter, r *http.Request) {
 
 func (api *FTBDatasetAPI) getEdition(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	vars := mux.Vars(r)
+	vars := routeVars(r)
 	datasetID := vars["dataset_id"]
 	edition := vars["edition"]
 	logData := log.Data{"dataset_id": datasetID, "edition": edition}
 
-	var state string
+	state := api.state()
+	logData["state"] = state
 
-	if err := api.dataStore.Backend.CheckDatasetExists(datasetID, state); err != nil {
+	if err := api.dataStore.Backend.CheckDatasetExists(ctx, datasetID, state); err != nil {
 		log.Event(ctx, "getEdition endpoint: unable to find dataset", log.ERROR, log.Error(err), logData)
 
-		if err == errs.ErrDatasetNotFound {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-
-		http.Error(w, errs.ErrInternalServer.Error(), http.StatusInternalServerError)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
-	editionDoc, err := api.dataStore.Backend.GetEdition(datasetID, edition, state)
+	editionDoc, err := api.dataStore.Backend.GetEdition(ctx, datasetID, edition, state)
 	if err != nil {
 		log.Event(ctx, "getEdition endpoint: unable to find edition", log.ERROR, log.Error(err), logData)
 
-		if err == errs.ErrEditionNotFound {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
+		handleAPIErr(ctx, w, r, err, logData)
+		return
+	}
 
-		http.Error(w, errs.ErrInternalServer.Error(), http.StatusInternalServerError)
+	editionRevision := editionDoc.Revision(api.enablePrivateEndpoints)
+	if editionRevision == nil {
+		log.Event(ctx, "getEdition endpoint: published or unpublished edition not found", log.INFO, logData)
+		handleAPIErr(ctx, w, r, errs.ErrEditionNotFound, logData)
 		return
 	}
 
-	b, err := json.Marshal(editionDoc)
+	b, err := json.Marshal(editionRevision)
 	if err != nil {
 		log.Event(ctx, "getEdition endpoint: failed to marshal edition resource into bytes", log.ERROR, log.Error(err), logData)
 
-		http.Error(w, errs.ErrInternalServer.Error(), http.StatusInternalServerError)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
 	setJSONContentType(w)
-	_, err = w.Write(b)
-	if err != nil {
+	if err = writeBody(w, r, b, editionRevision.LastUpdated); err != nil {
 		log.Event(ctx, "getEdition endpoint: failed to write byte to response", log.ERROR, log.Error(err), logData)
-		http.Error(w, errs.ErrInternalServer.Error(), http.StatusInternalServerError)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 	log.Event(ctx, "getEdition endpoint: request successful", log.INFO, logData)
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	"github.com/ONSdigital/dp-net/request"
+	"github.com/ONSdigital/log.go/log"
+)
+
+const problemContentType = "application/problem+json"
+
+var (
+	// errors defined outside of apierrors that map to a HTTP 400 response
+	modelsBadRequest = map[error]bool{
+		models.ErrAssociatedVersionCollectionIDInvalid: true,
+		models.ErrPublishedVersionCollectionIDInvalid:  true,
+		models.ErrVersionReleaseDateInvalid:            true,
+		models.ErrVersionStateInvalid:                  true,
+	}
+
+	// HTTP 500 responses with a specific message
+	internalServerErrWithMessage = map[error]bool{
+		errs.ErrResourceState: true,
+	}
+
+	// prefixes of validation errors, built for each request, that map to a HTTP 400 response
+	badRequestPrefixes = []string{
+		"invalid fields:",
+		"missing mandatory fields:",
+	}
+
+	// errorStatuses is the registry of every known error and the HTTP status returned for it
+	errorStatuses = newErrorStatuses(map[int][]map[error]bool{
+		http.StatusBadRequest:          {errs.BadRequestMap, modelsBadRequest},
+		http.StatusUnauthorized:        {errs.UnauthorisedMap},
+		http.StatusForbidden:           {errs.ForbiddenMap},
+		http.StatusNotFound:            {errs.NotFoundMap},
+		http.StatusNotAcceptable:       {errs.NotAcceptableMap},
+		http.StatusConflict:            {errs.ConflictRequestMap},
+		http.StatusInternalServerError: {internalServerErrWithMessage},
+		http.StatusGatewayTimeout:      {errs.GatewayTimeoutMap},
+	})
+)
+
+func newErrorStatuses(tables map[int][]map[error]bool) map[error]int {
+	statuses := make(map[error]int)
+	for status, statusTables := range tables {
+		for _, table := range statusTables {
+			for err := range table {
+				statuses[err] = status
+			}
+		}
+	}
+
+	return statuses
+}
+
+// errorStatus returns the HTTP status for an error and whether its message can be returned to the caller.
+// Unknown errors are internal server errors and their message is hidden.
+func errorStatus(err error) (int, bool) {
+	if status, ok := errorStatuses[err]; ok {
+		return status, true
+	}
+
+	for _, prefix := range badRequestPrefixes {
+		if strings.HasPrefix(err.Error(), prefix) {
+			return http.StatusBadRequest, true
+		}
+	}
+
+	return http.StatusInternalServerError, false
+}
+
+// handleAPIErr logs an unsuccessful request and writes the problem details for the error to the response
+func handleAPIErr(ctx context.Context, w http.ResponseWriter, r *http.Request, err error, data log.Data) {
+	if data == nil {
+		data = log.Data{}
+	}
+
+	status, known := errorStatus(err)
+	detail := err.Error()
+	if !known {
+		detail = errs.ErrInternalServer.Error()
+	}
+
+	// an unknown error returned once the deadline of the request has passed, such as a store query timing out, is
+	// reported as a timeout rather than an internal error
+	if !known && r.Context().Err() == context.DeadlineExceeded {
+		status, _ = errorStatus(errs.ErrRequestTimeout)
+		detail = errs.ErrRequestTimeout.Error()
+	}
+
+	data["response_status"] = status
+	log.Event(ctx, "request unsuccessful", log.ERROR, log.Error(err), data)
+
+	writeProblem(w, r, status, detail)
+}
+
+// writeProblem writes an RFC 7807 problem details body, identifying the request it was returned for
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	problem := models.Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		RequestID: getRequestID(r),
+	}
+
+	b, err := json.Marshal(problem)
+	if err != nil {
+		log.Event(r.Context(), "failed to marshal problem details into bytes", log.ERROR, log.Error(err))
+		http.Error(w, detail, status)
+		return
+	}
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	if _, err = w.Write(b); err != nil {
+		log.Event(r.Context(), "failed to write problem details to response", log.ERROR, log.Error(err))
+	}
+}
+
+// getRequestID returns the ID given to the request by the request ID middleware, falling back to the request
+// header when the handler is used without it
+func getRequestID(r *http.Request) string {
+	if id := request.GetRequestId(r.Context()); id != "" {
+		return id
+	}
+
+	return r.Header.Get(request.RequestHeaderKey)
+}
+
+// notFound writes the problem details for a request that does not match any route
+func notFound(w http.ResponseWriter, r *http.Request) {
+	writeProblem(w, r, http.StatusNotFound, errs.ErrNotFound.Error())
+}
+
+// methodNotAllowed writes the problem details for a request using a method a route does not support
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeProblem(w, r, http.StatusMethodNotAllowed, "")
+}
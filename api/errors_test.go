@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	"github.com/ONSdigital/dp-net/request"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHandleAPIErr(t *testing.T) {
+
+	Convey("Given an error from each of the apierrors tables", t, func() {
+		tests := map[error]int{
+			errs.ErrAddDatasetAlreadyExists:  http.StatusForbidden,
+			errs.ErrConflictUpdatingInstance: http.StatusConflict,
+			errs.ErrDatasetNotFound:          http.StatusNotFound,
+			errs.ErrInvalidQueryParameter:    http.StatusBadRequest,
+			models.ErrVersionStateInvalid:    http.StatusBadRequest,
+		}
+
+		Convey("Then each error is written as problem details with the status from the registry", func() {
+			for err, status := range tests {
+				r := httptest.NewRequest("GET", "/datasets/123", nil)
+				w := httptest.NewRecorder()
+
+				handleAPIErr(context.Background(), w, r, err, nil)
+
+				So(w.Code, ShouldEqual, status)
+				So(w.Header().Get("Content-Type"), ShouldEqual, "application/problem+json")
+
+				var problem models.Problem
+				So(json.Unmarshal(w.Body.Bytes(), &problem), ShouldBeNil)
+				So(problem.Status, ShouldEqual, status)
+				So(problem.Title, ShouldEqual, http.StatusText(status))
+				So(problem.Detail, ShouldEqual, err.Error())
+				So(problem.Instance, ShouldEqual, "/datasets/123")
+			}
+		})
+	})
+
+	Convey("Given a validation error", t, func() {
+		err := errors.New("missing mandatory fields: [release_date]")
+		r := httptest.NewRequest("PUT", "/datasets/123/editions/2011/versions/1", nil)
+		w := httptest.NewRecorder()
+
+		handleAPIErr(context.Background(), w, r, err, nil)
+
+		Convey("Then a bad request is returned with the validation message", func() {
+			So(w.Code, ShouldEqual, http.StatusBadRequest)
+
+			var problem models.Problem
+			So(json.Unmarshal(w.Body.Bytes(), &problem), ShouldBeNil)
+			So(problem.Detail, ShouldEqual, err.Error())
+		})
+	})
+
+	Convey("Given an unexpected error", t, func() {
+		r := httptest.NewRequest("GET", "/datasets", nil)
+		r = r.WithContext(request.WithRequestId(r.Context(), "request-123"))
+		w := httptest.NewRecorder()
+
+		handleAPIErr(context.Background(), w, r, errors.New("connection refused"), nil)
+
+		Convey("Then an internal server error is returned without the error message", func() {
+			So(w.Code, ShouldEqual, http.StatusInternalServerError)
+
+			var problem models.Problem
+			So(json.Unmarshal(w.Body.Bytes(), &problem), ShouldBeNil)
+			So(problem.Detail, ShouldEqual, errs.ErrInternalServer.Error())
+			So(problem.RequestID, ShouldEqual, "request-123")
+		})
+	})
+
+	Convey("Given an unexpected error returned after the deadline of the request has passed", t, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 0)
+		defer cancel()
+
+		r := httptest.NewRequest("GET", "/datasets", nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+
+		handleAPIErr(ctx, w, r, errors.New("i/o timeout"), nil)
+
+		Convey("Then the request is reported as having timed out", func() {
+			So(w.Code, ShouldEqual, http.StatusGatewayTimeout)
+
+			var problem models.Problem
+			So(json.Unmarshal(w.Body.Bytes(), &problem), ShouldBeNil)
+			So(problem.Status, ShouldEqual, http.StatusGatewayTimeout)
+			So(problem.Title, ShouldEqual, "Gateway Timeout")
+			So(problem.Detail, ShouldEqual, errs.ErrRequestTimeout.Error())
+		})
+	})
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	"github.com/ONSdigital/log.go/log"
+	"github.com/gorilla/mux"
+)
+
+// latestAlias can be used in place of the edition or version of a route, to refer to the latest version
+const latestAlias = "latest"
+
+// resolvedVarsKey is the context key of the route variables of a request once its aliases are resolved
+type resolvedVarsKey struct{}
+
+// routeVars returns the variables of the route of a request, with any latest alias replaced by the edition or
+// version it was resolved to
+func routeVars(r *http.Request) map[string]string {
+	if vars, ok := r.Context().Value(resolvedVarsKey{}).(map[string]string); ok {
+		return vars
+	}
+
+	return mux.Vars(r)
+}
+
+// resolveLatest wraps the handler of a route nested under an edition, so that "latest" can be used in place of its
+// edition or version. A latest edition is the edition of the latest version linked from the dataset, and a latest
+// version the one linked from the edition, taking the same revisions that the request reads. The request is then
+// either redirected to the resolved route, or handled as if it had been made to it, with the resolved route as
+// the Content-Location of the response.
+func (api *FTBDatasetAPI) resolveLatest(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		if vars["edition"] != latestAlias && vars["version"] != latestAlias {
+			handler(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		logData := log.Data{"dataset_id": vars["dataset_id"], "edition": vars["edition"], "version": vars["version"]}
+
+		resolved, err := api.resolveLatestVars(ctx, vars, api.versionState(api.previewCollectionID(w, r)))
+		if err != nil {
+			log.Event(ctx, "failed to resolve latest alias", log.ERROR, log.Error(err), logData)
+			handleAPIErr(ctx, w, r, err, logData)
+			return
+		}
+		logData["resolved_edition"] = resolved["edition"]
+		logData["resolved_version"] = resolved["version"]
+
+		location := resolvedPath(r.URL, resolved)
+		if api.redirectLatest {
+			log.Event(ctx, "redirecting latest alias", log.INFO, logData)
+			http.Redirect(w, r, location, http.StatusFound)
+			return
+		}
+
+		log.Event(ctx, "resolved latest alias", log.INFO, logData)
+		w.Header().Set("Content-Location", location)
+		handler(w, r.WithContext(context.WithValue(ctx, resolvedVarsKey{}, resolved)))
+	}
+}
+
+// resolveLatestVars returns a copy of the route variables with the latest edition and version replaced
+func (api *FTBDatasetAPI) resolveLatestVars(ctx context.Context, vars map[string]string, state string) (map[string]string, error) {
+	resolved := make(map[string]string, len(vars))
+	for name, value := range vars {
+		resolved[name] = value
+	}
+
+	if vars["edition"] == latestAlias {
+		datasetDoc, err := api.dataStore.Backend.GetDataset(ctx, vars["dataset_id"])
+		if err != nil {
+			return nil, err
+		}
+
+		dataset := datasetDoc.Revision(state == "")
+		if dataset == nil {
+			return nil, errs.ErrDatasetNotFound
+		}
+
+		if dataset.Links == nil || dataset.Links.LatestVersion == nil {
+			return nil, errs.ErrEditionNotFound
+		}
+
+		edition, version, ok := parseVersionHRef(dataset.Links.LatestVersion.HRef)
+		if !ok {
+			return nil, errs.ErrEditionNotFound
+		}
+
+		resolved["edition"] = edition
+		if vars["version"] == latestAlias {
+			resolved["version"] = version
+			return resolved, nil
+		}
+	}
+
+	if vars["version"] == latestAlias {
+		editionDoc, err := api.dataStore.Backend.GetEdition(ctx, vars["dataset_id"], resolved["edition"], state)
+		if err != nil {
+			return nil, err
+		}
+
+		version, err := latestVersion(editionDoc.Revision(state == ""))
+		if err != nil {
+			return nil, err
+		}
+		resolved["version"] = strconv.Itoa(version)
+	}
+
+	return resolved, nil
+}
+
+// parseVersionHRef returns the edition and version of a link to a version
+func parseVersionHRef(href string) (edition, version string, ok bool) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", "", false
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+3 < len(segments); i++ {
+		if segments[i] == "editions" && segments[i+2] == "versions" && segments[i+1] != "" && segments[i+3] != "" {
+			return segments[i+1], segments[i+3], true
+		}
+	}
+
+	return "", "", false
+}
+
+// resolvedPath returns the path and query of a request with its latest edition and version replaced by those
+// resolved. The edition and version are the fifth and seventh segments of every route that can be aliased.
+func resolvedPath(u *url.URL, resolved map[string]string) string {
+	segments := strings.Split(u.Path, "/")
+	if len(segments) > 4 && segments[4] == latestAlias {
+		segments[4] = resolved["edition"]
+	}
+	if len(segments) > 6 && segments[6] == latestAlias {
+		segments[6] = resolved["version"]
+	}
+
+	location := url.URL{Path: strings.Join(segments, "/"), RawQuery: u.RawQuery}
+	return location.String()
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	storetest "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store/datastoretest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestResolveLatest(t *testing.T) {
+
+	Convey("Given a dataset whose published latest version is 2011 version 3, and next latest version is 2021 version 1", t, func() {
+		const host = "http://localhost:10400/datasets/People"
+		mockedDataStore := &storetest.StorerMock{
+			CheckDatasetExistsFunc: func(ctx context.Context, ID, state string) error {
+				return nil
+			},
+			CheckEditionExistsFunc: func(ctx context.Context, ID, editionID, state string) error {
+				return nil
+			},
+			GetDatasetFunc: func(ctx context.Context, ID string) (*models.DatasetUpdate, error) {
+				return &models.DatasetUpdate{
+					Current: &models.Dataset{Links: &models.DatasetLinks{LatestVersion: &models.LinkObject{ID: "3", HRef: host + "/editions/2011/versions/3"}}},
+					Next:    &models.Dataset{Links: &models.DatasetLinks{LatestVersion: &models.LinkObject{ID: "1", HRef: host + "/editions/2021/versions/1"}}},
+				}, nil
+			},
+			GetEditionFunc: func(ctx context.Context, ID, editionID, state string) (*models.EditionUpdate, error) {
+				return &models.EditionUpdate{
+					Current: &models.Edition{Links: &models.EditionUpdateLinks{LatestVersion: &models.LinkObject{ID: "2"}}},
+					Next:    &models.Edition{Links: &models.EditionUpdateLinks{LatestVersion: &models.LinkObject{ID: "4"}}},
+				}, nil
+			},
+			GetDimensionsFunc: func(ctx context.Context, datasetID, versionID string) ([]models.DimensionOption, error) {
+				return nil, nil
+			},
+			GetVersionFunc: func(ctx context.Context, datasetID, editionID, versionID, state string) (*models.Version, error) {
+				return &models.Version{
+					Edition: editionID,
+					State:   models.PublishedState,
+					Links:   &models.VersionLinks{Self: &models.LinkObject{}, Version: &models.LinkObject{HRef: host + "/editions/" + editionID + "/versions/" + versionID}},
+				}, nil
+			},
+		}
+
+		get := func(api *FTBDatasetAPI, target string) *httptest.ResponseRecorder {
+			r := httptest.NewRequest("GET", "http://localhost:10400"+target, nil)
+			if api.enablePrivateEndpoints {
+				r.Header.Set("Authorization", "Bearer "+testServiceAuthToken)
+			}
+			w := httptest.NewRecorder()
+			api.Router.ServeHTTP(w, r)
+			return w
+		}
+
+		Convey("When the latest version of the latest edition is requested in public mode", func() {
+			w := get(newPublicAPI(mockedDataStore), "/datasets/People/editions/latest/versions/latest")
+
+			Convey("Then the version linked from the published dataset is returned, with its route as the content location", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Content-Location"), ShouldEqual, "/datasets/People/editions/2011/versions/3")
+				So(mockedDataStore.GetVersionCalls()[0].EditionID, ShouldEqual, "2011")
+				So(mockedDataStore.GetVersionCalls()[0].Version, ShouldEqual, "3")
+				So(mockedDataStore.GetEditionCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When the latest version of the latest edition is requested in private mode", func() {
+			w := get(newPrivateAPI(mockedDataStore), "/datasets/People/editions/latest/versions/latest")
+
+			Convey("Then the version linked from the next dataset is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Content-Location"), ShouldEqual, "/datasets/People/editions/2021/versions/1")
+			})
+		})
+
+		Convey("When the latest version of an edition is requested", func() {
+			w := get(newPublicAPI(mockedDataStore), "/datasets/People/editions/2011/versions/latest/dimensions?limit=5")
+
+			Convey("Then it is resolved from the link of the edition, keeping the query", func() {
+				So(w.Header().Get("Content-Location"), ShouldEqual, "/datasets/People/editions/2011/versions/2/dimensions?limit=5")
+				So(mockedDataStore.GetEditionCalls()[0].EditionID, ShouldEqual, "2011")
+				So(mockedDataStore.GetEditionCalls()[0].State, ShouldEqual, models.PublishedState)
+			})
+		})
+
+		Convey("When a version of the latest edition is requested", func() {
+			w := get(newPublicAPI(mockedDataStore), "/datasets/People/editions/latest/versions/1")
+
+			Convey("Then only the edition is resolved", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Content-Location"), ShouldEqual, "/datasets/People/editions/2011/versions/1")
+			})
+		})
+
+		Convey("When the API redirects latest aliases", func() {
+			api := newPublicAPI(mockedDataStore)
+			api.redirectLatest = true
+			w := get(api, "/datasets/People/editions/latest/versions/latest/metadata")
+
+			Convey("Then the request is redirected to the resolved route", func() {
+				So(w.Code, ShouldEqual, http.StatusFound)
+				So(w.Header().Get("Location"), ShouldEqual, "/datasets/People/editions/2011/versions/3/metadata")
+				So(mockedDataStore.GetVersionCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When a concrete version is requested", func() {
+			w := get(newPublicAPI(mockedDataStore), "/datasets/People/editions/2011/versions/1")
+
+			Convey("Then nothing is resolved", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Content-Location"), ShouldBeEmpty)
+				So(mockedDataStore.GetDatasetCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When the dataset has not been published", func() {
+			mockedDataStore.GetDatasetFunc = func(ctx context.Context, ID string) (*models.DatasetUpdate, error) {
+				return &models.DatasetUpdate{Next: &models.Dataset{}}, nil
+			}
+			w := get(newPublicAPI(mockedDataStore), "/datasets/People/editions/latest")
+
+			Convey("Then dataset not found is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+			})
+		})
+
+		Convey("When the edition does not exist", func() {
+			mockedDataStore.GetEditionFunc = func(ctx context.Context, ID, editionID, state string) (*models.EditionUpdate, error) {
+				return nil, errs.ErrEditionNotFound
+			}
+			w := get(newPublicAPI(mockedDataStore), "/datasets/People/editions/2031/versions/latest")
+
+			Convey("Then edition not found is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+			})
+		})
+	})
+}
+
+func TestParseVersionHRef(t *testing.T) {
+
+	Convey("Given links to versions", t, func() {
+		Convey("Then the edition and version are read from their paths", func() {
+			edition, version, ok := parseVersionHRef("http://localhost:10400/datasets/People/editions/2011/versions/3")
+			So(ok, ShouldBeTrue)
+			So(edition, ShouldEqual, "2011")
+			So(version, ShouldEqual, "3")
+		})
+
+		Convey("Then a link that is not to a version is rejected", func() {
+			_, _, ok := parseVersionHRef("http://localhost:10400/datasets/People/editions/2011")
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
@@ -3,94 +3,93 @@ package api
 import (
 	"encoding/json"
 	"net/http"
+	"time"
 
 	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
 	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
 	"github.com/ONSdigital/log.go/log"
-	"github.com/gorilla/mux"
 )
 
 func (api *FTBDatasetAPI) getMetadata(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	vars := mux.Vars(r)
+	vars := routeVars(r)
 	datasetID := vars["dataset_id"]
 	edition := vars["edition"]
 	version := vars["version"]
 	logData := log.Data{"dataset_id": datasetID, "edition": edition, "version": version}
 
-	versionDoc, err := api.dataStore.Backend.GetVersion(datasetID, edition, version, "")
+	contentType := negotiateContentType(r, jsonContentType, csvwContentType, jsonldContentType)
+	if contentType == "" {
+		logData["accept"] = r.Header.Get("Accept")
+		log.Event(ctx, "getMetadata endpoint: unsupported content type requested", log.ERROR, log.Error(errs.ErrNotAcceptable), logData)
+		handleAPIErr(ctx, w, r, errs.ErrNotAcceptable, logData)
+		return
+	}
+
+	versionDoc, err := api.getVisibleVersion(w, r, datasetID, edition, version)
 	if err != nil {
 		if err == errs.ErrVersionNotFound {
 			log.Event(ctx, "getMetadata endpoint: failed to find version for dataset edition", log.ERROR, log.Error(err), logData)
 
-			handleMetadataErr(w, errs.ErrMetadataVersionNotFound)
+			handleAPIErr(ctx, w, r, errs.ErrMetadataVersionNotFound, logData)
 			return
 		}
 
 		log.Event(ctx, "getMetadata endpoint: get datastore.getVersion returned an error", log.ERROR, log.Error(err), logData)
-		handleMetadataErr(w, err)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
-	datasetDoc, err := api.dataStore.Backend.GetDataset(datasetID)
+	datasetDoc, err := api.dataStore.Backend.GetDataset(ctx, datasetID)
 	if err != nil {
 		log.Event(ctx, "getMetadata endpoint: get datastore.getDataset returned an error", log.ERROR, log.Error(err), logData)
-		handleMetadataErr(w, err)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
-	if err = api.dataStore.Backend.CheckEditionExists(datasetID, edition, ""); err != nil {
+	if err = api.dataStore.Backend.CheckEditionExists(ctx, datasetID, edition, ""); err != nil {
 		log.Event(ctx, "getMetadata endpoint: failed to find edition for dataset", log.ERROR, log.Error(err), logData)
-		handleMetadataErr(w, err)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
 	if err = models.CheckState("version", versionDoc.State); err != nil {
 		logData["state"] = versionDoc.State
 		log.Event(ctx, "getMetadata endpoint: unpublished version has an invalid state", log.ERROR, log.Error(err), logData)
-		handleMetadataErr(w, err)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
 	var metaDataDoc *models.Metadata
+	var lastModified time.Time
 	// combine version and dataset metadata
 	if versionDoc.State != models.PublishedState {
 		metaDataDoc = models.CreateMetaDataDoc(datasetDoc.Next, versionDoc, api.urlBuilder)
+		lastModified = latest(datasetDoc.Next.LastUpdated, versionDoc.LastUpdated)
 	} else {
 		metaDataDoc = models.CreateMetaDataDoc(datasetDoc.Current, versionDoc, api.urlBuilder)
+		lastModified = latest(datasetDoc.Current.LastUpdated, versionDoc.LastUpdated)
 	}
 
-	b, err := json.Marshal(metaDataDoc)
+	var b []byte
+	switch contentType {
+	case csvwContentType:
+		b, err = json.Marshal(models.CreateCSVWMetadata(metaDataDoc))
+	case jsonldContentType:
+		b, err = json.Marshal(models.CreateDCATMetadata(metaDataDoc))
+	default:
+		b, err = json.Marshal(metaDataDoc)
+	}
 	if err != nil {
 		log.Event(ctx, "getMetadata endpoint: failed to marshal metadata resource into bytes", log.ERROR, log.Error(err), logData)
-		handleMetadataErr(w, err)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
-	setJSONContentType(w)
-	if _, err = w.Write(b); err != nil {
+	setContentType(w, contentType)
+	if err = writeBody(w, r, b, lastModified); err != nil {
 		log.Event(ctx, "getMetadata endpoint: failed to write bytes to response", log.ERROR, log.Error(err), logData)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		handleAPIErr(ctx, w, r, err, logData)
 	}
 	log.Event(ctx, "getMetadata endpoint: get metadata request successful", log.INFO, logData)
 }
-
-func handleMetadataErr(w http.ResponseWriter, err error) {
-	var responseStatus int
-
-	switch {
-	case err == errs.ErrUnauthorised:
-		responseStatus = http.StatusNotFound
-	case err == errs.ErrEditionNotFound:
-		responseStatus = http.StatusNotFound
-	case err == errs.ErrMetadataVersionNotFound:
-		responseStatus = http.StatusNotFound
-	case err == errs.ErrDatasetNotFound:
-		responseStatus = http.StatusNotFound
-	default:
-		err = errs.ErrInternalServer
-		responseStatus = http.StatusInternalServerError
-	}
-
-	http.Error(w, err.Error(), responseStatus)
-}
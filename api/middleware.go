@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ONSdigital/dp-net/request"
+	"github.com/ONSdigital/log.go/log"
+	"github.com/gorilla/mux"
+)
+
+const (
+	requestIDSize      = 16
+	maxRequestIDLength = 128
+)
+
+// requestIDHandler takes the ID of a request from its X-Request-Id header, or generates one when the header is
+// missing or invalid. The ID is added to the request context, where log.Event reads it as the trace id of every
+// event, and echoed in the response.
+func requestIDHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(request.RequestHeaderKey)
+		if !isValidRequestID(id) {
+			id = request.NewRequestID(requestIDSize)
+			r.Header.Set(request.RequestHeaderKey, id)
+		}
+
+		w.Header().Set(request.RequestHeaderKey, id)
+		next.ServeHTTP(w, r.WithContext(request.WithRequestId(r.Context(), id)))
+	})
+}
+
+// isValidRequestID checks that an ID given by a caller is short and printable, so that it is safe to log and echo
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// deadlineHandler gives each request a deadline of timeout, which is passed through its context to every store
+// query made while serving it. Requests are left without a deadline when timeout is zero.
+func deadlineHandler(timeout time.Duration) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		if timeout <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// accessLogHandler logs a single event for each request once it has been served, with the method, route
+// template, status, number of bytes written and duration of the request
+func accessLogHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now().UTC()
+		rw := &responseRecorder{ResponseWriter: w}
+		next.ServeHTTP(rw, r)
+		end := time.Now().UTC()
+
+		log.Event(r.Context(), "http request completed", log.INFO, log.HTTP(r, rw.statusCode(), rw.bytes, &start, &end), log.Data{"route": routeTemplate(r)})
+	})
+}
+
+// routeTemplate returns the path template of the route matching a request, e.g. /datasets/{dataset_id}, or an
+// empty string when no route matched
+func routeTemplate(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+
+	return template
+}
+
+// responseRecorder captures the status and number of bytes of a response as it is written
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (rw *responseRecorder) WriteHeader(status int) {
+	if rw.status == 0 {
+		rw.status = status
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *responseRecorder) Write(b []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += int64(n)
+	return n, err
+}
+
+// statusCode returns the status of the response, which is 200 when a handler wrote nothing at all
+func (rw *responseRecorder) statusCode() int {
+	if rw.status == 0 {
+		return http.StatusOK
+	}
+
+	return rw.status
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	storetest "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store/datastoretest"
+	"github.com/ONSdigital/dp-net/request"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRequestIDHandler(t *testing.T) {
+
+	Convey("Given a handler wrapped with the request ID middleware", t, func() {
+		var contextID string
+		handler := requestIDHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			contextID = request.GetRequestId(r.Context())
+		}))
+
+		Convey("When a request has an X-Request-Id header", func() {
+			r := httptest.NewRequest("GET", "/datasets", nil)
+			r.Header.Set("X-Request-Id", "abc-123")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			Convey("Then its ID is added to the context and echoed in the response", func() {
+				So(contextID, ShouldEqual, "abc-123")
+				So(w.Header().Get("X-Request-Id"), ShouldEqual, "abc-123")
+			})
+		})
+
+		Convey("When a request has no X-Request-Id header", func() {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", "/datasets", nil))
+
+			Convey("Then an ID is generated", func() {
+				So(contextID, ShouldHaveLength, requestIDSize)
+				So(w.Header().Get("X-Request-Id"), ShouldEqual, contextID)
+			})
+		})
+
+		Convey("When a request has an X-Request-Id header that is not printable or too long", func() {
+			for _, id := range []string{"abc 123", strings.Repeat("a", maxRequestIDLength+1)} {
+				r := httptest.NewRequest("GET", "/datasets", nil)
+				r.Header.Set("X-Request-Id", id)
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, r)
+
+				So(contextID, ShouldHaveLength, requestIDSize)
+				So(w.Header().Get("X-Request-Id"), ShouldEqual, contextID)
+			}
+		})
+	})
+
+	Convey("Given the router of the API", t, func() {
+		api := newPublicAPI(&storetest.StorerMock{})
+
+		Convey("When a request does not match any route", func() {
+			r := httptest.NewRequest("GET", "/nope", nil)
+			r.Header.Set("X-Request-Id", "abc-123")
+			w := httptest.NewRecorder()
+			api.Router.ServeHTTP(w, r)
+
+			Convey("Then the ID of the request is echoed and given in the problem details", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+				So(w.Header().Get("X-Request-Id"), ShouldEqual, "abc-123")
+
+				var problem models.Problem
+				So(json.Unmarshal(w.Body.Bytes(), &problem), ShouldBeNil)
+				So(problem.RequestID, ShouldEqual, "abc-123")
+			})
+		})
+	})
+}
+
+func TestDeadlineHandler(t *testing.T) {
+
+	Convey("Given a handler wrapped with the deadline middleware", t, func() {
+		var deadline time.Time
+		var hasDeadline bool
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			deadline, hasDeadline = r.Context().Deadline()
+		})
+
+		Convey("When a request is served with a timeout", func() {
+			start := time.Now()
+			deadlineHandler(time.Minute)(handler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/datasets", nil))
+
+			Convey("Then its context is given a deadline of the timeout", func() {
+				So(hasDeadline, ShouldBeTrue)
+				So(deadline, ShouldHappenOnOrBetween, start.Add(time.Minute), time.Now().Add(time.Minute))
+			})
+		})
+
+		Convey("When a request is served with a timeout of zero", func() {
+			deadlineHandler(0)(handler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/datasets", nil))
+
+			Convey("Then its context is left without a deadline", func() {
+				So(hasDeadline, ShouldBeFalse)
+			})
+		})
+	})
+
+	Convey("Given a store whose queries outlast the deadline of the request", t, func() {
+		api := newPublicAPI(&storetest.StorerMock{
+			GetDatasetFunc: func(ctx context.Context, ID string) (*models.DatasetUpdate, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+		})
+		api.Router.Use(deadlineHandler(time.Millisecond))
+
+		Convey("When a request is made", func() {
+			w := httptest.NewRecorder()
+			api.Router.ServeHTTP(w, httptest.NewRequest("GET", "/datasets/People", nil))
+
+			Convey("Then it is reported as having timed out", func() {
+				So(w.Code, ShouldEqual, http.StatusGatewayTimeout)
+			})
+		})
+	})
+}
+
+func TestAccessLogHandler(t *testing.T) {
+
+	Convey("Given a route wrapped with the access log middleware", t, func() {
+		var template string
+		var recorder *responseRecorder
+		router := mux.NewRouter()
+		router.Use(accessLogHandler)
+		router.HandleFunc("/datasets/{dataset_id}", func(w http.ResponseWriter, r *http.Request) {
+			template = routeTemplate(r)
+			recorder = w.(*responseRecorder)
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte("accepted"))
+		})
+
+		Convey("When a request is served", func() {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", "/datasets/People", nil))
+
+			Convey("Then the route template, status and bytes of the response are captured", func() {
+				So(w.Code, ShouldEqual, http.StatusAccepted)
+				So(template, ShouldEqual, "/datasets/{dataset_id}")
+				So(recorder.statusCode(), ShouldEqual, http.StatusAccepted)
+				So(recorder.bytes, ShouldEqual, len("accepted"))
+			})
+		})
+	})
+
+	Convey("Given a response that has not been written to", t, func() {
+		recorder := &responseRecorder{ResponseWriter: httptest.NewRecorder()}
+
+		Convey("Then its status is 200", func() {
+			So(recorder.statusCode(), ShouldEqual, http.StatusOK)
+		})
+	})
+}
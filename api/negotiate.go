@@ -0,0 +1,99 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Media types that can be requested through the Accept header
+const (
+	csvContentType    = "text/csv"
+	csvwContentType   = "application/csvm+json"
+	jsonContentType   = "application/json"
+	jsonldContentType = "application/ld+json"
+)
+
+// mediaRange is a single, weighted, media range from an Accept header
+type mediaRange struct {
+	mediaType string
+	quality   float64
+}
+
+// negotiateContentType returns the offered media type that the Accept header of the request prefers, or an empty
+// string when none of the offers are acceptable. Without an Accept header the first offer is returned, and offers
+// of equal preference are chosen in the order given.
+func negotiateContentType(r *http.Request, offers ...string) string {
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return offers[0]
+	}
+
+	ranges := parseAccept(accept)
+
+	var best string
+	var bestQuality float64
+	for _, offer := range offers {
+		if quality := offerQuality(offer, ranges); quality > bestQuality {
+			best = offer
+			bestQuality = quality
+		}
+	}
+
+	return best
+}
+
+// offerQuality returns the quality given to an offer by the most specific media range that matches it
+func offerQuality(offer string, ranges []mediaRange) float64 {
+	offerType := strings.SplitN(offer, "/", 2)[0]
+
+	quality, specificity := 0.0, -1
+	for _, r := range ranges {
+		var s int
+		switch {
+		case r.mediaType == offer:
+			s = 2
+		case r.mediaType == offerType+"/*":
+			s = 1
+		case r.mediaType == "*/*":
+			s = 0
+		default:
+			continue
+		}
+
+		if s > specificity {
+			quality, specificity = r.quality, s
+		}
+	}
+
+	return quality
+}
+
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+
+		r := mediaRange{mediaType: strings.ToLower(strings.TrimSpace(params[0])), quality: 1}
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					r.quality = q
+				}
+			}
+		}
+
+		if r.mediaType != "" {
+			ranges = append(ranges, r)
+		}
+	}
+
+	return ranges
+}
+
+// setContentType sets the content type of the response, and marks it as varying by the Accept header
+func setContentType(w http.ResponseWriter, contentType string) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Add("Vary", "Accept")
+}
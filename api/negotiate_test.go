@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNegotiateContentType(t *testing.T) {
+
+	Convey("Given a set of offered content types", t, func() {
+		offers := []string{jsonContentType, csvContentType}
+
+		tests := map[string]string{
+			"":                                   jsonContentType,
+			"*/*":                                jsonContentType,
+			"text/csv":                           csvContentType,
+			"text/*":                             csvContentType,
+			"TEXT/CSV; charset=utf-8":            csvContentType,
+			"application/json;q=0.5, text/csv":   csvContentType,
+			"text/csv;q=0.2, */*;q=0.8":          jsonContentType,
+			"text/csv;q=0, */*":                  jsonContentType,
+			"application/xml":                    "",
+			"application/xml, text/html;q=0.9":   "",
+			"application/json;q=0, text/csv;q=0": "",
+		}
+
+		Convey("Then the offer preferred by the Accept header is chosen", func() {
+			for accept, expected := range tests {
+				r := httptest.NewRequest("GET", "/datasets", nil)
+				if accept != "" {
+					r.Header.Set("Accept", accept)
+				}
+
+				So(negotiateContentType(r, offers...), ShouldEqual, expected)
+			}
+		})
+	})
+}
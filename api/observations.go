@@ -0,0 +1,267 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	"github.com/ONSdigital/log.go/log"
+)
+
+const wildcard = "*"
+
+func (api *FTBDatasetAPI) getObservations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := routeVars(r)
+	datasetID := vars["dataset_id"]
+	edition := vars["edition"]
+	version := vars["version"]
+	logData := log.Data{"dataset_id": datasetID, "edition": edition, "version": version, "func": "getObservations"}
+
+	query, err := api.getObservationQuery(r.URL.Query())
+	if err != nil {
+		logData["query"] = r.URL.RawQuery
+		log.Event(ctx, "invalid observation query", log.ERROR, log.Error(err), logData)
+		handleAPIErr(ctx, w, r, err, logData)
+		return
+	}
+	query.DatasetID, query.Edition, query.Version = datasetID, edition, version
+	logData["query"] = query.Dimensions
+
+	versionDoc, err := api.getVisibleVersion(w, r, datasetID, edition, version)
+	if err != nil {
+		log.Event(ctx, "failed to get version", log.ERROR, log.Error(err), logData)
+		handleAPIErr(ctx, w, r, err, logData)
+		return
+	}
+
+	if err = models.CheckState("version", versionDoc.State); err != nil {
+		logData["version_state"] = versionDoc.State
+		log.Event(ctx, "unpublished version has an invalid state", log.ERROR, log.Error(err), logData)
+		handleAPIErr(ctx, w, r, err, logData)
+		return
+	}
+
+	// the options of the version and the observations link to the version, so a version without its links is
+	// rejected before the store is queried for options
+	if !hasVersionLinks(versionDoc) {
+		log.Event(ctx, "version is missing links to its dataset or itself", log.ERROR, log.Error(errs.ErrInternalServer), logData)
+		handleAPIErr(ctx, w, r, errs.ErrInternalServer, logData)
+		return
+	}
+
+	// options of each dimension by code, used to check the requested codes and label the observations
+	options := make(map[string]map[string]models.PublicDimensionOption)
+	for _, dimension := range query.Dimensions {
+		if !hasDimension(versionDoc, dimension.Name) {
+			logData["dimension"] = dimension.Name
+			log.Event(ctx, "dimension in query does not exist for version", log.ERROR, log.Error(errs.ErrObservationDimensionNotFound), logData)
+			handleAPIErr(ctx, w, r, errs.ErrObservationDimensionNotFound, logData)
+			return
+		}
+
+		if len(dimension.Codes) == 0 {
+			continue
+		}
+
+		if options[dimension.Name], err = api.getOptionsByCode(ctx, versionDoc, dimension.Name, dimension.Codes); err != nil {
+			log.Event(ctx, "failed to get dimension options in query", log.ERROR, log.Error(err), logData)
+			handleAPIErr(ctx, w, r, err, logData)
+			return
+		}
+
+		if len(options[dimension.Name]) != len(dimension.Codes) {
+			logData["dimension"] = dimension.Name
+			log.Event(ctx, "dimension option in query does not exist for version", log.ERROR, log.Error(errs.ErrObservationOptionNotFound), logData)
+			handleAPIErr(ctx, w, r, errs.ErrObservationOptionNotFound, logData)
+			return
+		}
+	}
+
+	crossTab, err := api.ftbClient.Query(ctx, query)
+	if err != nil {
+		log.Event(ctx, "ftb table engine failed to cross-tabulate version", log.ERROR, log.Error(err), logData)
+		handleAPIErr(ctx, w, r, err, logData)
+		return
+	}
+
+	if len(crossTab.Cells) == 0 {
+		log.Event(ctx, "no observations found for query", log.ERROR, log.Error(errs.ErrObservationsNotFound), logData)
+		handleAPIErr(ctx, w, r, errs.ErrObservationsNotFound, logData)
+		return
+	}
+
+	// the codes of a wildcard dimension are only known once the cross-tabulation has been returned
+	for i, dimension := range crossTab.Dimensions {
+		if _, ok := options[dimension]; ok {
+			continue
+		}
+
+		if options[dimension], err = api.getOptionsByCode(ctx, versionDoc, dimension, cellCodes(crossTab, i)); err != nil {
+			log.Event(ctx, "failed to get dimension options for wildcard", log.ERROR, log.Error(err), logData)
+			handleAPIErr(ctx, w, r, err, logData)
+			return
+		}
+	}
+
+	results := api.createObservationResults(r, versionDoc, crossTab, options)
+
+	b, err := json.Marshal(results)
+	if err != nil {
+		log.Event(ctx, "failed to marshal observations into bytes", log.ERROR, log.Error(err), logData)
+		handleAPIErr(ctx, w, r, err, logData)
+		return
+	}
+
+	setJSONContentType(w)
+	if err = writeBody(w, r, b, versionDoc.LastUpdated); err != nil {
+		log.Event(ctx, "error writing bytes to response", log.ERROR, log.Error(err), logData)
+		handleAPIErr(ctx, w, r, err, logData)
+	}
+
+	log.Event(ctx, "getObservations endpoint: request successful", log.INFO, logData)
+}
+
+// getObservationQuery reads the dimensions of an observation query from the query parameters, where each parameter
+// is a dimension and its values, repeated or comma separated, are the codes to restrict it to. A single dimension
+// can be given the wildcard value to break it down by all of its codes. Dimensions are sorted by name.
+func (api *FTBDatasetAPI) getObservationQuery(parameters url.Values) (*models.CrossTabQuery, error) {
+	query := &models.CrossTabQuery{}
+
+	var wildcards, numberOfCodes int
+	for name, values := range parameters {
+		dimension := models.CrossTabDimension{Name: name}
+
+		var isWildcard bool
+		seen := make(map[string]bool)
+		for _, value := range values {
+			for _, code := range strings.Split(value, ",") {
+				code = strings.TrimSpace(code)
+				switch {
+				case code == "":
+					return nil, errs.ErrInvalidQueryParameter
+				case code == wildcard:
+					isWildcard = true
+				case !seen[code]:
+					seen[code] = true
+					dimension.Codes = append(dimension.Codes, code)
+				}
+			}
+		}
+
+		if isWildcard {
+			wildcards++
+			dimension.Codes = nil
+		}
+
+		numberOfCodes += len(dimension.Codes)
+		query.Dimensions = append(query.Dimensions, dimension)
+	}
+
+	if len(query.Dimensions) == 0 {
+		return nil, errs.ErrObservationDimensionsMissing
+	}
+
+	if wildcards > 1 {
+		return nil, errs.ErrTooManyWildcards
+	}
+
+	if numberOfCodes > api.maxLimit {
+		return nil, errs.ErrTooManyQueryParameters
+	}
+
+	sort.Slice(query.Dimensions, func(i, j int) bool {
+		return query.Dimensions[i].Name < query.Dimensions[j].Name
+	})
+
+	return query, nil
+}
+
+// getOptionsByCode returns the options of a version's dimension with the given codes, keyed by code
+func (api *FTBDatasetAPI) getOptionsByCode(ctx context.Context, versionDoc *models.Version, dimension string, codes []string) (map[string]models.PublicDimensionOption, error) {
+	results, err := api.dataStore.Backend.GetDimensionOptionsFromIDs(ctx, versionDoc, dimension, codes)
+	if err != nil {
+		return nil, err
+	}
+
+	options := make(map[string]models.PublicDimensionOption)
+	for _, option := range results.Items {
+		options[option.Option] = option
+	}
+
+	return options, nil
+}
+
+// createObservationResults returns the observations of a cross-tabulation, labelled with the options of its
+// dimensions. The links of the version must have been checked by hasVersionLinks.
+func (api *FTBDatasetAPI) createObservationResults(r *http.Request, versionDoc *models.Version, crossTab *models.CrossTab, options map[string]map[string]models.PublicDimensionOption) *models.ObservationResults {
+	versionURL := fmt.Sprintf("%s/datasets/%s/editions/%s/versions/%s",
+		api.host, versionDoc.Links.Dataset.ID, versionDoc.Edition, versionDoc.Links.Version.ID)
+
+	results := &models.ObservationResults{
+		Count:      len(crossTab.Cells),
+		Dimensions: crossTab.Dimensions,
+		Links: &models.ObservationLinks{
+			Self:    &models.LinkObject{HRef: versionURL + "/observations?" + r.URL.RawQuery},
+			Version: &models.LinkObject{HRef: versionURL, ID: versionDoc.Links.Version.ID},
+		},
+		Observations: make([]models.Observation, 0, len(crossTab.Cells)),
+	}
+
+	for _, cell := range crossTab.Cells {
+		observation := models.Observation{
+			Dimensions:  make(map[string]*models.ObservationOption),
+			Observation: cell.Value,
+		}
+
+		for i, dimension := range crossTab.Dimensions {
+			code := cell.Codes[i]
+			option := options[dimension][code]
+			observation.Dimensions[dimension] = &models.ObservationOption{
+				HRef:  option.Links.Code.HRef,
+				ID:    code,
+				Label: option.Label,
+			}
+		}
+
+		results.Observations = append(results.Observations, observation)
+	}
+
+	return results
+}
+
+// hasVersionLinks checks a version links to its dataset and itself, both as an instance and as a version
+func hasVersionLinks(versionDoc *models.Version) bool {
+	links := versionDoc.Links
+	return links != nil && links.Dataset != nil && links.Self != nil && links.Version != nil
+}
+
+func hasDimension(versionDoc *models.Version, name string) bool {
+	for _, dimension := range versionDoc.Dimensions {
+		if dimension.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cellCodes returns the distinct codes of a dimension of a cross-tabulation, by the dimension's position
+func cellCodes(crossTab *models.CrossTab, index int) []string {
+	var codes []string
+	seen := make(map[string]bool)
+	for _, cell := range crossTab.Cells {
+		if code := cell.Codes[index]; !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+
+	return codes
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/config"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/ftb"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/memory"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store"
+	storetest "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store/datastoretest"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/url"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetObservations(t *testing.T) {
+
+	Convey("Given a published version with AGE and SEX dimensions", t, func() {
+		labels := map[string]string{"0-15": "Aged 0 to 15", "16-64": "Aged 16 to 64", "65+": "Aged 65 and over", "1": "Male", "2": "Female"}
+		links := &models.VersionLinks{
+			Dataset: &models.LinkObject{ID: "People"},
+			Self:    &models.LinkObject{HRef: "http://localhost:10400/instances/789"},
+			Version: &models.LinkObject{ID: "1"},
+		}
+
+		mockedDataStore := &storetest.StorerMock{
+			GetVersionFunc: func(ctx context.Context, datasetID, editionID, version, state string) (*models.Version, error) {
+				return &models.Version{
+					ID:         "789",
+					Edition:    "2011",
+					State:      models.PublishedState,
+					Dimensions: []models.Dimension{{Name: "AGE"}, {Name: "SEX"}},
+					Links:      links,
+				}, nil
+			},
+			GetDimensionOptionsFromIDsFunc: func(ctx context.Context, version *models.Version, dimension string, ids []string) (*models.DimensionOptionResults, error) {
+				results := &models.DimensionOptionResults{}
+				for _, id := range ids {
+					if label, ok := labels[id]; ok {
+						results.Items = append(results.Items, models.PublicDimensionOption{Name: dimension, Option: id, Label: label})
+					}
+				}
+				return results, nil
+			},
+		}
+
+		cfg, err := config.Get()
+		So(err, ShouldBeNil)
+		api := NewFTBDatasetAPI(context.Background(), *cfg, mux.NewRouter(), store.DataStore{Backend: mockedDataStore}, ftb.NewFileClient("../fixtures/cubes"), url.NewBuilder(cfg.WebsiteURL))
+
+		Convey("When observations are requested for a wildcard and a restricted dimension", func() {
+			r := httptest.NewRequest("GET", "http://localhost:10400/datasets/People/editions/2011/versions/1/observations?SEX=*&AGE=65%2B", nil)
+			w := httptest.NewRecorder()
+			api.Router.ServeHTTP(w, r)
+
+			Convey("Then a labelled observation is returned for each code of the wildcard dimension", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+
+				var results models.ObservationResults
+				So(json.Unmarshal(w.Body.Bytes(), &results), ShouldBeNil)
+				So(results.Count, ShouldEqual, 2)
+				So(results.Dimensions, ShouldResemble, []string{"AGE", "SEX"})
+				So(results.Observations[0].Observation, ShouldEqual, 4172419)
+				So(results.Observations[0].Dimensions["AGE"], ShouldResemble, &models.ObservationOption{ID: "65+", Label: "Aged 65 and over"})
+				So(results.Observations[1].Dimensions["SEX"], ShouldResemble, &models.ObservationOption{ID: "2", Label: "Female"})
+			})
+		})
+
+		Convey("When observations are requested for an option that does not exist", func() {
+			r := httptest.NewRequest("GET", "http://localhost:10400/datasets/People/editions/2011/versions/1/observations?AGE=0-15,99", nil)
+			w := httptest.NewRecorder()
+			api.Router.ServeHTTP(w, r)
+
+			Convey("Then a bad request is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+			})
+		})
+
+		Convey("When observations are requested for a dimension that does not exist", func() {
+			r := httptest.NewRequest("GET", "http://localhost:10400/datasets/People/editions/2011/versions/1/observations?REGION=E92000001", nil)
+			w := httptest.NewRecorder()
+			api.Router.ServeHTTP(w, r)
+
+			Convey("Then a bad request is returned without querying for options", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(mockedDataStore.GetDimensionOptionsFromIDsCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When observations are requested with more than one wildcard", func() {
+			r := httptest.NewRequest("GET", "http://localhost:10400/datasets/People/editions/2011/versions/1/observations?AGE=*&SEX=*", nil)
+			w := httptest.NewRecorder()
+			api.Router.ServeHTTP(w, r)
+
+			Convey("Then a bad request is returned without getting the version", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(mockedDataStore.GetVersionCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When observations are requested for a version without links to its dataset and itself", func() {
+			links.Version = nil
+			r := httptest.NewRequest("GET", "http://localhost:10400/datasets/People/editions/2011/versions/1/observations?SEX=*&AGE=65%2B", nil)
+			w := httptest.NewRecorder()
+			api.Router.ServeHTTP(w, r)
+
+			Convey("Then an internal server error is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusInternalServerError)
+			})
+		})
+	})
+}
+
+func TestGetObservationsWithoutVersionLinks(t *testing.T) {
+
+	Convey("Given a published version in the memory store that does not link to itself", t, func() {
+		ctx := context.Background()
+		memoryStore := memory.New()
+		So(memoryStore.AddVersion(ctx, &models.Version{
+			ID:         "789",
+			Edition:    "2011",
+			State:      models.PublishedState,
+			Version:    1,
+			Dimensions: []models.Dimension{{Name: "SEX"}},
+			Links: &models.VersionLinks{
+				Dataset: &models.LinkObject{ID: "People"},
+				Version: &models.LinkObject{ID: "1"},
+			},
+		}), ShouldBeNil)
+		So(memoryStore.AddDimensionOptions(ctx, []*models.DimensionOption{{InstanceID: "789", Name: "SEX", Option: "1", Label: "Male"}}), ShouldBeNil)
+
+		api := newPublicAPI(memoryStore)
+
+		Convey("When observations are requested for it", func() {
+			r := httptest.NewRequest("GET", "http://localhost:10400/datasets/People/editions/2011/versions/1/observations?SEX=1", nil)
+			w := httptest.NewRecorder()
+			api.Router.ServeHTTP(w, r)
+
+			Convey("Then an internal server error is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusInternalServerError)
+			})
+		})
+	})
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	"github.com/ONSdigital/go-ns/common"
+)
+
+// previewCollectionID returns the collection named by the Collection-Id header of a request, whose associated
+// versions can be previewed before they are published. Collection ids are not secret, so the header is ignored
+// unless the request is authorised with the service auth token. The header is added to the Vary header of the
+// response, as the versions returned depend on it, unless it has already been added.
+func (api *FTBDatasetAPI) previewCollectionID(w http.ResponseWriter, r *http.Request) string {
+	vary := false
+	for _, value := range w.Header()["Vary"] {
+		vary = vary || value == common.CollectionIDHeaderKey
+	}
+	if !vary {
+		w.Header().Add("Vary", common.CollectionIDHeaderKey)
+	}
+
+	if !api.isServiceAuthToken(r.Header.Get(common.AuthHeaderKey)) {
+		return ""
+	}
+
+	return r.Header.Get(common.CollectionIDHeaderKey)
+}
+
+// versionState returns the state used to look up versions and the dataset and edition they belong to. A request
+// previewing a collection looks them up whatever their state, and the versions found are then checked with
+// isVersionVisible.
+func (api *FTBDatasetAPI) versionState(collectionID string) string {
+	if collectionID != "" {
+		return ""
+	}
+
+	return api.state()
+}
+
+// isVersionVisible checks whether a version can be returned to a request previewing collectionID. Associated
+// versions are only visible to requests previewing their collection. Otherwise, in public mode only published
+// versions are visible, and in private mode every version is.
+func (api *FTBDatasetAPI) isVersionVisible(version *models.Version, collectionID string) bool {
+	if version.State == models.AssociatedState && collectionID != "" {
+		return version.CollectionID == collectionID
+	}
+
+	return api.enablePrivateEndpoints || version.State == models.PublishedState
+}
+
+// getVisibleVersion returns a version of an edition of a dataset, or ErrVersionNotFound when the version is not
+// visible to the request
+func (api *FTBDatasetAPI) getVisibleVersion(w http.ResponseWriter, r *http.Request, datasetID, edition, version string) (*models.Version, error) {
+	collectionID := api.previewCollectionID(w, r)
+
+	versionDoc, err := api.dataStore.Backend.GetVersion(r.Context(), datasetID, edition, version, api.versionState(collectionID))
+	if err != nil {
+		return nil, err
+	}
+
+	if !api.isVersionVisible(versionDoc, collectionID) {
+		return nil, errs.ErrVersionNotFound
+	}
+
+	return versionDoc, nil
+}
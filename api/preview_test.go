@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	storetest "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store/datastoretest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCollectionPreview(t *testing.T) {
+
+	Convey("Given a published version and versions associated with two collections", t, func() {
+		newVersions := func() []models.Version {
+			versions := []models.Version{
+				{Version: 1, State: models.PublishedState},
+				{Version: 2, State: models.AssociatedState, CollectionID: "census-2021"},
+				{Version: 3, State: models.AssociatedState, CollectionID: "labour-market"},
+				{Version: 4, State: models.EditionConfirmedState},
+			}
+			for i := range versions {
+				href := "http://localhost:10400/datasets/People/editions/2011/versions/" + strconv.Itoa(versions[i].Version)
+				versions[i].Links = &models.VersionLinks{Self: &models.LinkObject{}, Version: &models.LinkObject{HRef: href}}
+			}
+			return versions
+		}
+
+		// the mock applies the same selection by state as the stores
+		mockedDataStore := &storetest.StorerMock{
+			CheckDatasetExistsFunc: func(ctx context.Context, ID, state string) error {
+				return nil
+			},
+			CheckEditionExistsFunc: func(ctx context.Context, ID, editionID, state string) error {
+				return nil
+			},
+			GetVersionsFunc: func(ctx context.Context, datasetID, editionID, state string, query *models.VersionsQuery) (*models.VersionResults, error) {
+				results := &models.VersionResults{}
+				for _, version := range newVersions() {
+					if state == "" || version.State == state {
+						results.Items = append(results.Items, version)
+					}
+				}
+				return results, nil
+			},
+			GetVersionFunc: func(ctx context.Context, datasetID, editionID, versionID, state string) (*models.Version, error) {
+				for _, version := range newVersions() {
+					if strconv.Itoa(version.Version) == versionID && (state != models.PublishedState || version.State == state) {
+						return &version, nil
+					}
+				}
+				return nil, errs.ErrVersionNotFound
+			},
+		}
+
+		get := func(api *FTBDatasetAPI, target, collectionID string) *httptest.ResponseRecorder {
+			r := httptest.NewRequest("GET", target, nil)
+			if collectionID != "" {
+				r.Header.Set("Collection-Id", collectionID)
+			}
+			if api.enablePrivateEndpoints || collectionID != "" {
+				r.Header.Set("Authorization", "Bearer "+testServiceAuthToken)
+			}
+			w := httptest.NewRecorder()
+			api.Router.ServeHTTP(w, r)
+			return w
+		}
+
+		versionNumbers := func(w *httptest.ResponseRecorder) []int {
+			var results models.VersionResults
+			So(json.Unmarshal(w.Body.Bytes(), &results), ShouldBeNil)
+
+			var numbers []int
+			for _, version := range results.Items {
+				numbers = append(numbers, version.Version)
+			}
+			return numbers
+		}
+
+		Convey("When the API is in public mode", func() {
+			api := newPublicAPI(mockedDataStore)
+
+			Convey("Then only the published version is listed without a Collection-Id header", func() {
+				w := get(api, "/datasets/People/editions/2011/versions", "")
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(versionNumbers(w), ShouldResemble, []int{1})
+				So(w.Header()["Vary"], ShouldContain, "Collection-Id")
+			})
+
+			Convey("Then the associated version of the collection is listed with its Collection-Id header", func() {
+				w := get(api, "/datasets/People/editions/2011/versions", "census-2021")
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(versionNumbers(w), ShouldResemble, []int{1, 2})
+			})
+
+			Convey("Then the associated version can be previewed only with the Collection-Id of its collection", func() {
+				So(get(api, "/datasets/People/editions/2011/versions/2", "census-2021").Code, ShouldEqual, http.StatusOK)
+				So(get(api, "/datasets/People/editions/2011/versions/2", "labour-market").Code, ShouldEqual, http.StatusNotFound)
+				So(get(api, "/datasets/People/editions/2011/versions/2", "").Code, ShouldEqual, http.StatusNotFound)
+			})
+
+			Convey("Then a version that has not been associated with a collection stays hidden", func() {
+				So(get(api, "/datasets/People/editions/2011/versions/4", "census-2021").Code, ShouldEqual, http.StatusNotFound)
+			})
+
+			Convey("Then the Collection-Id header of a request without the service auth token is ignored", func() {
+				for _, authorization := range []string{"", "Bearer not-the-service-auth-token"} {
+					r := httptest.NewRequest("GET", "/datasets/People/editions/2011/versions/2", nil)
+					r.Header.Set("Collection-Id", "census-2021")
+					if authorization != "" {
+						r.Header.Set("Authorization", authorization)
+					}
+					w := httptest.NewRecorder()
+					api.Router.ServeHTTP(w, r)
+					So(w.Code, ShouldEqual, http.StatusNotFound)
+				}
+
+				r := httptest.NewRequest("GET", "/datasets/People/editions/2011/versions", nil)
+				r.Header.Set("Collection-Id", "census-2021")
+				w := httptest.NewRecorder()
+				api.Router.ServeHTTP(w, r)
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(versionNumbers(w), ShouldResemble, []int{1})
+			})
+		})
+
+		Convey("When the API is in private mode", func() {
+			api := newPrivateAPI(mockedDataStore)
+
+			Convey("Then every version is listed without a Collection-Id header", func() {
+				w := get(api, "/datasets/People/editions/2011/versions", "")
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(versionNumbers(w), ShouldResemble, []int{1, 2, 3, 4})
+			})
+
+			Convey("Then versions associated with other collections are hidden with a Collection-Id header", func() {
+				w := get(api, "/datasets/People/editions/2011/versions", "census-2021")
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(versionNumbers(w), ShouldResemble, []int{1, 2, 4})
+				So(get(api, "/datasets/People/editions/2011/versions/3", "census-2021").Code, ShouldEqual, http.StatusNotFound)
+			})
+		})
+	})
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	"github.com/ONSdigital/log.go/log"
+)
+
+func (api *FTBDatasetAPI) search(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := &models.SearchQuery{
+		Dimension: strings.TrimSpace(r.URL.Query().Get("dimension")),
+		FTBType:   strings.TrimSpace(r.URL.Query().Get("type")),
+		Q:         strings.TrimSpace(r.URL.Query().Get("q")),
+	}
+	logData := log.Data{"q": query.Q, "type": query.FTBType, "dimension": query.Dimension, "func": "search"}
+
+	if query.Q == "" {
+		log.Event(ctx, "search query missing", log.ERROR, log.Error(errs.ErrSearchQueryMissing), logData)
+		handleAPIErr(ctx, w, r, errs.ErrSearchQueryMissing, logData)
+		return
+	}
+
+	if query.FTBType != "" && query.FTBType != models.FTBBlobType && query.FTBType != models.FTBTableType {
+		log.Event(ctx, "invalid search type", log.ERROR, log.Error(errs.ErrSearchTypeInvalid), logData)
+		handleAPIErr(ctx, w, r, errs.ErrSearchTypeInvalid, logData)
+		return
+	}
+
+	var err error
+	if query.Offset, query.Limit, err = api.getPaginationParameters(r, logData); err != nil {
+		log.Event(ctx, "invalid pagination parameters", log.ERROR, log.Error(err), logData)
+		handleAPIErr(ctx, w, r, err, logData)
+		return
+	}
+
+	results, err := api.dataStore.Searcher.Search(ctx, query)
+	if err != nil {
+		log.Event(ctx, "failed to search datasets", log.ERROR, log.Error(err), logData)
+		handleAPIErr(ctx, w, r, err, logData)
+		return
+	}
+
+	b, err := json.Marshal(results)
+	if err != nil {
+		log.Event(ctx, "failed to marshal search results into bytes", log.ERROR, log.Error(err), logData)
+		handleAPIErr(ctx, w, r, err, logData)
+		return
+	}
+
+	setJSONContentType(w)
+	if err = writeBody(w, r, b, time.Time{}); err != nil {
+		log.Event(ctx, "error writing bytes to response", log.ERROR, log.Error(err), logData)
+		handleAPIErr(ctx, w, r, err, logData)
+	}
+
+	log.Event(ctx, "search endpoint: request successful", log.INFO, logData)
+}
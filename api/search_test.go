@@ -0,0 +1,65 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/config"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store"
+	storetest "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store/datastoretest"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/url"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSearch(t *testing.T) {
+
+	Convey("Given a searcher that finds the People dataset", t, func() {
+		mockedSearcher := &storetest.SearcherMock{
+			SearchFunc: func(ctx context.Context, query *models.SearchQuery) (*models.SearchResults, error) {
+				result := models.SearchResult{ID: "People", Score: 10, Highlights: map[string][]string{"title": {"<em>People</em>"}}}
+				return models.NewSearchResults([]models.SearchResult{result}, query.Offset, query.Limit), nil
+			},
+		}
+
+		cfg, err := config.Get()
+		So(err, ShouldBeNil)
+		api := NewFTBDatasetAPI(context.Background(), *cfg, mux.NewRouter(), store.DataStore{Backend: &storetest.StorerMock{}, Searcher: mockedSearcher}, nil, url.NewBuilder(cfg.WebsiteURL))
+
+		Convey("When a search is requested", func() {
+			r := httptest.NewRequest("GET", "http://localhost:10400/search?q=people&type=ftb-blob&dimension=AGE&limit=5", nil)
+			w := httptest.NewRecorder()
+			api.Router.ServeHTTP(w, r)
+
+			Convey("Then the query is passed to the searcher and its results returned", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(mockedSearcher.SearchCalls(), ShouldHaveLength, 1)
+				So(*mockedSearcher.SearchCalls()[0].Query, ShouldResemble, models.SearchQuery{
+					Dimension: "AGE", FTBType: models.FTBBlobType, Limit: 5, Offset: cfg.DefaultOffset, Q: "people",
+				})
+
+				var results models.SearchResults
+				So(json.Unmarshal(w.Body.Bytes(), &results), ShouldBeNil)
+				So(results.TotalCount, ShouldEqual, 1)
+				So(results.Items[0].Highlights["title"], ShouldResemble, []string{"<em>People</em>"})
+			})
+		})
+
+		Convey("When a search is requested without a query or with an unknown type", func() {
+			for _, target := range []string{"/search", "/search?q=+", "/search?q=people&type=cantabular"} {
+				r := httptest.NewRequest("GET", "http://localhost:10400"+target, nil)
+				w := httptest.NewRecorder()
+				api.Router.ServeHTTP(w, r)
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+			}
+
+			Convey("Then the searcher is not called", func() {
+				So(mockedSearcher.SearchCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+}
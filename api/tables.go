@@ -0,0 +1,379 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	"github.com/ONSdigital/log.go/log"
+	"github.com/gorilla/mux"
+	uuid "github.com/satori/go.uuid"
+)
+
+// tableVersion is the version of the dataset created for a new table
+const tableVersion = 1
+
+// blob holds the documents of the ftb-blob version a table is created from
+type blob struct {
+	dataset *models.Dataset
+	edition *models.Edition
+	version *models.Version
+}
+
+func (api *FTBDatasetAPI) addTable(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	datasetID := vars["dataset_id"]
+	edition := vars["edition"]
+	version := vars["version"]
+	logData := log.Data{"dataset_id": datasetID, "edition": edition, "version": version, "func": "addTable"}
+
+	tableDoc, err := func() (*models.DatasetUpdate, error) {
+		table, err := models.CreateNewTable(r.Body)
+		if err != nil {
+			log.Event(ctx, "failed to model table resource based on request", log.ERROR, log.Error(err), logData)
+			return nil, err
+		}
+
+		logData["table_id"] = table.ID
+		logData["table_dimensions"] = table.Dimensions
+
+		if err = models.ValidateNewTable(table); err != nil {
+			log.Event(ctx, "table resource failed validation", log.ERROR, log.Error(err), logData)
+			return nil, err
+		}
+
+		source, err := api.getBlob(ctx, datasetID, edition, version)
+		if err != nil {
+			log.Event(ctx, "failed to get ftb-blob version for table", log.ERROR, log.Error(err), logData)
+			return nil, err
+		}
+
+		dimensions, err := getTableDimensions(source.version, table.Dimensions)
+		if err != nil {
+			log.Event(ctx, "table dimensions do not exist for ftb-blob version", log.ERROR, log.Error(err), logData)
+			return nil, err
+		}
+
+		if err = api.dataStore.Backend.CheckDatasetExists(ctx, table.ID, ""); err == nil {
+			log.Event(ctx, "unable to create table, dataset already exists", log.ERROR, log.Error(errs.ErrAddDatasetAlreadyExists), logData)
+			return nil, errs.ErrAddDatasetAlreadyExists
+		} else if err != errs.ErrDatasetNotFound {
+			log.Event(ctx, "failed to check if table dataset exists", log.ERROR, log.Error(err), logData)
+			return nil, err
+		}
+
+		versionDoc := api.createTableVersion(source, table, dimensions)
+		logData["table_instance_id"] = versionDoc.ID
+
+		options, err := api.copyDimensionOptions(ctx, source.version, versionDoc)
+		if err != nil {
+			log.Event(ctx, "failed to get dimension options of ftb-blob version", log.ERROR, log.Error(err), logData)
+			return nil, err
+		}
+
+		// The dataset is written last, as its existence is checked above, so that a request that fails part way
+		// through can be retried. Every write before it replaces or leaves what an earlier attempt wrote, as the ids
+		// of the table's documents are derived from its dataset and edition.
+		if err = api.dataStore.Backend.AddDimensionOptions(ctx, options); err != nil {
+			log.Event(ctx, "failed to add dimension options of table", log.ERROR, log.Error(err), logData)
+			return nil, err
+		}
+
+		if err = api.dataStore.Backend.AddVersion(ctx, versionDoc); err != nil {
+			log.Event(ctx, "failed to add version of table", log.ERROR, log.Error(err), logData)
+			return nil, err
+		}
+
+		editionDoc := api.createTableEdition(source, versionDoc)
+		if err = api.dataStore.Backend.UpsertEdition(ctx, table.ID, edition, editionDoc); err != nil {
+			log.Event(ctx, "failed to add edition of table", log.ERROR, log.Error(err), logData)
+			return nil, err
+		}
+
+		datasetDoc := api.createTableDataset(source, table, versionDoc)
+		links := &models.TableLinks{
+			Dataset: models.Table{HRef: datasetDoc.Next.Links.Self.HRef, Title: table.Title},
+			Edition: models.Table{HRef: editionDoc.Next.Links.Self.HRef, Title: table.Title},
+			Version: models.Table{HRef: versionDoc.Links.Version.HRef, Title: table.Title},
+		}
+
+		if err = api.dataStore.Backend.AddTableLinks(ctx, datasetID, edition, source.version.ID, links); err != nil {
+			log.Event(ctx, "failed to add table links to ftb-blob", log.ERROR, log.Error(err), logData)
+			return nil, err
+		}
+
+		if err = api.dataStore.Backend.UpsertDataset(ctx, table.ID, datasetDoc); err != nil {
+			log.Event(ctx, "failed to add dataset of table", log.ERROR, log.Error(err), logData)
+			return nil, err
+		}
+
+		return datasetDoc, nil
+	}()
+	if err != nil {
+		handleAPIErr(ctx, w, r, err, logData)
+		return
+	}
+
+	b, err := json.Marshal(tableDoc)
+	if err != nil {
+		log.Event(ctx, "failed to marshal table dataset resource into bytes", log.ERROR, log.Error(err), logData)
+		handleAPIErr(ctx, w, r, err, logData)
+		return
+	}
+
+	setJSONContentType(w)
+	w.Header().Set("Location", tableDoc.Next.Links.Self.HRef)
+	w.WriteHeader(http.StatusCreated)
+	if _, err = w.Write(b); err != nil {
+		log.Event(ctx, "error writing bytes to response", log.ERROR, log.Error(err), logData)
+	}
+	log.Event(ctx, "addTable endpoint: request successful", log.INFO, logData)
+}
+
+// getBlob returns the dataset, edition and version documents of an ftb-blob version. The published dataset and
+// edition are used for a published version, otherwise the next.
+func (api *FTBDatasetAPI) getBlob(ctx context.Context, datasetID, edition, version string) (*blob, error) {
+	versionDoc, err := api.dataStore.Backend.GetVersion(ctx, datasetID, edition, version, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if versionDoc.FTBType != models.FTBBlobType {
+		return nil, errs.ErrTableBlobInvalid
+	}
+
+	datasetDoc, err := api.dataStore.Backend.GetDataset(ctx, datasetID)
+	if err != nil {
+		return nil, err
+	}
+
+	editionDoc, err := api.dataStore.Backend.GetEdition(ctx, datasetID, edition, "")
+	if err != nil {
+		return nil, err
+	}
+
+	source := &blob{dataset: datasetDoc.Next, edition: editionDoc.Next, version: versionDoc}
+	if versionDoc.State == models.PublishedState {
+		if datasetDoc.Current != nil {
+			source.dataset = datasetDoc.Current
+		}
+		if editionDoc.Current != nil {
+			source.edition = editionDoc.Current
+		}
+	}
+
+	if source.dataset == nil || source.edition == nil {
+		return nil, errs.ErrResourceState
+	}
+
+	return source, nil
+}
+
+// getTableDimensions returns the dimensions of an ftb-blob version with the given ids, in the order requested.
+// The flexible dimensions of the version are used when it has them.
+func getTableDimensions(blobVersion *models.Version, ids []string) ([]models.Dimension, error) {
+	available := blobVersion.Dimensions
+	if blobVersion.FlexDimensions != nil {
+		available = *blobVersion.FlexDimensions
+	}
+
+	byID := make(map[string]models.Dimension)
+	for _, dimension := range available {
+		byID[dimensionID(dimension)] = dimension
+	}
+
+	dimensions := make([]models.Dimension, 0, len(ids))
+	for _, id := range ids {
+		dimension, ok := byID[id]
+		if !ok {
+			return nil, errs.ErrTableDimensionNotFound
+		}
+		dimensions = append(dimensions, dimension)
+	}
+
+	return dimensions, nil
+}
+
+// copyDimensionOptions returns a copy of the options of each dimension of a table, taken from the ftb-blob
+// version it is created from, and sets the number of options of each of the table's dimensions
+func (api *FTBDatasetAPI) copyDimensionOptions(ctx context.Context, blobVersion, versionDoc *models.Version) ([]*models.DimensionOption, error) {
+	var options []*models.DimensionOption
+
+	for i, dimension := range versionDoc.Dimensions {
+		name := dimensionID(dimension)
+
+		for offset := 0; ; offset += api.maxLimit {
+			page, err := api.dataStore.Backend.GetDimensionOptions(ctx, blobVersion, name, offset, api.maxLimit)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, option := range page.Items {
+				options = append(options, &models.DimensionOption{
+					InstanceID: versionDoc.ID,
+					Label:      option.Label,
+					Links: models.DimensionOptionLinks{
+						Code:     option.Links.Code,
+						CodeList: option.Links.CodeList,
+						Version:  *versionDoc.Links.Version,
+					},
+					Name:   option.Name,
+					Option: option.Option,
+				})
+			}
+
+			if len(page.Items) == 0 || offset+len(page.Items) >= page.TotalCount {
+				versionDoc.Dimensions[i].NumberOfOptions = page.TotalCount
+				break
+			}
+		}
+	}
+
+	log.Event(ctx, "copied dimension options of ftb-blob version", log.INFO, log.Data{"instance_id": versionDoc.ID, "number_of_options": len(options)})
+
+	return options, nil
+}
+
+func (api *FTBDatasetAPI) createTableVersion(source *blob, table *models.NewTable, dimensions []models.Dimension) *models.Version {
+	datasetURL := fmt.Sprintf("%s/datasets/%s", api.host, table.ID)
+	editionURL := fmt.Sprintf("%s/editions/%s", datasetURL, source.version.Edition)
+	versionURL := fmt.Sprintf("%s/versions/%d", editionURL, tableVersion)
+	instanceID := tableDocumentID(versionURL)
+
+	headers := []string{models.FTBTableType}
+	for _, dimension := range dimensions {
+		headers = append(headers, dimensionID(dimension))
+	}
+
+	// The blob's dimensions are the flexible dimensions the table could have been built from
+	flexDimensions := source.version.Dimensions
+	if source.version.FlexDimensions != nil {
+		flexDimensions = *source.version.FlexDimensions
+	}
+
+	versionDoc := &models.Version{
+		CollectionID:   source.version.CollectionID,
+		Dimensions:     dimensions,
+		Edition:        source.version.Edition,
+		FTBType:        models.FTBTableType,
+		FlexDimensions: &flexDimensions,
+		Headers:        headers,
+		ID:             instanceID,
+		Links: &models.VersionLinks{
+			Dataset:    &models.LinkObject{HRef: datasetURL, ID: table.ID},
+			Dimensions: &models.LinkObject{HRef: versionURL + "/dimensions"},
+			Edition:    &models.LinkObject{HRef: editionURL, ID: source.version.Edition},
+			Self:       &models.LinkObject{HRef: fmt.Sprintf("%s/instances/%s", api.host, instanceID)},
+			Version:    &models.LinkObject{HRef: versionURL, ID: fmt.Sprint(tableVersion)},
+		},
+		ReleaseDate: source.version.ReleaseDate,
+		State:       source.version.State,
+		Temporal:    source.version.Temporal,
+		Type:        source.version.Type,
+		Version:     tableVersion,
+	}
+
+	if source.version.Links != nil {
+		versionDoc.Links.Spatial = source.version.Links.Spatial
+		if source.version.Links.Version != nil {
+			versionDoc.IsBasedOn = &[]models.IsBasedOn{{ID: source.version.Links.Version.HRef, Type: "DataSet"}}
+		}
+	}
+
+	return versionDoc
+}
+
+func (api *FTBDatasetAPI) createTableDataset(source *blob, table *models.NewTable, versionDoc *models.Version) *models.DatasetUpdate {
+	keywords := append([]string{}, source.dataset.Keywords...)
+	for _, dimension := range versionDoc.Dimensions {
+		if dimension.Category != "" {
+			keywords = append(keywords, strings.ToLower(dimension.Category))
+		}
+	}
+
+	dataset := &models.Dataset{
+		CollectionID:      versionDoc.CollectionID,
+		Contacts:          source.dataset.Contacts,
+		Description:       table.Description,
+		FTBType:           models.FTBTableType,
+		ID:                table.ID,
+		Keywords:          keywords,
+		License:           source.dataset.License,
+		Methodologies:     source.dataset.Methodologies,
+		NationalStatistic: source.dataset.NationalStatistic,
+		NextRelease:       source.dataset.NextRelease,
+		Publisher:         source.dataset.Publisher,
+		QMI:               source.dataset.QMI,
+		ReleaseFrequency:  source.dataset.ReleaseFrequency,
+		State:             versionDoc.State,
+		Theme:             source.dataset.Theme,
+		Title:             table.Title,
+		Type:              source.dataset.Type,
+		UnitOfMeasure:     source.dataset.UnitOfMeasure,
+		Links: &models.DatasetLinks{
+			Editions:      &models.LinkObject{HRef: versionDoc.Links.Dataset.HRef + "/editions"},
+			LatestVersion: versionDoc.Links.Version,
+			Self:          &models.LinkObject{HRef: versionDoc.Links.Dataset.HRef},
+		},
+	}
+
+	if source.dataset.Links != nil && source.dataset.Links.Self != nil {
+		dataset.IsBasedOn = &[]models.IsBasedOn{{ID: source.dataset.Links.Self.HRef, Type: "DataSet"}}
+	}
+
+	datasetDoc := &models.DatasetUpdate{ID: table.ID, Next: dataset}
+	if versionDoc.State == models.PublishedState {
+		datasetDoc.Current = dataset
+	}
+
+	return datasetDoc
+}
+
+func (api *FTBDatasetAPI) createTableEdition(source *blob, versionDoc *models.Version) *models.EditionUpdate {
+	edition := &models.Edition{
+		Edition: versionDoc.Edition,
+		FTBType: models.FTBTableType,
+		ID:      tableDocumentID(versionDoc.Links.Edition.HRef),
+		Links: &models.EditionUpdateLinks{
+			Dataset:       versionDoc.Links.Dataset,
+			LatestVersion: versionDoc.Links.Version,
+			Self:          &models.LinkObject{HRef: versionDoc.Links.Edition.HRef},
+			Versions:      &models.LinkObject{HRef: versionDoc.Links.Edition.HRef + "/versions"},
+		},
+		State: source.edition.State,
+		Type:  source.edition.Type,
+	}
+
+	if source.edition.Links != nil && source.edition.Links.Self != nil {
+		edition.IsBasedOn = &[]models.IsBasedOn{{ID: source.edition.Links.Self.HRef, Type: "DataSet"}}
+	}
+
+	editionDoc := &models.EditionUpdate{ID: edition.ID, Next: edition}
+	if versionDoc.State == models.PublishedState {
+		editionDoc.Current = edition
+	}
+
+	return editionDoc
+}
+
+// tableDocumentID returns the id of a document of a table, derived from its url so that the same id is used when
+// the table's creation is retried
+func tableDocumentID(url string) string {
+	return uuid.NewV5(uuid.NamespaceURL, url).String()
+}
+
+// dimensionID returns the id of a version dimension, which is also the name of its dimension options
+func dimensionID(dimension models.Dimension) string {
+	if dimension.ID != "" {
+		return dimension.ID
+	}
+
+	return dimension.Name
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/memory"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store"
+	storetest "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store/datastoretest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAddTable(t *testing.T) {
+
+	Convey("Given a published ftb-blob version with AGE and SEX dimensions", t, func() {
+		blobVersion := &models.Version{
+			ID:         "789",
+			Edition:    "2011",
+			FTBType:    models.FTBBlobType,
+			State:      models.PublishedState,
+			Dimensions: []models.Dimension{{ID: "AGE", Name: "Age"}, {ID: "SEX", Name: "Sex", Category: "Sex"}},
+			Links: &models.VersionLinks{
+				Version: &models.LinkObject{HRef: "http://localhost:10400/datasets/People/editions/2011/versions/1", ID: "1"},
+			},
+		}
+		blobDataset := &models.Dataset{ID: "People", License: "Open Government Licence v3.0", Keywords: []string{"census"}}
+		blobEdition := &models.Edition{Edition: "2011", State: models.PublishedState}
+
+		mockedDataStore := &storetest.StorerMock{
+			GetVersionFunc: func(ctx context.Context, datasetID, editionID, version, state string) (*models.Version, error) {
+				return blobVersion, nil
+			},
+			GetDatasetFunc: func(ctx context.Context, ID string) (*models.DatasetUpdate, error) {
+				return &models.DatasetUpdate{ID: ID, Current: blobDataset, Next: blobDataset}, nil
+			},
+			GetEditionFunc: func(ctx context.Context, ID, editionID, state string) (*models.EditionUpdate, error) {
+				return &models.EditionUpdate{Current: blobEdition, Next: blobEdition}, nil
+			},
+			CheckDatasetExistsFunc: func(ctx context.Context, ID, state string) error {
+				return errs.ErrDatasetNotFound
+			},
+			GetDimensionOptionsFunc: func(ctx context.Context, version *models.Version, dimension string, offset, limit int) (*models.DimensionOptionResults, error) {
+				items := []models.PublicDimensionOption{{Name: dimension, Option: "1", Label: "Male"}, {Name: dimension, Option: "2", Label: "Female"}}
+				return &models.DimensionOptionResults{Count: 2, Items: items, TotalCount: 2}, nil
+			},
+			AddDimensionOptionsFunc: func(ctx context.Context, options []*models.DimensionOption) error {
+				return nil
+			},
+			AddVersionFunc: func(ctx context.Context, version *models.Version) error {
+				return nil
+			},
+			UpsertDatasetFunc: func(ctx context.Context, ID string, datasetDoc *models.DatasetUpdate) error {
+				return nil
+			},
+			UpsertEditionFunc: func(ctx context.Context, datasetID, edition string, editionDoc *models.EditionUpdate) error {
+				return nil
+			},
+			AddTableLinksFunc: func(ctx context.Context, datasetID, editionID, instanceID string, links *models.TableLinks) error {
+				return nil
+			},
+		}
+
+		api := newPrivateAPI(mockedDataStore)
+
+		Convey("When a table of the SEX dimension is created", func() {
+			body := strings.NewReader(`{"title": "People by sex", "description": "Usual residents by sex", "dimensions": ["SEX"]}`)
+			r := httptest.NewRequest("POST", "http://localhost:10400/datasets/People/editions/2011/versions/1/tables", body)
+			r.Header.Set("Authorization", "Bearer "+testServiceAuthToken)
+			w := httptest.NewRecorder()
+			api.Router.ServeHTTP(w, r)
+
+			Convey("Then the table's documents are created and linked to the blob", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(w.Header().Get("Location"), ShouldEqual, api.host+"/datasets/people-by-sex")
+
+				So(mockedDataStore.AddDimensionOptionsCalls(), ShouldHaveLength, 1)
+				options := mockedDataStore.AddDimensionOptionsCalls()[0].Options
+				So(options, ShouldHaveLength, 2)
+
+				So(mockedDataStore.AddVersionCalls(), ShouldHaveLength, 1)
+				version := mockedDataStore.AddVersionCalls()[0].Version
+				So(version.FTBType, ShouldEqual, models.FTBTableType)
+				So(version.Dimensions, ShouldResemble, []models.Dimension{{ID: "SEX", Name: "Sex", Category: "Sex", NumberOfOptions: 2}})
+				So(*version.FlexDimensions, ShouldResemble, blobVersion.Dimensions)
+				So(options[0].InstanceID, ShouldEqual, version.ID)
+
+				So(mockedDataStore.UpsertDatasetCalls(), ShouldHaveLength, 1)
+				dataset := mockedDataStore.UpsertDatasetCalls()[0].DatasetDoc
+				So(dataset.Current, ShouldNotBeNil)
+				So(dataset.Next.Keywords, ShouldResemble, []string{"census", "sex"})
+
+				So(mockedDataStore.AddTableLinksCalls(), ShouldHaveLength, 1)
+				call := mockedDataStore.AddTableLinksCalls()[0]
+				So(call.InstanceID, ShouldEqual, "789")
+				So(call.Links.Version.HRef, ShouldEqual, api.host+"/datasets/people-by-sex/editions/2011/versions/1")
+			})
+		})
+
+		Convey("When a table of a dimension the blob does not have is created", func() {
+			body := strings.NewReader(`{"title": "People by region", "dimensions": ["REGION"]}`)
+			r := httptest.NewRequest("POST", "http://localhost:10400/datasets/People/editions/2011/versions/1/tables", body)
+			r.Header.Set("Authorization", "Bearer "+testServiceAuthToken)
+			w := httptest.NewRecorder()
+			api.Router.ServeHTTP(w, r)
+
+			Convey("Then a bad request is returned and nothing is created", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(mockedDataStore.AddVersionCalls(), ShouldHaveLength, 0)
+				So(mockedDataStore.AddTableLinksCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When a table is created from a version that is not an ftb-blob", func() {
+			blobVersion.FTBType = models.FTBTableType
+			body := strings.NewReader(`{"title": "People by sex", "dimensions": ["SEX"]}`)
+			r := httptest.NewRequest("POST", "http://localhost:10400/datasets/People/editions/2011/versions/1/tables", body)
+			r.Header.Set("Authorization", "Bearer "+testServiceAuthToken)
+			w := httptest.NewRecorder()
+			api.Router.ServeHTTP(w, r)
+
+			Convey("Then a bad request is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(mockedDataStore.AddVersionCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+}
+
+// failingStorer fails the first call to the named write method of the store it wraps
+type failingStorer struct {
+	store.Storer
+	fail string
+}
+
+func (s *failingStorer) failOnce(method string) error {
+	if s.fail == method {
+		s.fail = ""
+		return errors.New("connection reset")
+	}
+	return nil
+}
+
+func (s *failingStorer) UpsertEdition(ctx context.Context, datasetID, edition string, editionDoc *models.EditionUpdate) error {
+	if err := s.failOnce("UpsertEdition"); err != nil {
+		return err
+	}
+	return s.Storer.UpsertEdition(ctx, datasetID, edition, editionDoc)
+}
+
+func (s *failingStorer) UpsertDataset(ctx context.Context, ID string, datasetDoc *models.DatasetUpdate) error {
+	if err := s.failOnce("UpsertDataset"); err != nil {
+		return err
+	}
+	return s.Storer.UpsertDataset(ctx, ID, datasetDoc)
+}
+
+func TestAddTableRetry(t *testing.T) {
+
+	Convey("Given the People ftb-blob in the memory store", t, func() {
+		ctx := context.Background()
+		memoryStore := memory.New()
+		So(memoryStore.Load("../fixtures"), ShouldBeNil)
+
+		blobVersion, err := memoryStore.GetVersion(ctx, "People", "2011", "1", models.PublishedState)
+		So(err, ShouldBeNil)
+		sexOptions, err := memoryStore.GetDimensionOptions(ctx, blobVersion, "SEX", 0, 0)
+		So(err, ShouldBeNil)
+
+		failing := &failingStorer{Storer: memoryStore}
+		api := newPrivateAPI(failing)
+
+		post := func() int {
+			body := strings.NewReader(`{"title": "People by sex", "dimensions": ["SEX"]}`)
+			r := httptest.NewRequest("POST", "http://localhost:10400/datasets/People/editions/2011/versions/1/tables", body)
+			r.Header.Set("Authorization", "Bearer "+testServiceAuthToken)
+			w := httptest.NewRecorder()
+			api.Router.ServeHTTP(w, r)
+			return w.Code
+		}
+
+		for _, step := range []string{"UpsertEdition", "UpsertDataset"} {
+			step := step
+
+			Convey("When creating a table fails at "+step+" and is retried", func() {
+				failing.fail = step
+				So(post(), ShouldEqual, http.StatusInternalServerError)
+				So(post(), ShouldEqual, http.StatusCreated)
+
+				Convey("Then the table has a single version with a copy of each option", func() {
+					instances, err := memoryStore.GetInstances(ctx, nil, []string{"people-by-sex"})
+					So(err, ShouldBeNil)
+					So(instances.Items, ShouldHaveLength, 1)
+
+					version, err := memoryStore.GetVersion(ctx, "people-by-sex", "2011", "1", "")
+					So(err, ShouldBeNil)
+					options, err := memoryStore.GetDimensionOptions(ctx, version, "SEX", 0, 0)
+					So(err, ShouldBeNil)
+					So(options.TotalCount, ShouldEqual, sexOptions.TotalCount)
+
+					editions, err := memoryStore.GetEditions(ctx, "people-by-sex", "", nil)
+					So(err, ShouldBeNil)
+					So(editions.Items, ShouldHaveLength, 1)
+				})
+
+				Convey("Then the blob links to the table once", func() {
+					datasetDoc, err := memoryStore.GetDataset(ctx, "People")
+					So(err, ShouldBeNil)
+					So(*datasetDoc.Next.Tables, ShouldHaveLength, 1)
+
+					editionDoc, err := memoryStore.GetEdition(ctx, "People", "2011", "")
+					So(err, ShouldBeNil)
+					So(*editionDoc.Next.Tables, ShouldHaveLength, 1)
+
+					version, err := memoryStore.GetVersion(ctx, "People", "2011", "1", "")
+					So(err, ShouldBeNil)
+					So(*version.Tables, ShouldHaveLength, 1)
+				})
+
+				Convey("Then a further attempt is forbidden, as the table exists", func() {
+					So(post(), ShouldEqual, http.StatusForbidden)
+				})
+			})
+		}
+	})
+}
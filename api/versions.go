@@ -3,8 +3,10 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
-	"strings"
+	"strconv"
+	"time"
 
 	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
 	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
@@ -12,28 +14,6 @@ import (
 	"github.com/gorilla/mux"
 )
 
-var (
-	// errors that map to a HTTP 404 response
-	notFound = map[error]bool{
-		errs.ErrDatasetNotFound: true,
-		errs.ErrEditionNotFound: true,
-		errs.ErrVersionNotFound: true,
-	}
-
-	// errors that map to a HTTP 400 response
-	badRequest = map[error]bool{
-		errs.ErrUnableToParseJSON:                      true,
-		models.ErrPublishedVersionCollectionIDInvalid:  true,
-		models.ErrAssociatedVersionCollectionIDInvalid: true,
-		models.ErrVersionStateInvalid:                  true,
-	}
-
-	// HTTP 500 responses with a specific message
-	internalServerErrWithMessage = map[error]bool{
-		errs.ErrResourceState: true,
-	}
-)
-
 // VersionDetails contains the details that uniquely identify a version resource
 type VersionDetails struct {
 	datasetID string
@@ -43,29 +23,67 @@ type VersionDetails struct {
 
 func (api *FTBDatasetAPI) getVersions(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	vars := mux.Vars(r)
+	vars := routeVars(r)
 	datasetID := vars["dataset_id"]
 	edition := vars["edition"]
 	logData := log.Data{"dataset_id": datasetID, "edition": edition}
 
-	var state string
+	collectionID := api.previewCollectionID(w, r)
+	state := api.versionState(collectionID)
+	logData["collection_id"] = collectionID
+
+	query, latestOnly, err := getVersionsQuery(r, logData)
+	if err != nil {
+		log.Event(ctx, "invalid query parameters for list of versions", log.ERROR, log.Error(err), logData)
+		handleAPIErr(ctx, w, r, err, logData)
+		return
+	}
 
-	if err := api.dataStore.Backend.CheckDatasetExists(datasetID, state); err != nil {
+	if err = api.dataStore.Backend.CheckDatasetExists(ctx, datasetID, state); err != nil {
 		log.Event(ctx, "failed to find dataset for list of versions", log.ERROR, log.Error(err), logData)
-		handleVersionAPIErr(ctx, err, w, logData)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
-	if err := api.dataStore.Backend.CheckEditionExists(datasetID, edition, state); err != nil {
+	if latestOnly {
+		// the latest version is the one linked from the revision of the edition that the request reads
+		editionDoc, err := api.dataStore.Backend.GetEdition(ctx, datasetID, edition, state)
+		if err != nil {
+			log.Event(ctx, "failed to find edition for list of versions", log.ERROR, log.Error(err), logData)
+			handleAPIErr(ctx, w, r, err, logData)
+			return
+		}
+
+		if query.Version, err = latestVersion(editionDoc.Revision(state == "")); err != nil {
+			log.Event(ctx, "failed to find latest version of edition", log.ERROR, log.Error(err), logData)
+			handleAPIErr(ctx, w, r, err, logData)
+			return
+		}
+		logData["latest_version"] = query.Version
+	} else if err = api.dataStore.Backend.CheckEditionExists(ctx, datasetID, edition, state); err != nil {
 		log.Event(ctx, "failed to find edition for list of versions", log.ERROR, log.Error(err), logData)
-		handleVersionAPIErr(ctx, err, w, logData)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
-	results, err := api.dataStore.Backend.GetVersions(ctx, datasetID, edition, state)
+	results, err := api.dataStore.Backend.GetVersions(ctx, datasetID, edition, state, query)
 	if err != nil {
 		log.Event(ctx, "failed to find any versions for dataset edition", log.ERROR, log.Error(err), logData)
-		handleVersionAPIErr(ctx, err, w, logData)
+		handleAPIErr(ctx, w, r, err, logData)
+		return
+	}
+
+	visible := results.Items[:0]
+	for _, item := range results.Items {
+		if api.isVersionVisible(&item, collectionID) {
+			visible = append(visible, item)
+		}
+	}
+	results.Items = visible
+
+	if len(results.Items) == 0 {
+		log.Event(ctx, "no versions of dataset edition are visible", log.ERROR, log.Error(errs.ErrVersionNotFound), logData)
+		handleAPIErr(ctx, w, r, errs.ErrVersionNotFound, logData)
 		return
 	}
 
@@ -79,51 +97,141 @@ func (api *FTBDatasetAPI) getVersions(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if hasInvalidState {
-		handleVersionAPIErr(ctx, err, w, logData)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
 	b, err := json.Marshal(results)
 	if err != nil {
 		log.Event(ctx, "failed to marshal list of version resources into bytes", log.ERROR, log.Error(err), logData)
-		handleVersionAPIErr(ctx, err, w, logData)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
+	var lastModified time.Time
+	for _, item := range results.Items {
+		lastModified = latest(lastModified, item.LastUpdated)
+	}
+
 	setJSONContentType(w)
-	_, err = w.Write(b)
-	if err != nil {
+	if err = writeBody(w, r, b, lastModified); err != nil {
 		log.Event(ctx, "error writing bytes to response", log.ERROR, log.Error(err), logData)
-		handleVersionAPIErr(ctx, err, w, logData)
+		handleAPIErr(ctx, w, r, err, logData)
 	}
 	log.Event(ctx, "getVersions endpoint: request successful", log.INFO, logData)
 }
 
+// getVersionsQuery reads the filters and sort of a list of versions from the query parameters of the request,
+// along with whether only the latest version of the edition is requested. Release dates are either RFC 3339
+// timestamps or dates, where release_date_to includes the whole of its date.
+func getVersionsQuery(r *http.Request, logData log.Data) (*models.VersionsQuery, bool, error) {
+	parameters := r.URL.Query()
+	query := &models.VersionsQuery{
+		Sort:  parameters.Get("sort"),
+		State: parameters.Get("state"),
+	}
+
+	if query.State != "" {
+		logData["state_filter"] = query.State
+		if err := models.CheckState("version", query.State); err != nil {
+			return nil, false, errs.ErrInvalidQueryParameter
+		}
+	}
+
+	if query.Sort != "" {
+		logData["sort"] = query.Sort
+		switch query.Sort {
+		case models.VersionSortReleaseDate, models.VersionSortReleaseDateDesc, models.VersionSortVersion, models.VersionSortVersionDesc:
+		default:
+			return nil, false, errs.ErrInvalidQueryParameter
+		}
+	}
+
+	var err error
+	if from := parameters.Get("release_date_from"); from != "" {
+		logData["release_date_from"] = from
+		if query.ReleaseDateFrom, err = parseReleaseDate(from, false); err != nil {
+			return nil, false, errs.ErrInvalidQueryParameter
+		}
+	}
+
+	if to := parameters.Get("release_date_to"); to != "" {
+		logData["release_date_to"] = to
+		if query.ReleaseDateTo, err = parseReleaseDate(to, true); err != nil {
+			return nil, false, errs.ErrInvalidQueryParameter
+		}
+	}
+
+	var latestOnly bool
+	if latestParameter := parameters.Get("latest"); latestParameter != "" {
+		logData["latest"] = latestParameter
+		if latestOnly, err = strconv.ParseBool(latestParameter); err != nil {
+			return nil, false, errs.ErrInvalidQueryParameter
+		}
+	}
+
+	return query, latestOnly, nil
+}
+
+// parseReleaseDate converts a release date parameter into the layout release dates are stored in. A date without
+// a time is the start of the day, or with endOfDay its last millisecond.
+func parseReleaseDate(parameter string, endOfDay bool) (string, error) {
+	if t, err := time.Parse(models.ReleaseDateDayLayout, parameter); err == nil && endOfDay {
+		return t.Add(24*time.Hour - time.Millisecond).Format(models.ReleaseDateLayout), nil
+	}
+
+	return models.NormaliseReleaseDate(parameter)
+}
+
+// latestVersion returns the number of the version linked as the latest of an edition
+func latestVersion(edition *models.Edition) (int, error) {
+	if edition == nil || edition.Links == nil || edition.Links.LatestVersion == nil {
+		return 0, errs.ErrVersionNotFound
+	}
+
+	version, err := strconv.Atoi(edition.Links.LatestVersion.ID)
+	if err != nil || version < 1 {
+		return 0, errs.ErrVersionNotFound
+	}
+
+	return version, nil
+}
+
 func (api *FTBDatasetAPI) getVersion(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	vars := mux.Vars(r)
+	vars := routeVars(r)
 	datasetID := vars["dataset_id"]
 	edition := vars["edition"]
 	version := vars["version"]
 	logData := log.Data{"dataset_id": datasetID, "edition": edition, "version": version}
 
-	var state string
-	if err := api.dataStore.Backend.CheckDatasetExists(datasetID, state); err != nil {
+	collectionID := api.previewCollectionID(w, r)
+	state := api.versionState(collectionID)
+	logData["collection_id"] = collectionID
+
+	if err := api.dataStore.Backend.CheckDatasetExists(ctx, datasetID, state); err != nil {
 		log.Event(ctx, "failed to find dataset", log.ERROR, log.Error(err), logData)
-		handleVersionAPIErr(ctx, err, w, logData)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
-	if err := api.dataStore.Backend.CheckEditionExists(datasetID, edition, state); err != nil {
+	if err := api.dataStore.Backend.CheckEditionExists(ctx, datasetID, edition, state); err != nil {
 		log.Event(ctx, "failed to find edition for dataset", log.ERROR, log.Error(err), logData)
-		handleVersionAPIErr(ctx, err, w, logData)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
-	results, err := api.dataStore.Backend.GetVersion(datasetID, edition, version, state)
+	results, err := api.dataStore.Backend.GetVersion(ctx, datasetID, edition, version, state)
 	if err != nil {
 		log.Event(ctx, "failed to find version for dataset edition", log.ERROR, log.Error(err), logData)
-		handleVersionAPIErr(ctx, err, w, logData)
+		handleAPIErr(ctx, w, r, err, logData)
+		return
+	}
+
+	if !api.isVersionVisible(results, collectionID) {
+		logData["state"] = results.State
+		log.Event(ctx, "version of dataset edition is not visible", log.ERROR, log.Error(errs.ErrVersionNotFound), logData)
+		handleAPIErr(ctx, w, r, errs.ErrVersionNotFound, logData)
 		return
 	}
 
@@ -131,48 +239,256 @@ func (api *FTBDatasetAPI) getVersion(w http.ResponseWriter, r *http.Request) {
 
 	if err = models.CheckState("version", results.State); err != nil {
 		log.Event(ctx, "unpublished version has an invalid state", log.ERROR, log.Error(err), log.Data{"state": results.State})
-		handleVersionAPIErr(ctx, errs.ErrResourceState, w, logData)
+		handleAPIErr(ctx, w, r, errs.ErrResourceState, logData)
 		return
 	}
 
 	b, err := json.Marshal(results)
 	if err != nil {
 		log.Event(ctx, "failed to marshal version resource into bytes", log.ERROR, log.Error(err), logData)
-		handleVersionAPIErr(ctx, err, w, logData)
+		handleAPIErr(ctx, w, r, err, logData)
 		return
 	}
 
 	setJSONContentType(w)
-	_, err = w.Write(b)
-	if err != nil {
+	if err = writeBody(w, r, b, results.LastUpdated); err != nil {
 		log.Event(ctx, "failed writing bytes to response", log.ERROR, log.Error(err), logData)
-		handleVersionAPIErr(ctx, err, w, logData)
+		handleAPIErr(ctx, w, r, err, logData)
 	}
 	log.Event(ctx, "getVersion endpoint: request successful", log.INFO, logData)
 }
 
-func handleVersionAPIErr(ctx context.Context, err error, w http.ResponseWriter, data log.Data) {
-	var status int
-	switch {
-	case notFound[err]:
-		status = http.StatusNotFound
-	case badRequest[err]:
-		status = http.StatusBadRequest
-	case internalServerErrWithMessage[err]:
-		status = http.StatusInternalServerError
-	case strings.HasPrefix(err.Error(), "missing mandatory fields:"):
-		status = http.StatusBadRequest
-	case strings.HasPrefix(err.Error(), "invalid fields:"):
-		status = http.StatusBadRequest
-	default:
-		err = errs.ErrInternalServer
-		status = http.StatusInternalServerError
-	}
-
-	if data == nil {
-		data = log.Data{}
-	}
-
-	log.Event(ctx, "request unsuccessful", log.ERROR, log.Error(err), data)
-	http.Error(w, err.Error(), status)
+func (api *FTBDatasetAPI) putVersion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+	versionDetails := VersionDetails{
+		datasetID: vars["dataset_id"],
+		edition:   vars["edition"],
+		version:   vars["version"],
+	}
+	logData := log.Data{"dataset_id": versionDetails.datasetID, "edition": versionDetails.edition, "version": versionDetails.version}
+
+	versionUpdate, err := models.CreateVersion(r.Body)
+	if err != nil {
+		log.Event(ctx, "putVersion endpoint: failed to model version resource based on request", log.ERROR, log.Error(err), logData)
+		handleAPIErr(ctx, w, r, err, logData)
+		return
+	}
+
+	// Release dates are stored in a single layout so that they can be filtered and sorted
+	if versionUpdate.ReleaseDate != "" {
+		logData["release_date"] = versionUpdate.ReleaseDate
+		if versionUpdate.ReleaseDate, err = models.NormaliseReleaseDate(versionUpdate.ReleaseDate); err != nil {
+			log.Event(ctx, "putVersion endpoint: release date is not a timestamp or date", log.ERROR, log.Error(err), logData)
+			handleAPIErr(ctx, w, r, models.ErrVersionReleaseDateInvalid, logData)
+			return
+		}
+	}
+
+	currentVersion, err := api.validateVersionUpdate(ctx, versionDetails, versionUpdate, logData)
+	if err != nil {
+		handleAPIErr(ctx, w, r, err, logData)
+		return
+	}
+
+	// The edition and dataset are updated before the state of the version, so that should either update fail the
+	// version keeps its previous state and the request can be retried
+	switch versionUpdate.State {
+	case models.EditionConfirmedState:
+		err = api.confirmEdition(ctx, versionDetails, currentVersion, logData)
+	case models.PublishedState:
+		err = api.publishVersion(ctx, versionDetails, currentVersion, logData)
+	}
+	if err != nil {
+		handleAPIErr(ctx, w, r, err, logData)
+		return
+	}
+
+	if err = api.dataStore.Backend.UpdateVersion(ctx, currentVersion.ID, versionUpdate); err != nil {
+		log.Event(ctx, "putVersion endpoint: failed to update version document", log.ERROR, log.Error(err), logData)
+		handleAPIErr(ctx, w, r, err, logData)
+		return
+	}
+
+	setJSONContentType(w)
+	w.WriteHeader(http.StatusOK)
+	log.Event(ctx, "putVersion endpoint: request successful", log.INFO, logData)
+}
+
+// validateVersionUpdate checks the requested change is allowed for the current state of the version, returning the
+// version as it is before the update
+func (api *FTBDatasetAPI) validateVersionUpdate(ctx context.Context, details VersionDetails, versionUpdate *models.Version, logData log.Data) (*models.Version, error) {
+	if err := api.dataStore.Backend.CheckDatasetExists(ctx, details.datasetID, ""); err != nil {
+		log.Event(ctx, "validateVersionUpdate: failed to find dataset", log.ERROR, log.Error(err), logData)
+		return nil, err
+	}
+
+	if err := api.dataStore.Backend.CheckEditionExists(ctx, details.datasetID, details.edition, ""); err != nil {
+		log.Event(ctx, "validateVersionUpdate: failed to find edition of dataset", log.ERROR, log.Error(err), logData)
+		return nil, err
+	}
+
+	currentVersion, err := api.dataStore.Backend.GetVersion(ctx, details.datasetID, details.edition, details.version, "")
+	if err != nil {
+		log.Event(ctx, "validateVersionUpdate: datastore.GetVersion returned an error", log.ERROR, log.Error(err), logData)
+		return nil, err
+	}
+
+	logData["current_state"] = currentVersion.State
+	logData["requested_state"] = versionUpdate.State
+
+	if err = models.ValidateVersionStateTransition(currentVersion.State, versionUpdate.State); err != nil {
+		log.Event(ctx, "validateVersionUpdate: requested state transition is not allowed", log.ERROR, log.Error(err), logData)
+		return nil, err
+	}
+
+	// The full set of validation only applies once a version has been attached to an edition
+	combinedVersion := populateNewVersionDoc(currentVersion, versionUpdate)
+	if models.CheckState("version", combinedVersion.State) == nil {
+		if err = models.ValidateVersion(combinedVersion); err != nil {
+			log.Event(ctx, "validateVersionUpdate: failed validation check for version update", log.ERROR, log.Error(err), logData)
+			return nil, err
+		}
+	}
+
+	return currentVersion, nil
+}
+
+// confirmEdition moves the latest version link of the edition on to a newly confirmed version
+func (api *FTBDatasetAPI) confirmEdition(ctx context.Context, details VersionDetails, version *models.Version, logData log.Data) error {
+	editionDoc, err := api.dataStore.Backend.GetEdition(ctx, details.datasetID, details.edition, "")
+	if err != nil {
+		log.Event(ctx, "confirmEdition: failed to find edition of dataset", log.ERROR, log.Error(err), logData)
+		return err
+	}
+
+	if editionDoc.Next == nil || editionDoc.Next.Links == nil || editionDoc.Next.Links.LatestVersion == nil {
+		log.Event(ctx, "confirmEdition: edition is missing its latest version link", log.ERROR, log.Error(models.ErrEditionLinksInvalid), logData)
+		return models.ErrEditionLinksInvalid
+	}
+
+	latestVersion, err := strconv.Atoi(editionDoc.Next.Links.LatestVersion.ID)
+	if err != nil {
+		log.Event(ctx, "confirmEdition: failed to convert latest version id of edition", log.ERROR, log.Error(err), logData)
+		return err
+	}
+
+	// UpdateLinks moves the link on by a single version, so only the version following the latest is linked to
+	if latestVersion != version.Version-1 {
+		log.Event(ctx, "confirmEdition: edition latest version link left unchanged", log.INFO, logData)
+		return nil
+	}
+
+	if err = editionDoc.UpdateLinks(ctx, api.host); err != nil {
+		log.Event(ctx, "confirmEdition: failed to update edition links", log.ERROR, log.Error(err), logData)
+		return err
+	}
+
+	editionDoc.Next.State = models.EditionConfirmedState
+
+	if err = api.dataStore.Backend.UpsertEdition(ctx, details.datasetID, details.edition, editionDoc); err != nil {
+		log.Event(ctx, "confirmEdition: failed to update edition document", log.ERROR, log.Error(err), logData)
+		return err
+	}
+
+	return nil
+}
+
+// publishVersion copies the next documents of the edition and dataset into current, so that the published
+// version becomes visible to the public
+func (api *FTBDatasetAPI) publishVersion(ctx context.Context, details VersionDetails, version *models.Version, logData log.Data) error {
+	versionLink := &models.LinkObject{
+		ID:   strconv.Itoa(version.Version),
+		HRef: fmt.Sprintf("%s/datasets/%s/editions/%s/versions/%d", api.host, details.datasetID, details.edition, version.Version),
+	}
+
+	editionDoc, err := api.dataStore.Backend.GetEdition(ctx, details.datasetID, details.edition, "")
+	if err != nil {
+		log.Event(ctx, "publishVersion: failed to find edition of dataset", log.ERROR, log.Error(err), logData)
+		return err
+	}
+
+	if err = editionDoc.PublishLinks(ctx, api.host, versionLink); err != nil {
+		log.Event(ctx, "publishVersion: failed to update edition links", log.ERROR, log.Error(err), logData)
+		return err
+	}
+
+	editionDoc.Next.State = models.PublishedState
+	editionDoc.Current = editionDoc.Next
+
+	if err = api.dataStore.Backend.UpsertEdition(ctx, details.datasetID, details.edition, editionDoc); err != nil {
+		log.Event(ctx, "publishVersion: failed to update edition document", log.ERROR, log.Error(err), logData)
+		return err
+	}
+
+	datasetDoc, err := api.dataStore.Backend.GetDataset(ctx, details.datasetID)
+	if err != nil {
+		log.Event(ctx, "publishVersion: failed to find dataset", log.ERROR, log.Error(err), logData)
+		return err
+	}
+
+	if datasetDoc.Next == nil {
+		datasetDoc.Next = datasetDoc.Current
+	}
+
+	if datasetDoc.Next.Links == nil {
+		datasetDoc.Next.Links = &models.DatasetLinks{}
+	}
+
+	datasetDoc.Next.CollectionID = ""
+	datasetDoc.Next.Links.LatestVersion = editionDoc.Next.Links.LatestVersion
+	datasetDoc.Next.State = models.PublishedState
+	datasetDoc.Current = datasetDoc.Next
+
+	if err = api.dataStore.Backend.UpsertDataset(ctx, details.datasetID, datasetDoc); err != nil {
+		log.Event(ctx, "publishVersion: failed to update dataset document", log.ERROR, log.Error(err), logData)
+		return err
+	}
+
+	log.Event(ctx, "publishVersion: version published", log.INFO, logData)
+	return nil
+}
+
+// populateNewVersionDoc merges the requested changes over the current version so the result can be validated
+func populateNewVersionDoc(currentVersion *models.Version, versionUpdate *models.Version) *models.Version {
+	combinedVersion := *currentVersion
+
+	if versionUpdate.Alerts != nil {
+		combinedVersion.Alerts = versionUpdate.Alerts
+	}
+
+	if versionUpdate.CollectionID != "" {
+		combinedVersion.CollectionID = versionUpdate.CollectionID
+	}
+
+	if versionUpdate.Downloads != nil {
+		combinedVersion.Downloads = versionUpdate.Downloads
+	}
+
+	if versionUpdate.LatestChanges != nil {
+		combinedVersion.LatestChanges = versionUpdate.LatestChanges
+	}
+
+	if versionUpdate.ReleaseDate != "" {
+		combinedVersion.ReleaseDate = versionUpdate.ReleaseDate
+	}
+
+	if versionUpdate.State != "" {
+		combinedVersion.State = versionUpdate.State
+	}
+
+	if versionUpdate.Temporal != nil {
+		combinedVersion.Temporal = versionUpdate.Temporal
+	}
+
+	if versionUpdate.UsageNotes != nil {
+		combinedVersion.UsageNotes = versionUpdate.UsageNotes
+	}
+
+	// A published version no longer belongs to a collection
+	if combinedVersion.State == models.PublishedState {
+		combinedVersion.CollectionID = ""
+	}
+
+	return &combinedVersion
 }
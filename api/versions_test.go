@@ -0,0 +1,267 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	storetest "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store/datastoretest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetVersionsQuery(t *testing.T) {
+
+	Convey("Given an edition whose published revision links to version 1 and next revision to version 2", t, func() {
+		mockedDataStore := &storetest.StorerMock{
+			CheckDatasetExistsFunc: func(ctx context.Context, ID, state string) error {
+				return nil
+			},
+			CheckEditionExistsFunc: func(ctx context.Context, ID, editionID, state string) error {
+				return nil
+			},
+			GetEditionFunc: func(ctx context.Context, ID, editionID, state string) (*models.EditionUpdate, error) {
+				return &models.EditionUpdate{
+					Current: &models.Edition{Links: &models.EditionUpdateLinks{LatestVersion: &models.LinkObject{ID: "1"}}},
+					Next:    &models.Edition{Links: &models.EditionUpdateLinks{LatestVersion: &models.LinkObject{ID: "2"}}},
+				}, nil
+			},
+			GetVersionsFunc: func(ctx context.Context, datasetID, editionID, state string, query *models.VersionsQuery) (*models.VersionResults, error) {
+				return &models.VersionResults{Items: []models.Version{{Version: 1, State: models.PublishedState}}}, nil
+			},
+		}
+
+		get := func(api *FTBDatasetAPI, target string) int {
+			r := httptest.NewRequest("GET", "http://localhost:10400"+target, nil)
+			if api.enablePrivateEndpoints {
+				r.Header.Set("Authorization", "Bearer "+testServiceAuthToken)
+			}
+			w := httptest.NewRecorder()
+			api.Router.ServeHTTP(w, r)
+			return w.Code
+		}
+
+		Convey("When the versions are filtered and sorted", func() {
+			api := newPublicAPI(mockedDataStore)
+			code := get(api, "/datasets/People/editions/2011/versions?state=published&sort=-version&release_date_from=2013-01-01&release_date_to=2013-01-30")
+
+			Convey("Then the query is passed to the store, with dates converted to release date bounds", func() {
+				So(code, ShouldEqual, http.StatusOK)
+				So(mockedDataStore.GetVersionsCalls(), ShouldHaveLength, 1)
+				So(*mockedDataStore.GetVersionsCalls()[0].Query, ShouldResemble, models.VersionsQuery{
+					ReleaseDateFrom: "2013-01-01T00:00:00.000Z",
+					ReleaseDateTo:   "2013-01-30T23:59:59.999Z",
+					Sort:            models.VersionSortVersionDesc,
+					State:           models.PublishedState,
+				})
+			})
+		})
+
+		Convey("When a release date is a timestamp", func() {
+			api := newPublicAPI(mockedDataStore)
+			code := get(api, "/datasets/People/editions/2011/versions?release_date_from=2013-01-30T10:30:00%2B01:00")
+
+			Convey("Then it is converted to UTC", func() {
+				So(code, ShouldEqual, http.StatusOK)
+				So(mockedDataStore.GetVersionsCalls()[0].Query.ReleaseDateFrom, ShouldEqual, "2013-01-30T09:30:00.000Z")
+			})
+		})
+
+		Convey("When only the latest version is requested in public mode", func() {
+			api := newPublicAPI(mockedDataStore)
+			code := get(api, "/datasets/People/editions/2011/versions?latest=true")
+
+			Convey("Then the version linked from the published revision of the edition is requested", func() {
+				So(code, ShouldEqual, http.StatusOK)
+				So(mockedDataStore.GetEditionCalls()[0].State, ShouldEqual, models.PublishedState)
+				So(mockedDataStore.GetVersionsCalls()[0].Query.Version, ShouldEqual, 1)
+			})
+		})
+
+		Convey("When only the latest version is requested in private mode", func() {
+			api := newPrivateAPI(mockedDataStore)
+			code := get(api, "/datasets/People/editions/2011/versions?latest=true")
+
+			Convey("Then the version linked from the next revision of the edition is requested", func() {
+				So(code, ShouldEqual, http.StatusOK)
+				So(mockedDataStore.GetVersionsCalls()[0].Query.Version, ShouldEqual, 2)
+			})
+		})
+
+		Convey("When the edition does not link to a latest version", func() {
+			mockedDataStore.GetEditionFunc = func(ctx context.Context, ID, editionID, state string) (*models.EditionUpdate, error) {
+				return &models.EditionUpdate{Current: &models.Edition{Links: &models.EditionUpdateLinks{}}}, nil
+			}
+			code := get(newPublicAPI(mockedDataStore), "/datasets/People/editions/2011/versions?latest=true")
+
+			Convey("Then version not found is returned", func() {
+				So(code, ShouldEqual, http.StatusNotFound)
+				So(mockedDataStore.GetVersionsCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When the query parameters are invalid", func() {
+			api := newPublicAPI(mockedDataStore)
+			for _, parameters := range []string{
+				"state=created",
+				"state=unknown",
+				"sort=edition",
+				"release_date_from=yesterday",
+				"release_date_to=2013-13-01",
+				"latest=yes",
+			} {
+				So(get(api, "/datasets/People/editions/2011/versions?"+parameters), ShouldEqual, http.StatusBadRequest)
+			}
+
+			Convey("Then the store is not called", func() {
+				So(mockedDataStore.GetVersionsCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+}
+
+func TestGetEditionsQuery(t *testing.T) {
+
+	Convey("Given a dataset with editions", t, func() {
+		mockedDataStore := &storetest.StorerMock{
+			CheckDatasetExistsFunc: func(ctx context.Context, ID, state string) error {
+				return nil
+			},
+			GetEditionsFunc: func(ctx context.Context, ID, state string, query *models.EditionsQuery) (*models.EditionUpdateResults, error) {
+				return nil, errs.ErrEditionNotFound
+			},
+		}
+		api := newPublicAPI(mockedDataStore)
+
+		Convey("When the editions are filtered by state", func() {
+			r := httptest.NewRequest("GET", "http://localhost:10400/datasets/People/editions?state=created", nil)
+			w := httptest.NewRecorder()
+			api.Router.ServeHTTP(w, r)
+
+			Convey("Then the filter is passed to the store", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+				So(mockedDataStore.GetEditionsCalls()[0].Query.State, ShouldEqual, models.CreatedState)
+			})
+		})
+
+		Convey("When the state filter is not a state", func() {
+			r := httptest.NewRequest("GET", "http://localhost:10400/datasets/People/editions?state=unknown", nil)
+			w := httptest.NewRecorder()
+			api.Router.ServeHTTP(w, r)
+
+			Convey("Then the request is rejected", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(mockedDataStore.GetEditionsCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+}
+
+func TestPutVersion(t *testing.T) {
+
+	Convey("Given a version associated with a collection", t, func() {
+		mockedDataStore := &storetest.StorerMock{
+			CheckDatasetExistsFunc: func(ctx context.Context, ID, state string) error {
+				return nil
+			},
+			CheckEditionExistsFunc: func(ctx context.Context, ID, editionID, state string) error {
+				return nil
+			},
+			GetVersionFunc: func(ctx context.Context, datasetID, editionID, version, state string) (*models.Version, error) {
+				return &models.Version{
+					ID:           "instance-1",
+					CollectionID: "collection-1",
+					ReleaseDate:  "2013-01-30T09:30:00.000Z",
+					State:        models.AssociatedState,
+					Version:      1,
+				}, nil
+			},
+			GetEditionFunc: func(ctx context.Context, ID, editionID, state string) (*models.EditionUpdate, error) {
+				return &models.EditionUpdate{
+					Next: &models.Edition{Links: &models.EditionUpdateLinks{LatestVersion: &models.LinkObject{ID: "1"}}},
+				}, nil
+			},
+			UpsertEditionFunc: func(ctx context.Context, datasetID, edition string, editionDoc *models.EditionUpdate) error {
+				return nil
+			},
+			GetDatasetFunc: func(ctx context.Context, ID string) (*models.DatasetUpdate, error) {
+				return &models.DatasetUpdate{Next: &models.Dataset{State: models.AssociatedState}}, nil
+			},
+			UpsertDatasetFunc: func(ctx context.Context, ID string, datasetDoc *models.DatasetUpdate) error {
+				return nil
+			},
+			UpdateVersionFunc: func(ctx context.Context, ID string, version *models.Version) error {
+				return nil
+			},
+		}
+
+		put := func(body string) int {
+			r := httptest.NewRequest("PUT", "http://localhost:10400/datasets/People/editions/2011/versions/1", strings.NewReader(body))
+			r.Header.Set("Authorization", "Bearer "+testServiceAuthToken)
+			w := httptest.NewRecorder()
+			newPrivateAPI(mockedDataStore).Router.ServeHTTP(w, r)
+			return w.Code
+		}
+		publish := func() int {
+			return put(`{"state":"published"}`)
+		}
+
+		Convey("When it is published", func() {
+			code := publish()
+
+			Convey("Then the edition and dataset are published along with the version", func() {
+				So(code, ShouldEqual, http.StatusOK)
+				So(mockedDataStore.UpsertEditionCalls(), ShouldHaveLength, 1)
+				So(mockedDataStore.UpsertDatasetCalls(), ShouldHaveLength, 1)
+				So(mockedDataStore.UpdateVersionCalls(), ShouldHaveLength, 1)
+				So(mockedDataStore.UpdateVersionCalls()[0].Version.State, ShouldEqual, models.PublishedState)
+			})
+		})
+
+		Convey("When the dataset cannot be published", func() {
+			mockedDataStore.UpsertDatasetFunc = func(ctx context.Context, ID string, datasetDoc *models.DatasetUpdate) error {
+				return errors.New("connection refused")
+			}
+			code := publish()
+
+			Convey("Then the version keeps its state, so that the request can be retried", func() {
+				So(code, ShouldEqual, http.StatusInternalServerError)
+				So(mockedDataStore.UpdateVersionCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When its release date is changed", func() {
+			code := put(`{"release_date":"2013-01-31T10:00:00+01:00"}`)
+
+			Convey("Then the release date is stored in UTC in the layout release dates are compared in", func() {
+				So(code, ShouldEqual, http.StatusOK)
+				So(mockedDataStore.UpdateVersionCalls()[0].Version.ReleaseDate, ShouldEqual, "2013-01-31T09:00:00.000Z")
+			})
+		})
+
+		Convey("When its release date is not a timestamp or date", func() {
+			code := put(`{"release_date":"31/01/2013"}`)
+
+			Convey("Then the request is rejected", func() {
+				So(code, ShouldEqual, http.StatusBadRequest)
+				So(mockedDataStore.UpdateVersionCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When the edition has no latest version link", func() {
+			mockedDataStore.GetEditionFunc = func(ctx context.Context, ID, editionID, state string) (*models.EditionUpdate, error) {
+				return &models.EditionUpdate{Next: &models.Edition{}}, nil
+			}
+			code := publish()
+
+			Convey("Then the version keeps its state", func() {
+				So(code, ShouldNotEqual, http.StatusOK)
+				So(mockedDataStore.UpdateVersionCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+}
@@ -23,15 +23,26 @@ var (
 	ErrIndexOutOfRange                   = errors.New("index out of range")
 	ErrInstanceNotFound                  = errors.New("instance not found")
 	ErrInternalServer                    = errors.New("internal error")
+	ErrInvalidQueryParameter             = errors.New("invalid query parameter")
 	ErrInsertedObservationsInvalidSyntax = errors.New("inserted observation request parameter not an integer")
 	ErrMetadataVersionNotFound           = errors.New("version not found")
 	ErrMissingJobProperties              = errors.New("missing job properties")
 	ErrMissingParameters                 = errors.New("missing properties in JSON")
 	ErrMissingVersionHeadersOrDimensions = errors.New("missing headers or dimensions or both from version doc")
 	ErrNoAuthHeader                      = errors.New("no authentication header provided")
+	ErrNotAcceptable                     = errors.New("none of the content types in the accept header are supported")
+	ErrObservationDimensionNotFound      = errors.New("a dimension in the query does not exist for this version of the dataset")
+	ErrObservationDimensionsMissing      = errors.New("at least one dimension must be provided in the query")
+	ErrObservationOptionNotFound         = errors.New("a dimension option in the query does not exist for this version of the dataset")
 	ErrObservationsNotFound              = errors.New("no observations found")
 	ErrResourcePublished                 = errors.New("unable to update resource as it has been published")
 	ErrResourceState                     = errors.New("incorrect resource state")
+	ErrRequestTimeout                    = errors.New("the request could not be completed in time")
+	ErrSearchQueryMissing                = errors.New("a search query must be provided using the q parameter")
+	ErrSearchTypeInvalid                 = errors.New("the type of a search must be ftb-blob or ftb-table")
+	ErrTableBlobInvalid                  = errors.New("tables can only be created from a version of an ftb-blob dataset")
+	ErrTableDimensionNotFound            = errors.New("a dimension of the table does not exist for the ftb-blob version")
+	ErrTooManyQueryParameters            = errors.New("too many query parameters have been provided")
 	ErrTooManyWildcards                  = errors.New("only one wildcard (*) is allowed as a value in selected query parameters")
 	ErrUnableToParseJSON                 = errors.New("failed to parse json body")
 	ErrUnableToReadMessage               = errors.New("failed to read message body")
@@ -54,14 +65,28 @@ var (
 		ErrDimensionNodeNotFound:   true,
 		ErrDimensionOptionNotFound: true,
 		ErrEditionNotFound:         true,
+		ErrEditionsNotFound:        true,
 		ErrInstanceNotFound:        true,
+		ErrMetadataVersionNotFound: true,
+		ErrObservationsNotFound:    true,
 		ErrVersionNotFound:         true,
 	}
 
 	BadRequestMap = map[error]bool{
+		ErrAddUpdateDatasetBadRequest:        true,
 		ErrInsertedObservationsInvalidSyntax: true,
+		ErrInvalidQueryParameter:             true,
 		ErrMissingJobProperties:              true,
 		ErrMissingParameters:                 true,
+		ErrObservationDimensionNotFound:      true,
+		ErrObservationDimensionsMissing:      true,
+		ErrObservationOptionNotFound:         true,
+		ErrSearchQueryMissing:                true,
+		ErrSearchTypeInvalid:                 true,
+		ErrTableBlobInvalid:                  true,
+		ErrTableDimensionNotFound:            true,
+		ErrTooManyQueryParameters:            true,
+		ErrTooManyWildcards:                  true,
 		ErrUnableToParseJSON:                 true,
 		ErrUnableToReadMessage:               true,
 	}
@@ -70,13 +95,30 @@ var (
 		ErrConflictUpdatingInstance: true,
 	}
 
+	NotAcceptableMap = map[error]bool{
+		ErrNotAcceptable: true,
+	}
+
+	GatewayTimeoutMap = map[error]bool{
+		ErrRequestTimeout: true,
+	}
+
+	UnauthorisedMap = map[error]bool{
+		ErrNoAuthHeader: true,
+		ErrUnauthorised: true,
+	}
+
 	ForbiddenMap = map[error]bool{
+		ErrAddDatasetAlreadyExists:         true,
+		ErrDeletePublishedDatasetForbidden: true,
+
 		ErrExpectedResourceStateOfCreated:          true,
 		ErrExpectedResourceStateOfSubmitted:        true,
 		ErrExpectedResourceStateOfCompleted:        true,
 		ErrExpectedResourceStateOfEditionConfirmed: true,
 		ErrExpectedResourceStateOfAssociated:       true,
 
-		ErrResourcePublished: true,
+		ErrIncorrectStateToDetach: true,
+		ErrResourcePublished:      true,
 	}
 )
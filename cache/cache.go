@@ -0,0 +1,157 @@
+// Package cache provides a read-through cache of a store.Storer. Published versions, along with their dimensions
+// and options, never change once published so they are held for a long time, while the dataset and edition
+// documents that can still be updated are held only briefly.
+package cache
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store"
+	lru "github.com/hashicorp/golang-lru"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// check that Store satisfies the store.Storer interface
+var _ store.Storer = (*Store)(nil)
+
+// Store caches the results of a store.Storer in a bounded LRU, each entry expiring after a TTL. Only successful
+// lookups are cached, and any write through the store empties the cache: writes are rare next to reads, so this
+// is simpler than tracking which entries each write affects. Writes made by other instances of the API are only
+// seen once the entries they affect expire.
+type Store struct {
+	storer       store.Storer
+	entries      *lru.Cache
+	publishedTTL time.Duration
+	mutableTTL   time.Duration
+
+	mutex      sync.Mutex
+	generation uint64
+	stats      map[string]*MethodStats
+}
+
+// entry holds the bson form of a cached document, so that each caller is given its own copy to modify
+type entry struct {
+	doc     []byte
+	expires time.Time
+}
+
+// Stats holds the number of entries in the cache and the hits and misses of each cached method
+type Stats struct {
+	Entries int
+	Methods map[string]MethodStats
+}
+
+// MethodStats holds the hits and misses of a cached method
+type MethodStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// New returns a Store caching up to size results of storer. Published documents are held for publishedTTL and
+// those that can change for mutableTTL.
+func New(storer store.Storer, size int, publishedTTL, mutableTTL time.Duration) (*Store, error) {
+	entries, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Store{
+		storer:       storer,
+		entries:      entries,
+		publishedTTL: publishedTTL,
+		mutableTTL:   mutableTTL,
+		stats:        make(map[string]*MethodStats),
+	}, nil
+}
+
+// Stats returns the number of entries in the cache and the hits and misses of each cached method so far
+func (s *Store) Stats() Stats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stats := Stats{Entries: s.entries.Len(), Methods: make(map[string]MethodStats, len(s.stats))}
+	for method, methodStats := range s.stats {
+		stats.Methods[method] = *methodStats
+	}
+
+	return stats
+}
+
+// key returns the key of the result of calling method with args
+func key(method string, args ...string) string {
+	return method + "\x00" + strings.Join(args, "\x00")
+}
+
+// get reads the cached result of a call to method into doc, which is nil for methods that only return an error,
+// reporting whether the result was found. On a miss, the generation returned is passed to set along with the
+// result read from the store.
+func (s *Store) get(method, key string, doc interface{}) (bool, uint64) {
+	found := s.lookup(key, doc)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	methodStats, ok := s.stats[method]
+	if !ok {
+		methodStats = &MethodStats{}
+		s.stats[method] = methodStats
+	}
+
+	if found {
+		methodStats.Hits++
+	} else {
+		methodStats.Misses++
+	}
+
+	return found, s.generation
+}
+
+func (s *Store) lookup(key string, doc interface{}) bool {
+	value, ok := s.entries.Get(key)
+	if !ok {
+		return false
+	}
+
+	cached := value.(entry)
+	if time.Now().After(cached.expires) {
+		s.entries.Remove(key)
+		return false
+	}
+
+	if doc == nil {
+		return true
+	}
+
+	return bson.Unmarshal(cached.doc, doc) == nil
+}
+
+// set caches doc under key for ttl, unless the cache has been purged since the generation in which doc was read
+// from the store, as a write may have changed it since. Documents that cannot be held in their bson form are not
+// cached.
+func (s *Store) set(key string, doc interface{}, ttl time.Duration, generation uint64) {
+	var b []byte
+	if doc != nil {
+		var err error
+		if b, err = bson.Marshal(doc); err != nil {
+			return
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if generation == s.generation {
+		s.entries.Add(key, entry{doc: b, expires: time.Now().Add(ttl)})
+	}
+}
+
+// purge empties the cache after a write
+func (s *Store) purge() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.generation++
+	s.entries.Purge()
+}
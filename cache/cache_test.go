@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	storetest "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store/datastoretest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCache(t *testing.T) {
+
+	Convey("Given a cache of a store holding a published and an unpublished version", t, func() {
+		versions := map[string]*models.Version{
+			"1": {ID: "instance-1", Version: 1, State: models.PublishedState},
+			"2": {ID: "instance-2", Version: 2, State: models.EditionConfirmedState},
+		}
+
+		mockedDataStore := &storetest.StorerMock{
+			GetVersionFunc: func(ctx context.Context, datasetID, editionID, version, state string) (*models.Version, error) {
+				versionDoc, ok := versions[version]
+				if !ok {
+					return nil, errs.ErrVersionNotFound
+				}
+				copied := *versionDoc
+				return &copied, nil
+			},
+			GetDimensionsFunc: func(ctx context.Context, datasetID, versionID string) ([]models.DimensionOption, error) {
+				return []models.DimensionOption{{Name: "SEX", Label: "Sex"}}, nil
+			},
+			GetDatasetFunc: func(ctx context.Context, ID string) (*models.DatasetUpdate, error) {
+				return &models.DatasetUpdate{ID: ID}, nil
+			},
+			UpsertDatasetFunc: func(ctx context.Context, ID string, datasetDoc *models.DatasetUpdate) error {
+				return nil
+			},
+		}
+
+		// unpublished documents expire as soon as they are cached
+		c, err := New(mockedDataStore, 100, time.Hour, 0)
+		So(err, ShouldBeNil)
+
+		Convey("When a published version is read twice", func() {
+			first, err := c.GetVersion(context.Background(), "People", "2011", "1", models.PublishedState)
+			So(err, ShouldBeNil)
+			first.Version = 99
+
+			second, err := c.GetVersion(context.Background(), "People", "2011", "1", models.PublishedState)
+			So(err, ShouldBeNil)
+
+			Convey("Then it is read from the store once, and each caller is given its own copy", func() {
+				So(mockedDataStore.GetVersionCalls(), ShouldHaveLength, 1)
+				So(second.Version, ShouldEqual, 1)
+				So(second.ID, ShouldEqual, "instance-1")
+			})
+
+			Convey("Then the hit and miss are counted", func() {
+				stats := c.Stats()
+				So(stats.Methods["GetVersion"], ShouldResemble, MethodStats{Hits: 1, Misses: 1})
+			})
+
+			Convey("Then the dimensions of the version are cached for as long as the version", func() {
+				_, err = c.GetDimensions(context.Background(), "People", "instance-1")
+				So(err, ShouldBeNil)
+				dimensions, err := c.GetDimensions(context.Background(), "People", "instance-1")
+				So(err, ShouldBeNil)
+
+				So(mockedDataStore.GetDimensionsCalls(), ShouldHaveLength, 1)
+				So(dimensions, ShouldHaveLength, 1)
+				So(dimensions[0].Name, ShouldEqual, "SEX")
+				So(dimensions[0].Label, ShouldEqual, "Sex")
+			})
+		})
+
+		Convey("When an unpublished version is read twice", func() {
+			_, err = c.GetVersion(context.Background(), "People", "2011", "2", "")
+			So(err, ShouldBeNil)
+			_, err = c.GetVersion(context.Background(), "People", "2011", "2", "")
+			So(err, ShouldBeNil)
+
+			Convey("Then it has expired by the second read", func() {
+				So(mockedDataStore.GetVersionCalls(), ShouldHaveLength, 2)
+			})
+
+			Convey("Then its dimensions are not cached for long", func() {
+				c.GetDimensions(context.Background(), "People", "instance-2")
+				c.GetDimensions(context.Background(), "People", "instance-2")
+				So(mockedDataStore.GetDimensionsCalls(), ShouldHaveLength, 2)
+			})
+		})
+
+		Convey("When a version that does not exist is read twice", func() {
+			_, err = c.GetVersion(context.Background(), "People", "2011", "3", "")
+			So(err, ShouldEqual, errs.ErrVersionNotFound)
+			_, err = c.GetVersion(context.Background(), "People", "2011", "3", "")
+			So(err, ShouldEqual, errs.ErrVersionNotFound)
+
+			Convey("Then the error is not cached", func() {
+				So(mockedDataStore.GetVersionCalls(), ShouldHaveLength, 2)
+			})
+		})
+
+		Convey("When a write is made through the cache", func() {
+			c.GetVersion(context.Background(), "People", "2011", "1", models.PublishedState)
+			So(c.Stats().Entries, ShouldBeGreaterThan, 0)
+			So(c.UpsertDataset(context.Background(), "People", &models.DatasetUpdate{}), ShouldBeNil)
+
+			Convey("Then the cache is emptied", func() {
+				So(c.Stats().Entries, ShouldEqual, 0)
+				c.GetVersion(context.Background(), "People", "2011", "1", models.PublishedState)
+				So(mockedDataStore.GetVersionCalls(), ShouldHaveLength, 2)
+			})
+		})
+
+		Convey("When a write is made while a document is being read from the store", func() {
+			c, err = New(mockedDataStore, 100, time.Hour, time.Hour)
+			So(err, ShouldBeNil)
+
+			mockedDataStore.GetDatasetFunc = func(ctx context.Context, ID string) (*models.DatasetUpdate, error) {
+				if len(mockedDataStore.GetDatasetCalls()) == 1 {
+					c.UpsertDataset(context.Background(), ID, &models.DatasetUpdate{})
+				}
+				return &models.DatasetUpdate{ID: ID}, nil
+			}
+
+			c.GetDataset(context.Background(), "People")
+			c.GetDataset(context.Background(), "People")
+
+			Convey("Then the document read before the write is not cached", func() {
+				So(mockedDataStore.GetDatasetCalls(), ShouldHaveLength, 2)
+			})
+		})
+	})
+
+	Convey("Given a size that is not positive", t, func() {
+		Convey("Then a cache cannot be created", func() {
+			_, err := New(&storetest.StorerMock{}, 0, time.Hour, time.Second)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
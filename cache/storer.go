@@ -0,0 +1,261 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+)
+
+// publishedVersion is the method under which the IDs of published versions are recorded, so that the dimensions
+// of a version, which are looked up by its ID alone, can be cached for as long as the version itself
+const publishedVersion = "publishedVersion"
+
+// dimensions holds the result of GetDimensions, as only documents can be held in their bson form
+type dimensions struct {
+	Items []models.DimensionOption `bson:"items"`
+}
+
+// ttl returns how long a document in state is cached for
+func (s *Store) ttl(state string) time.Duration {
+	if state == models.PublishedState {
+		return s.publishedTTL
+	}
+
+	return s.mutableTTL
+}
+
+// versionKey returns the part of a key identifying the version that dimension options are linked to
+func versionKey(version *models.Version) []string {
+	var self string
+	if version.Links != nil && version.Links.Self != nil {
+		self = version.Links.Self.HRef
+	}
+
+	return []string{version.ID, self}
+}
+
+// CheckDatasetExists checks the dataset exists, caching that it does for a short time
+func (s *Store) CheckDatasetExists(ctx context.Context, ID, state string) error {
+	k := key("CheckDatasetExists", ID, state)
+	found, generation := s.get("CheckDatasetExists", k, nil)
+	if found {
+		return nil
+	}
+
+	if err := s.storer.CheckDatasetExists(ctx, ID, state); err != nil {
+		return err
+	}
+
+	s.set(k, nil, s.mutableTTL, generation)
+	return nil
+}
+
+// CheckEditionExists checks the edition exists, caching that it does for a short time
+func (s *Store) CheckEditionExists(ctx context.Context, ID, editionID, state string) error {
+	k := key("CheckEditionExists", ID, editionID, state)
+	found, generation := s.get("CheckEditionExists", k, nil)
+	if found {
+		return nil
+	}
+
+	if err := s.storer.CheckEditionExists(ctx, ID, editionID, state); err != nil {
+		return err
+	}
+
+	s.set(k, nil, s.mutableTTL, generation)
+	return nil
+}
+
+// GetDataset returns the dataset, caching it for a short time
+func (s *Store) GetDataset(ctx context.Context, ID string) (*models.DatasetUpdate, error) {
+	k := key("GetDataset", ID)
+	var cached models.DatasetUpdate
+	found, generation := s.get("GetDataset", k, &cached)
+	if found {
+		return &cached, nil
+	}
+
+	dataset, err := s.storer.GetDataset(ctx, ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.set(k, dataset, s.mutableTTL, generation)
+	return dataset, nil
+}
+
+// GetEdition returns the edition, caching it for a short time
+func (s *Store) GetEdition(ctx context.Context, ID, editionID, state string) (*models.EditionUpdate, error) {
+	k := key("GetEdition", ID, editionID, state)
+	var cached models.EditionUpdate
+	found, generation := s.get("GetEdition", k, &cached)
+	if found {
+		return &cached, nil
+	}
+
+	edition, err := s.storer.GetEdition(ctx, ID, editionID, state)
+	if err != nil {
+		return nil, err
+	}
+
+	s.set(k, edition, s.mutableTTL, generation)
+	return edition, nil
+}
+
+// GetVersion returns the version, caching it for a long time once it has been published
+func (s *Store) GetVersion(ctx context.Context, datasetID, editionID, version, state string) (*models.Version, error) {
+	k := key("GetVersion", datasetID, editionID, version, state)
+	var cached models.Version
+	found, generation := s.get("GetVersion", k, &cached)
+	if found {
+		return &cached, nil
+	}
+
+	versionDoc, err := s.storer.GetVersion(ctx, datasetID, editionID, version, state)
+	if err != nil {
+		return nil, err
+	}
+
+	s.set(k, versionDoc, s.ttl(versionDoc.State), generation)
+	if versionDoc.State == models.PublishedState {
+		s.set(key(publishedVersion, versionDoc.ID), nil, s.publishedTTL, generation)
+	}
+
+	return versionDoc, nil
+}
+
+// GetDimensions returns the dimensions of a version, caching them for a long time when the version is known to
+// have been published
+func (s *Store) GetDimensions(ctx context.Context, datasetID, versionID string) ([]models.DimensionOption, error) {
+	k := key("GetDimensions", datasetID, versionID)
+	var cached dimensions
+	found, generation := s.get("GetDimensions", k, &cached)
+	if found {
+		return cached.Items, nil
+	}
+
+	items, err := s.storer.GetDimensions(ctx, datasetID, versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := s.mutableTTL
+	if s.lookup(key(publishedVersion, versionID), nil) {
+		ttl = s.publishedTTL
+	}
+
+	s.set(k, &dimensions{Items: items}, ttl, generation)
+	return items, nil
+}
+
+// GetDimensionOptions returns a page of the options of a dimension, caching it for a long time once the version
+// has been published
+func (s *Store) GetDimensionOptions(ctx context.Context, version *models.Version, dimension string, offset, limit int) (*models.DimensionOptionResults, error) {
+	k := key("GetDimensionOptions", append(versionKey(version), dimension, strconv.Itoa(offset), strconv.Itoa(limit))...)
+	var cached models.DimensionOptionResults
+	found, generation := s.get("GetDimensionOptions", k, &cached)
+	if found {
+		return &cached, nil
+	}
+
+	results, err := s.storer.GetDimensionOptions(ctx, version, dimension, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	s.set(k, results, s.ttl(version.State), generation)
+	return results, nil
+}
+
+// GetDimensionOptionsFromIDs returns the requested options of a dimension, caching them for a long time once the
+// version has been published
+func (s *Store) GetDimensionOptionsFromIDs(ctx context.Context, version *models.Version, dimension string, ids []string) (*models.DimensionOptionResults, error) {
+	k := key("GetDimensionOptionsFromIDs", append(append(versionKey(version), dimension), ids...)...)
+	var cached models.DimensionOptionResults
+	found, generation := s.get("GetDimensionOptionsFromIDs", k, &cached)
+	if found {
+		return &cached, nil
+	}
+
+	results, err := s.storer.GetDimensionOptionsFromIDs(ctx, version, dimension, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	s.set(k, results, s.ttl(version.State), generation)
+	return results, nil
+}
+
+// The results of the methods below are lists or documents used to import and publish versions, which are not
+// cached.
+
+func (s *Store) GetDatasets(ctx context.Context, offset, limit int, authorised bool) (*models.DatasetUpdateResults, error) {
+	return s.storer.GetDatasets(ctx, offset, limit, authorised)
+}
+
+func (s *Store) GetDimensionsFromInstance(ctx context.Context, ID string) (*models.DimensionNodeResults, error) {
+	return s.storer.GetDimensionsFromInstance(ctx, ID)
+}
+
+func (s *Store) GetEditions(ctx context.Context, ID, state string, query *models.EditionsQuery) (*models.EditionUpdateResults, error) {
+	return s.storer.GetEditions(ctx, ID, state, query)
+}
+
+func (s *Store) GetInstances(ctx context.Context, states []string, datasets []string) (*models.InstanceResults, error) {
+	return s.storer.GetInstances(ctx, states, datasets)
+}
+
+func (s *Store) GetInstance(ctx context.Context, ID string) (*models.Instance, error) {
+	return s.storer.GetInstance(ctx, ID)
+}
+
+func (s *Store) GetNextVersion(ctx context.Context, datasetID, editionID string) (int, error) {
+	return s.storer.GetNextVersion(ctx, datasetID, editionID)
+}
+
+func (s *Store) GetUniqueDimensionAndOptions(ctx context.Context, ID, dimension string) (*models.DimensionValues, error) {
+	return s.storer.GetUniqueDimensionAndOptions(ctx, ID, dimension)
+}
+
+func (s *Store) GetVersions(ctx context.Context, datasetID, editionID, state string, query *models.VersionsQuery) (*models.VersionResults, error) {
+	return s.storer.GetVersions(ctx, datasetID, editionID, state, query)
+}
+
+// Writes are passed to the store and then empty the cache.
+
+func (s *Store) AddDimensionOptions(ctx context.Context, options []*models.DimensionOption) error {
+	defer s.purge()
+	return s.storer.AddDimensionOptions(ctx, options)
+}
+
+func (s *Store) AddTableLinks(ctx context.Context, datasetID, editionID, instanceID string, links *models.TableLinks) error {
+	defer s.purge()
+	return s.storer.AddTableLinks(ctx, datasetID, editionID, instanceID, links)
+}
+
+func (s *Store) AddVersion(ctx context.Context, version *models.Version) error {
+	defer s.purge()
+	return s.storer.AddVersion(ctx, version)
+}
+
+func (s *Store) UpdateDataset(ctx context.Context, ID string, dataset *models.Dataset, currentState string) error {
+	defer s.purge()
+	return s.storer.UpdateDataset(ctx, ID, dataset, currentState)
+}
+
+func (s *Store) UpdateVersion(ctx context.Context, ID string, version *models.Version) error {
+	defer s.purge()
+	return s.storer.UpdateVersion(ctx, ID, version)
+}
+
+func (s *Store) UpsertDataset(ctx context.Context, ID string, datasetDoc *models.DatasetUpdate) error {
+	defer s.purge()
+	return s.storer.UpsertDataset(ctx, ID, datasetDoc)
+}
+
+func (s *Store) UpsertEdition(ctx context.Context, datasetID, edition string, editionDoc *models.EditionUpdate) error {
+	defer s.purge()
+	return s.storer.UpsertEdition(ctx, datasetID, edition, editionDoc)
+}
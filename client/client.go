@@ -0,0 +1,301 @@
+// Package client is a Go client for the public endpoints of the FTB dataset API. Requests are retried with an
+// exponential backoff on connection errors and server errors, and each attempt is limited by a timeout.
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+)
+
+const (
+	defaultMaxRetries    = 3
+	defaultRetryInterval = 200 * time.Millisecond
+	defaultTimeout       = 10 * time.Second
+)
+
+// Client makes requests to the FTB dataset API. The exported fields can be changed after the client is created
+// with New, but not while requests are being made.
+type Client struct {
+	// HTTPClient sends the requests to the API
+	HTTPClient *http.Client
+	// MaxRetries is the number of times a request is retried after the first attempt fails
+	MaxRetries int
+	// RetryInterval is the wait before the first retry, which doubles for every retry after it
+	RetryInterval time.Duration
+	// Timeout limits each attempt at a request, including reading the response body. Zero means no timeout.
+	Timeout time.Duration
+
+	host string
+}
+
+// New creates a client for the FTB dataset API running at host e.g. http://localhost:10400
+func New(host string) *Client {
+	return &Client{
+		HTTPClient:    &http.Client{},
+		MaxRetries:    defaultMaxRetries,
+		RetryInterval: defaultRetryInterval,
+		Timeout:       defaultTimeout,
+		host:          strings.TrimSuffix(host, "/"),
+	}
+}
+
+// ErrInvalidResponse is returned when the API responds with an unsuccessful status code
+type ErrInvalidResponse struct {
+	ActualCode int
+	URI        string
+	// Problem is the problem details in the body of the response, if it could be read
+	Problem *models.Problem
+}
+
+func (e *ErrInvalidResponse) Error() string {
+	if e.Problem != nil && e.Problem.Detail != "" {
+		return fmt.Sprintf("invalid response from ftb dataset api: %d from %s: %s", e.ActualCode, e.URI, e.Problem.Detail)
+	}
+
+	return fmt.Sprintf("invalid response from ftb dataset api: %d from %s", e.ActualCode, e.URI)
+}
+
+// Code returns the status code of the response
+func (e *ErrInvalidResponse) Code() int {
+	return e.ActualCode
+}
+
+// GetDatasets returns a page of the published datasets. A limit of zero uses the API's default limit.
+func (c *Client) GetDatasets(ctx context.Context, offset, limit int) (*models.DatasetResults, error) {
+	var results models.DatasetResults
+	if err := c.get(ctx, "/datasets", pagination(offset, limit), &results); err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
+
+// GetDataset returns the published revision of a dataset
+func (c *Client) GetDataset(ctx context.Context, datasetID string) (*models.Dataset, error) {
+	var dataset models.Dataset
+	if err := c.get(ctx, path("datasets", datasetID), nil, &dataset); err != nil {
+		return nil, err
+	}
+
+	return &dataset, nil
+}
+
+// GetEditions returns the published editions of a dataset
+func (c *Client) GetEditions(ctx context.Context, datasetID string) (*models.EditionResults, error) {
+	var results models.EditionResults
+	if err := c.get(ctx, path("datasets", datasetID, "editions"), nil, &results); err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
+
+// GetEdition returns the published revision of an edition of a dataset
+func (c *Client) GetEdition(ctx context.Context, datasetID, edition string) (*models.Edition, error) {
+	var editionDoc models.Edition
+	if err := c.get(ctx, path("datasets", datasetID, "editions", edition), nil, &editionDoc); err != nil {
+		return nil, err
+	}
+
+	return &editionDoc, nil
+}
+
+// GetVersions returns the versions of an edition of a dataset
+func (c *Client) GetVersions(ctx context.Context, datasetID, edition string) (*models.VersionResults, error) {
+	var results models.VersionResults
+	if err := c.get(ctx, path("datasets", datasetID, "editions", edition, "versions"), nil, &results); err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
+
+// GetVersion returns a version of an edition of a dataset
+func (c *Client) GetVersion(ctx context.Context, datasetID, edition, version string) (*models.Version, error) {
+	var versionDoc models.Version
+	if err := c.get(ctx, path("datasets", datasetID, "editions", edition, "versions", version), nil, &versionDoc); err != nil {
+		return nil, err
+	}
+
+	return &versionDoc, nil
+}
+
+// GetMetadata returns the metadata of a version
+func (c *Client) GetMetadata(ctx context.Context, datasetID, edition, version string) (*models.Metadata, error) {
+	var metadata models.Metadata
+	if err := c.get(ctx, path("datasets", datasetID, "editions", edition, "versions", version, "metadata"), nil, &metadata); err != nil {
+		return nil, err
+	}
+
+	return &metadata, nil
+}
+
+// GetDimensions returns the dimensions of a version
+func (c *Client) GetDimensions(ctx context.Context, datasetID, edition, version string) (*models.DatasetDimensionResults, error) {
+	var results models.DatasetDimensionResults
+	if err := c.get(ctx, path("datasets", datasetID, "editions", edition, "versions", version, "dimensions"), nil, &results); err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
+
+// GetOptions returns a page of the options of a dimension of a version. A limit of zero uses the API's default limit.
+func (c *Client) GetOptions(ctx context.Context, datasetID, edition, version, dimension string, offset, limit int) (*models.DimensionOptionResults, error) {
+	var results models.DimensionOptionResults
+	optionsPath := path("datasets", datasetID, "editions", edition, "versions", version, "dimensions", dimension, "options")
+	if err := c.get(ctx, optionsPath, pagination(offset, limit), &results); err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
+
+// GetOptionsByIDs returns the options of a dimension of a version with the given ids
+func (c *Client) GetOptionsByIDs(ctx context.Context, datasetID, edition, version, dimension string, ids []string) (*models.DimensionOptionResults, error) {
+	var results models.DimensionOptionResults
+	optionsPath := path("datasets", datasetID, "editions", edition, "versions", version, "dimensions", dimension, "options")
+	if err := c.get(ctx, optionsPath, url.Values{"id": ids}, &results); err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
+
+// GetObservations returns the observations of a version cross-tabulated by the given dimensions, each restricted
+// to a list of codes. A single dimension can be given the wildcard code "*" to return all of its codes.
+func (c *Client) GetObservations(ctx context.Context, datasetID, edition, version string, dimensions map[string][]string) (*models.ObservationResults, error) {
+	var results models.ObservationResults
+	observationsPath := path("datasets", datasetID, "editions", edition, "versions", version, "observations")
+	if err := c.get(ctx, observationsPath, url.Values(dimensions), &results); err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
+
+// Search returns a page of the published datasets matching the terms of q, ranked by relevance. The results can
+// be restricted to an ftb type and to datasets with a dimension by setting ftbType and dimension.
+func (c *Client) Search(ctx context.Context, q, ftbType, dimension string, offset, limit int) (*models.SearchResults, error) {
+	query := pagination(offset, limit)
+	query.Set("q", q)
+	if ftbType != "" {
+		query.Set("type", ftbType)
+	}
+	if dimension != "" {
+		query.Set("dimension", dimension)
+	}
+
+	var results models.SearchResults
+	if err := c.get(ctx, "/search", query, &results); err != nil {
+		return nil, err
+	}
+
+	return &results, nil
+}
+
+// get requests a path of the API and decodes the JSON response into v, retrying failed attempts
+func (c *Client) get(ctx context.Context, path string, query url.Values, v interface{}) error {
+	uri := c.host + path
+	if len(query) > 0 {
+		uri += "?" + query.Encode()
+	}
+
+	interval := c.RetryInterval
+	for attempt := 0; ; attempt++ {
+		status, b, err := c.do(ctx, uri)
+		if attempt < c.MaxRetries && ctx.Err() == nil && isRetryable(status, err) {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(interval):
+			}
+			interval *= 2
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if status != http.StatusOK {
+			return newErrInvalidResponse(status, uri, b)
+		}
+
+		return json.Unmarshal(b, v)
+	}
+}
+
+// do makes a single attempt at a request, returning the status code and body of the response
+func (c *Client) do(ctx context.Context, uri string) (int, []byte, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return resp.StatusCode, b, nil
+}
+
+// isRetryable reports whether an attempt failed in a way that a later attempt might not
+func isRetryable(status int, err error) bool {
+	return err != nil || status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+func newErrInvalidResponse(status int, uri string, b []byte) error {
+	e := &ErrInvalidResponse{ActualCode: status, URI: uri}
+
+	var problem models.Problem
+	if json.Unmarshal(b, &problem) == nil && problem.Status != 0 {
+		e.Problem = &problem
+	}
+
+	return e
+}
+
+// path joins the escaped segments of a path of the API
+func path(segments ...string) string {
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+
+	return "/" + strings.Join(segments, "/")
+}
+
+func pagination(offset, limit int) url.Values {
+	query := url.Values{}
+	if offset > 0 {
+		query.Set("offset", strconv.Itoa(offset))
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	return query
+}
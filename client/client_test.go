@@ -0,0 +1,308 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/api"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/config"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/ftb"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/memory"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/search"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/url"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// newRouter returns the router of the API, backed by an in-memory store loaded with the example fixtures
+func newRouter(t *testing.T) (*mux.Router, *memory.Store) {
+	cfg, err := config.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	memoryStore := memory.New()
+	if err = memoryStore.Load("../fixtures"); err != nil {
+		t.Fatal(err)
+	}
+
+	index := search.NewIndex(memoryStore)
+	if err = index.Build(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	dataStore := store.DataStore{Backend: memoryStore, Searcher: index}
+	ftbAPI := api.NewFTBDatasetAPI(context.Background(), *cfg, mux.NewRouter(), dataStore, ftb.NewFileClient("../fixtures/cubes"), url.NewBuilder(cfg.WebsiteURL))
+	return ftbAPI.Router, memoryStore
+}
+
+// newClient returns a client that retries without waiting
+func newClient(host string) *Client {
+	c := New(host)
+	c.RetryInterval = time.Millisecond
+	return c
+}
+
+func TestClient(t *testing.T) {
+	router, _ := newRouter(t)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ctx := context.Background()
+	c := newClient(server.URL)
+
+	Convey("Given a client for an API serving the People dataset", t, func() {
+
+		Convey("When the dataset is requested", func() {
+			dataset, err := c.GetDataset(ctx, "People")
+
+			Convey("Then the dataset is returned", func() {
+				So(err, ShouldBeNil)
+				So(dataset.ID, ShouldEqual, "People")
+				So(dataset.State, ShouldEqual, models.PublishedState)
+			})
+		})
+
+		Convey("When the editions, versions and a version of the dataset are requested", func() {
+			editions, err := c.GetEditions(ctx, "People")
+			So(err, ShouldBeNil)
+			So(editions.Items, ShouldHaveLength, 1)
+
+			edition, err := c.GetEdition(ctx, "People", "2011")
+			So(err, ShouldBeNil)
+			So(edition.Edition, ShouldEqual, "2011")
+
+			versions, err := c.GetVersions(ctx, "People", "2011")
+			So(err, ShouldBeNil)
+			So(versions.Items, ShouldNotBeEmpty)
+
+			version, err := c.GetVersion(ctx, "People", "2011", "1")
+
+			Convey("Then the version is returned", func() {
+				So(err, ShouldBeNil)
+				So(version.Version, ShouldEqual, 1)
+				So(version.State, ShouldEqual, models.PublishedState)
+			})
+		})
+
+		Convey("When the metadata and dimensions of a version are requested", func() {
+			metadata, err := c.GetMetadata(ctx, "People", "2011", "1")
+			So(err, ShouldBeNil)
+
+			dimensions, err := c.GetDimensions(ctx, "People", "2011", "1")
+			So(err, ShouldBeNil)
+
+			Convey("Then they describe the version", func() {
+				So(metadata.Downloads, ShouldNotBeNil)
+				So(dimensions.Items, ShouldHaveLength, 2)
+			})
+		})
+
+		Convey("When a page of options and options by id are requested", func() {
+			page, err := c.GetOptions(ctx, "People", "2011", "1", "AGE", 1, 1)
+			So(err, ShouldBeNil)
+
+			byID, err := c.GetOptionsByIDs(ctx, "People", "2011", "1", "AGE", []string{"65+"})
+			So(err, ShouldBeNil)
+
+			Convey("Then the requested options are returned", func() {
+				So(page.Offset, ShouldEqual, 1)
+				So(page.Items, ShouldHaveLength, 1)
+				So(page.TotalCount, ShouldEqual, 3)
+				So(byID.Items, ShouldHaveLength, 1)
+				So(byID.Items[0].Label, ShouldEqual, "Aged 65 and over")
+			})
+		})
+
+		Convey("When observations are requested", func() {
+			observations, err := c.GetObservations(ctx, "People", "2011", "1", map[string][]string{"SEX": {"*"}})
+
+			Convey("Then an observation is returned for each code of the wildcard dimension", func() {
+				So(err, ShouldBeNil)
+				So(observations.Count, ShouldEqual, 2)
+			})
+		})
+
+		Convey("When datasets are searched for by the label of a dimension", func() {
+			results, err := c.Search(ctx, "sex", models.FTBBlobType, "AGE", 0, 10)
+
+			Convey("Then the dataset is returned with the label highlighted", func() {
+				So(err, ShouldBeNil)
+				So(results.TotalCount, ShouldEqual, 1)
+				So(results.Items[0].ID, ShouldEqual, "People")
+				So(results.Items[0].Highlights["dimensions"], ShouldResemble, []string{"<em>Sex</em>"})
+			})
+		})
+
+		Convey("When a dataset that does not exist is requested", func() {
+			dataset, err := c.GetDataset(ctx, "Nope")
+
+			Convey("Then the problem details of the response are returned in the error", func() {
+				So(dataset, ShouldBeNil)
+				e, ok := err.(*ErrInvalidResponse)
+				So(ok, ShouldBeTrue)
+				So(e.Code(), ShouldEqual, http.StatusNotFound)
+				So(e.Problem, ShouldNotBeNil)
+				So(e.Problem.Detail, ShouldEqual, "dataset not found")
+			})
+		})
+	})
+}
+
+func TestIterators(t *testing.T) {
+	router, memoryStore := newRouter(t)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ctx := context.Background()
+	c := newClient(server.URL)
+
+	Convey("Given an API serving three published datasets", t, func() {
+		for _, id := range []string{"Households", "Workplaces"} {
+			dataset := &models.Dataset{ID: id, State: models.PublishedState}
+			So(memoryStore.UpsertDataset(context.Background(), id, &models.DatasetUpdate{ID: id, Current: dataset, Next: dataset}), ShouldBeNil)
+		}
+
+		Convey("When the datasets are iterated through a page at a time", func() {
+			var ids []string
+			it := c.Datasets(ctx, 2)
+			for it.Next() {
+				ids = append(ids, it.Dataset().ID)
+			}
+
+			Convey("Then every dataset is returned once", func() {
+				So(it.Err(), ShouldBeNil)
+				So(ids, ShouldHaveLength, 3)
+				So(ids, ShouldContain, "People")
+				So(ids, ShouldContain, "Households")
+				So(ids, ShouldContain, "Workplaces")
+			})
+		})
+
+		Convey("When the options of a dimension are iterated through a page at a time", func() {
+			var codes []string
+			it := c.Options(ctx, "People", "2011", "1", "AGE", 2)
+			for it.Next() {
+				codes = append(codes, it.Option().Option)
+			}
+
+			Convey("Then every option is returned once", func() {
+				So(it.Err(), ShouldBeNil)
+				So(codes, ShouldResemble, []string{"0-15", "16-64", "65+"})
+			})
+		})
+
+		Convey("When the options of a dimension of a version that does not exist are iterated through", func() {
+			it := c.Options(ctx, "People", "2011", "9", "AGE", 2)
+
+			Convey("Then the iteration stops with the error", func() {
+				So(it.Next(), ShouldBeFalse)
+				So(it.Err(), ShouldNotBeNil)
+				So(it.Next(), ShouldBeFalse)
+			})
+		})
+	})
+}
+
+func TestRetries(t *testing.T) {
+	router, _ := newRouter(t)
+	ctx := context.Background()
+
+	Convey("Given an API that fails the first two requests with a server error", t, func() {
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&requests, 1) <= 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			router.ServeHTTP(w, r)
+		}))
+		defer server.Close()
+
+		Convey("When a request is made by a client that retries twice", func() {
+			c := newClient(server.URL)
+			c.MaxRetries = 2
+			dataset, err := c.GetDataset(ctx, "People")
+
+			Convey("Then the third attempt succeeds", func() {
+				So(err, ShouldBeNil)
+				So(dataset.ID, ShouldEqual, "People")
+				So(atomic.LoadInt32(&requests), ShouldEqual, 3)
+			})
+		})
+
+		Convey("When a request is made by a client that retries once", func() {
+			c := newClient(server.URL)
+			c.MaxRetries = 1
+			_, err := c.GetDataset(ctx, "People")
+
+			Convey("Then the error of the last attempt is returned", func() {
+				So(err, ShouldNotBeNil)
+				So(err.(*ErrInvalidResponse).Code(), ShouldEqual, http.StatusServiceUnavailable)
+				So(atomic.LoadInt32(&requests), ShouldEqual, 2)
+			})
+		})
+	})
+
+	Convey("Given an API serving the People dataset", t, func() {
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			router.ServeHTTP(w, r)
+		}))
+		defer server.Close()
+
+		Convey("When a dataset that does not exist is requested", func() {
+			_, err := newClient(server.URL).GetDataset(ctx, "Nope")
+
+			Convey("Then the request is not retried", func() {
+				So(err, ShouldNotBeNil)
+				So(atomic.LoadInt32(&requests), ShouldEqual, 1)
+			})
+		})
+	})
+}
+
+func TestTimeouts(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given an API that does not respond", t, func() {
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			<-r.Context().Done()
+		}))
+		defer server.Close()
+
+		Convey("When a request is made by a client with a timeout", func() {
+			c := newClient(server.URL)
+			c.MaxRetries = 1
+			c.Timeout = 20 * time.Millisecond
+			_, err := c.GetDataset(ctx, "People")
+
+			Convey("Then each attempt times out", func() {
+				So(err, ShouldNotBeNil)
+				So(atomic.LoadInt32(&requests), ShouldEqual, 2)
+			})
+		})
+
+		Convey("When a request is made with a context that is cancelled", func() {
+			c := newClient(server.URL)
+			c.Timeout = 0
+			cancelCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+			defer cancel()
+			_, err := c.GetDataset(cancelCtx, "People")
+
+			Convey("Then the request is not retried", func() {
+				So(err, ShouldNotBeNil)
+				So(atomic.LoadInt32(&requests), ShouldEqual, 1)
+			})
+		})
+	})
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+)
+
+// pager requests the pages of a paginated list of the API as it is iterated through
+type pager struct {
+	// fetch requests a page of the list, returning the number of items in the page and in the whole list
+	fetch  func(offset, limit int) (count, totalCount int, err error)
+	offset int
+	limit  int
+	index  int
+	count  int
+	done   bool
+	err    error
+}
+
+// next moves on to the next item of the list, requesting the next page once the current page has been
+// iterated through
+func (p *pager) next() bool {
+	if p.err != nil {
+		return false
+	}
+
+	p.index++
+	if p.index < p.count {
+		return true
+	}
+
+	if p.done {
+		return false
+	}
+
+	count, totalCount, err := p.fetch(p.offset, p.limit)
+	if err != nil {
+		p.err = err
+		return false
+	}
+
+	p.offset += count
+	p.index, p.count = 0, count
+	p.done = count == 0 || p.offset >= totalCount
+
+	return count > 0
+}
+
+// DatasetIterator iterates through every published dataset, a page at a time
+type DatasetIterator struct {
+	pager
+	page []*models.Dataset
+}
+
+// Datasets returns an iterator through every published dataset, requesting pageSize datasets at a time. A pageSize of zero
+// uses the API's default limit.
+func (c *Client) Datasets(ctx context.Context, pageSize int) *DatasetIterator {
+	it := &DatasetIterator{}
+	it.limit = pageSize
+	it.fetch = func(offset, limit int) (int, int, error) {
+		results, err := c.GetDatasets(ctx, offset, limit)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		it.page = results.Items
+		return len(results.Items), results.TotalCount, nil
+	}
+
+	return it
+}
+
+// Next moves the iterator on to the next dataset, returning false when there are no more datasets or a request
+// has failed
+func (it *DatasetIterator) Next() bool {
+	return it.next()
+}
+
+// Dataset returns the current dataset of the iterator
+func (it *DatasetIterator) Dataset() *models.Dataset {
+	return it.page[it.index]
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *DatasetIterator) Err() error {
+	return it.err
+}
+
+// OptionIterator iterates through every option of a dimension, a page at a time
+type OptionIterator struct {
+	pager
+	page []models.PublicDimensionOption
+}
+
+// Options returns an iterator through every option of a dimension of a version, requesting pageSize options at
+// a time. A pageSize of zero uses the API's default limit.
+func (c *Client) Options(ctx context.Context, datasetID, edition, version, dimension string, pageSize int) *OptionIterator {
+	it := &OptionIterator{}
+	it.limit = pageSize
+	it.fetch = func(offset, limit int) (int, int, error) {
+		results, err := c.GetOptions(ctx, datasetID, edition, version, dimension, offset, limit)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		it.page = results.Items
+		return len(results.Items), results.TotalCount, nil
+	}
+
+	return it
+}
+
+// Next moves the iterator on to the next option, returning false when there are no more options or a request
+// has failed
+func (it *OptionIterator) Next() bool {
+	return it.next()
+}
+
+// Option returns the current option of the iterator
+func (it *OptionIterator) Option() models.PublicDimensionOption {
+	return it.page[it.index]
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *OptionIterator) Err() error {
+	return it.err
+}
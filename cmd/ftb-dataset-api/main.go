@@ -9,14 +9,30 @@ import (
 	"syscall"
 
 	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/api"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/cache"
 	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/config"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/ftb"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/health"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/memory"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/metrics"
 	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/mongo"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/search"
 	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store"
 	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/url"
-	mongolib "github.com/ONSdigital/dp-mongodb"
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	dphttp "github.com/ONSdigital/dp-net/http"
 	"github.com/ONSdigital/log.go/log"
 )
 
+var (
+	// BuildTime represents the time in which the service was built
+	BuildTime string
+	// GitCommit represents the commit (SHA-1) hash of the service that is running
+	GitCommit string
+	// Version represents the version of the service that is running
+	Version string
+)
+
 // check that DatsetAPIStore satifies the the store.Storer interface
 var _ store.Storer = (*DatsetAPIStore)(nil)
 
@@ -29,7 +45,17 @@ func main() {
 	log.Namespace = "dp-ftb-dataset-api"
 	ctx := context.Background()
 
-	if err := run(ctx); err != nil {
+	var err error
+	switch {
+	case len(os.Args) > 1 && os.Args[1] == "indexes":
+		err = runIndexes(ctx, os.Args[2:])
+	case len(os.Args) > 1 && os.Args[1] == "migrations":
+		err = runMigrations(ctx, os.Args[2:])
+	default:
+		err = run(ctx)
+	}
+
+	if err != nil {
 		log.Event(ctx, "application unexpectedly failed", log.ERROR, log.Error(err))
 		os.Exit(1)
 	}
@@ -49,32 +75,127 @@ func run(ctx context.Context) error {
 
 	log.Event(ctx, "config on startup", log.INFO, log.Data{"config": cfg})
 
-	mongodb := &mongo.Mongo{
-		CodeListURL: cfg.CodeListAPIURL,
-		Collection:  cfg.MongoConfig.Collection,
-		Database:    cfg.MongoConfig.Database,
-		DatasetURL:  cfg.FTBDatasetAPIURL,
-		URI:         cfg.MongoConfig.BindAddr,
+	if cfg.EnablePrivateEndpoints && cfg.ServiceAuthToken == "" {
+		err = errors.New("a service auth token must be configured when private endpoints are enabled")
+		log.Event(ctx, "invalid configuration", log.FATAL, log.Error(err))
+		return err
 	}
 
-	session, err := mongodb.Init()
-	if err != nil {
-		log.Event(ctx, "failed to initialise mongo", log.ERROR, log.Error(err))
-		return err
+	var mongodb *mongo.Mongo
+	var backend store.Storer
+
+	if cfg.InMemoryStore {
+		memoryStore := memory.New()
+		if cfg.FixturesDir != "" {
+			if err = memoryStore.Load(cfg.FixturesDir); err != nil {
+				log.Event(ctx, "failed to load fixtures into in-memory store", log.ERROR, log.Error(err), log.Data{"fixtures_dir": cfg.FixturesDir})
+				return err
+			}
+		}
+
+		log.Event(ctx, "using in-memory store", log.INFO, log.Data{"fixtures_dir": cfg.FixturesDir})
+		backend = memoryStore
 	} else {
-		mongodb.Session = session
-		log.Event(ctx, "listening to mongo db session", log.INFO, log.Data{
-			"bind_address": cfg.BindAddr,
+		mongodb = &mongo.Mongo{
+			CodeListURL: cfg.CodeListAPIURL,
+			Collection:  cfg.MongoConfig.Collection,
+			Database:    cfg.MongoConfig.Database,
+			DatasetURL:  cfg.FTBDatasetAPIURL,
+		}
+
+		if err = mongodb.Init(ctx, cfg.MongoConfig); err != nil {
+			log.Event(ctx, "failed to initialise mongo", log.ERROR, log.Error(err))
+			return err
+		}
+
+		log.Event(ctx, "connected to mongo db", log.INFO, log.Data{
+			"database":        cfg.MongoConfig.Database,
+			"read_preference": cfg.MongoConfig.ReadPreference,
+			"tls":             cfg.MongoConfig.EnableTLS,
 		})
+
+		// unique indexes are left to the indexes subcommand, as building one fails on duplicate documents, which
+		// would otherwise stop the API from starting
+		if _, err = syncIndexes(ctx, mongodb, cfg.MongoConfig.EnsureIndexes, false); err != nil {
+			log.Event(ctx, "failed to sync mongo indexes", log.ERROR, log.Error(err))
+			return err
+		}
+
+		if _, err = checkMigrations(ctx, mongodb); err != nil {
+			log.Event(ctx, "failed to check mongo migrations", log.ERROR, log.Error(err))
+			return err
+		}
+		backend = DatsetAPIStore{mongodb}
+	}
+
+	m := metrics.New()
+	backend = m.InstrumentStorer(backend)
+	if mongodb != nil {
+		if err = m.RegisterMongo(mongodb, cfg.HealthCheckTimeout); err != nil {
+			log.Event(ctx, "failed to register mongo metrics", log.ERROR, log.Error(err))
+			return err
+		}
+	}
+
+	// The store is timed beneath the cache, so that its metrics are of the calls that miss the cache
+	if cfg.CacheSize > 0 {
+		cacheStore, err := cache.New(backend, cfg.CacheSize, cfg.CachePublishedTTL, cfg.CacheMutableTTL)
+		if err != nil {
+			log.Event(ctx, "failed to create store cache", log.ERROR, log.Error(err))
+			return err
+		}
+
+		if err = m.RegisterCache(cacheStore); err != nil {
+			log.Event(ctx, "failed to register cache metrics", log.ERROR, log.Error(err))
+			return err
+		}
+
+		log.Event(ctx, "caching store results", log.INFO, log.Data{"size": cfg.CacheSize, "published_ttl": cfg.CachePublishedTTL, "mutable_ttl": cfg.CacheMutableTTL})
+		backend = cacheStore
 	}
 
-	store := store.DataStore{Backend: DatsetAPIStore{mongodb}}
+	// The in-memory store has no text indexes, so it is always searched with the in-process index
+	var searcher store.Searcher
+	if cfg.InMemoryStore || cfg.InProcessSearch {
+		index := search.NewIndex(backend)
+		if err = index.Build(ctx); err != nil {
+			log.Event(ctx, "failed to build search index", log.ERROR, log.Error(err))
+			return err
+		}
+
+		refreshCtx, cancelRefresh := context.WithCancel(ctx)
+		defer cancelRefresh()
+		go index.Refresh(refreshCtx, cfg.SearchRefreshInterval)
+
+		log.Event(ctx, "using in-process search index", log.INFO, log.Data{"refresh_interval": cfg.SearchRefreshInterval})
+		searcher = index
+	} else {
+		searcher = mongodb
+	}
+
+	store := store.DataStore{Backend: backend, Searcher: searcher}
+
+	versionInfo, err := healthcheck.NewVersionInfo(BuildTime, GitCommit, Version)
+	if err != nil {
+		log.Event(ctx, "failed to create service version information", log.FATAL, log.Error(err))
+		return err
+	}
+
+	hc := healthcheck.New(versionInfo, cfg.HealthCriticalTimeout, cfg.HealthCheckInterval)
+	if err = registerCheckers(ctx, cfg, &hc, mongodb); err != nil {
+		return err
+	}
+	hc.Start(ctx)
 
 	apiErrors := make(chan error, 1)
 
 	urlBuilder := url.NewBuilder(cfg.WebsiteURL)
 
-	api.CreateAndInitialiseFTBDatasetAPI(ctx, *cfg, store, urlBuilder, apiErrors)
+	// The FTB table engine is not yet available, so observations are served from fixture cubes
+	log.Event(ctx, "using fixture cubes for observations", log.INFO, log.Data{"ftb_cubes_dir": cfg.FTBCubesDir})
+	ftbClient := ftb.NewFileClient(cfg.FTBCubesDir)
+
+	api.CreateAndInitialiseFTBDatasetAPI(ctx, *cfg, &hc, m, store, ftbClient, urlBuilder, apiErrors)
 
 	// block until a fatal error occurs
 	select {
@@ -98,9 +219,13 @@ func run(ctx context.Context) error {
 		// stop any incoming requests before closing any outbound connections
 		api.Close(shutdownContext)
 
-		if err = mongolib.Close(ctx, mongodb.Session); err != nil {
-			log.Event(shutdownContext, "failed to close mongo db session", log.ERROR, log.Error(err))
-			hasShutdownError = true
+		hc.Stop()
+
+		if mongodb != nil {
+			if err = mongodb.Close(shutdownContext); err != nil {
+				log.Event(shutdownContext, "failed to close mongo db client", log.ERROR, log.Error(err))
+				hasShutdownError = true
+			}
 		}
 
 		if !hasShutdownError {
@@ -121,3 +246,156 @@ func run(ctx context.Context) error {
 
 	return nil
 }
+
+// runIndexes checks the indexes of the mongo database against those declared by the store, and with "ensure"
+// creates those that are missing, so that they can be built ahead of a deploy:
+//
+//	ftb-dataset-api indexes check
+//	ftb-dataset-api indexes ensure
+//
+// An error is returned if any declared index is missing, or exists with different keys or options, once done.
+func runIndexes(ctx context.Context, args []string) error {
+	if len(args) != 1 || (args[0] != "check" && args[0] != "ensure") {
+		return errors.New("usage: ftb-dataset-api indexes check|ensure")
+	}
+
+	cfg, mongodb, err := initMongo(ctx)
+	if err != nil {
+		return err
+	}
+	defer mongodb.Close(ctx)
+
+	drift, err := syncIndexes(ctx, mongodb, args[0] == "ensure", true)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range drift {
+		if d.Kind != mongo.IndexUndeclared {
+			return errors.New("the indexes of the database differ from those declared")
+		}
+	}
+
+	log.Event(ctx, "mongo indexes match those declared", log.INFO, log.Data{"database": cfg.MongoConfig.Database})
+	return nil
+}
+
+// syncIndexes compares the indexes of the mongo database with those declared by the store, creating any that are
+// missing when ensure is set, including unique ones when unique is set, and logs the differences that remain
+func syncIndexes(ctx context.Context, mongodb *mongo.Mongo, ensure, unique bool) ([]mongo.IndexDrift, error) {
+	var drift []mongo.IndexDrift
+	var err error
+	if ensure {
+		drift, err = mongodb.EnsureIndexes(ctx, mongo.Indexes, unique)
+	} else {
+		drift, err = mongodb.CheckIndexes(ctx, mongo.Indexes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range drift {
+		log.Event(ctx, "mongo index differs from declared indexes", log.WARN, log.Data{
+			"collection": d.Collection,
+			"index":      d.Name,
+			"kind":       d.Kind,
+			"detail":     d.Detail,
+		})
+	}
+
+	return drift, nil
+}
+
+// runMigrations runs the migrations of the documents of the mongo database that have not been applied. "status"
+// lists them, "dry-run" reports the documents each would change, and "up" applies them:
+//
+//	ftb-dataset-api migrations status
+//	ftb-dataset-api migrations dry-run
+//	ftb-dataset-api migrations up
+func runMigrations(ctx context.Context, args []string) error {
+	if len(args) != 1 || (args[0] != "status" && args[0] != "dry-run" && args[0] != "up") {
+		return errors.New("usage: ftb-dataset-api migrations status|dry-run|up")
+	}
+
+	cfg, mongodb, err := initMongo(ctx)
+	if err != nil {
+		return err
+	}
+	defer mongodb.Close(ctx)
+
+	if args[0] == "status" {
+		_, err = checkMigrations(ctx, mongodb)
+		return err
+	}
+
+	results, err := mongodb.Migrate(ctx, mongo.Migrations, args[0] == "dry-run")
+	for _, result := range results {
+		log.Event(ctx, "mongo migration run", log.INFO, log.Data{
+			"version":     result.Version,
+			"description": result.Description,
+			"documents":   result.Documents,
+			"dry_run":     result.DryRun,
+		})
+	}
+	if err != nil {
+		log.Event(ctx, "mongo migration failed", log.ERROR, log.Error(err))
+		return err
+	}
+
+	log.Event(ctx, "mongo migrations complete", log.INFO, log.Data{"database": cfg.MongoConfig.Database, "run": len(results)})
+	return nil
+}
+
+// checkMigrations logs the migrations of the mongo database that have not been applied, as the documents they
+// change may not be readable until they are
+func checkMigrations(ctx context.Context, mongodb *mongo.Mongo) ([]mongo.Migration, error) {
+	pending, err := mongodb.PendingMigrations(ctx, mongo.Migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, migration := range pending {
+		log.Event(ctx, "mongo migration has not been applied", log.WARN, log.Data{
+			"version":     migration.Version,
+			"description": migration.Description,
+		})
+	}
+
+	return pending, nil
+}
+
+// initMongo connects to the mongo database of the configuration, for the subcommands that only need the database
+func initMongo(ctx context.Context) (*config.Configuration, *mongo.Mongo, error) {
+	cfg, err := config.Get()
+	if err != nil {
+		log.Event(ctx, "failed to retrieve configuration", log.FATAL, log.Error(err))
+		return nil, nil, err
+	}
+
+	mongodb := &mongo.Mongo{Database: cfg.MongoConfig.Database}
+	if err = mongodb.Init(ctx, cfg.MongoConfig); err != nil {
+		log.Event(ctx, "failed to initialise mongo", log.ERROR, log.Error(err))
+		return nil, nil, err
+	}
+
+	return cfg, mongodb, nil
+}
+
+// registerCheckers adds a health check for each dependency of the service, where mongodb is nil when the
+// in-memory store is used
+func registerCheckers(ctx context.Context, cfg *config.Configuration, hc *healthcheck.HealthCheck, mongodb *mongo.Mongo) error {
+	if mongodb != nil {
+		if err := hc.AddCheck("Mongo DB", health.WithTimeout(mongodb.Checker, cfg.HealthCheckTimeout)); err != nil {
+			log.Event(ctx, "error adding check for mongo db", log.ERROR, log.Error(err))
+			return err
+		}
+	}
+
+	codeListChecker := health.NewHTTPChecker("CodeList API", dphttp.NewClient(), cfg.CodeListAPIURL)
+	if err := hc.AddCheck("CodeList API", health.WithTimeout(codeListChecker, cfg.HealthCheckTimeout)); err != nil {
+		log.Event(ctx, "error adding check for code list api", log.ERROR, log.Error(err))
+		return err
+	}
+
+	return nil
+}
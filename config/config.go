@@ -10,18 +10,48 @@ import (
 // Configuration structure which hold information for configuring the import API
 type Configuration struct {
 	BindAddr                string        `envconfig:"BIND_ADDR"`
+	CacheMutableTTL         time.Duration `envconfig:"CACHE_MUTABLE_TTL"`
+	CachePublishedTTL       time.Duration `envconfig:"CACHE_PUBLISHED_TTL"`
+	CacheSize               int           `envconfig:"CACHE_SIZE"`
 	CodeListAPIURL          string        `envconfig:"CODE_LIST_API_URL"`
+	DefaultMaxLimit         int           `envconfig:"DEFAULT_MAXIMUM_LIMIT"`
+	DefaultLimit            int           `envconfig:"DEFAULT_LIMIT"`
+	DefaultOffset           int           `envconfig:"DEFAULT_OFFSET"`
+	EnablePrivateEndpoints  bool          `envconfig:"ENABLE_PRIVATE_ENDPOINTS"`
+	FTBCubesDir             string        `envconfig:"FTB_CUBES_DIR"`
 	FTBDatasetAPIURL        string        `envconfig:"FTBDATASET_API_URL"`
+	FixturesDir             string        `envconfig:"FIXTURES_DIR"`
 	GracefulShutdownTimeout time.Duration `envconfig:"GRACEFUL_SHUTDOWN_TIMEOUT"`
+	HealthCheckInterval     time.Duration `envconfig:"HEALTHCHECK_INTERVAL"`
+	HealthCheckTimeout      time.Duration `envconfig:"HEALTHCHECK_TIMEOUT"`
+	HealthCriticalTimeout   time.Duration `envconfig:"HEALTHCHECK_CRITICAL_TIMEOUT"`
+	InMemoryStore           bool          `envconfig:"IN_MEMORY_STORE"`
+	InProcessSearch         bool          `envconfig:"IN_PROCESS_SEARCH"`
+	RedirectLatest          bool          `envconfig:"REDIRECT_LATEST"`
+	RequestTimeout          time.Duration `envconfig:"REQUEST_TIMEOUT"`
+	SearchRefreshInterval   time.Duration `envconfig:"SEARCH_REFRESH_INTERVAL"`
+	ServiceAuthToken        string        `envconfig:"SERVICE_AUTH_TOKEN"          json:"-"`
 	WebsiteURL              string        `envconfig:"WEBSITE_URL"`
 	MongoConfig             MongoConfig
 }
 
 // MongoConfig contains the config required to connect to MongoDB.
 type MongoConfig struct {
-	BindAddr   string `envconfig:"MONGODB_BIND_ADDR"   json:"-"`
-	Collection string `envconfig:"MONGODB_COLLECTION"`
-	Database   string `envconfig:"MONGODB_DATABASE"`
+	AuthSource            string        `envconfig:"MONGODB_AUTH_SOURCE"`
+	BindAddr              string        `envconfig:"MONGODB_BIND_ADDR"                json:"-"`
+	CAFilePath            string        `envconfig:"MONGODB_CA_FILE_PATH"`
+	Collection            string        `envconfig:"MONGODB_COLLECTION"`
+	ConnectTimeout        time.Duration `envconfig:"MONGODB_CONNECT_TIMEOUT"`
+	Database              string        `envconfig:"MONGODB_DATABASE"`
+	EnableTLS             bool          `envconfig:"MONGODB_ENABLE_TLS"`
+	EnsureIndexes         bool          `envconfig:"MONGODB_ENSURE_INDEXES"`
+	MaxConnIdleTime       time.Duration `envconfig:"MONGODB_MAX_CONN_IDLE_TIME"`
+	MaxPoolSize           uint64        `envconfig:"MONGODB_MAX_POOL_SIZE"`
+	MinPoolSize           uint64        `envconfig:"MONGODB_MIN_POOL_SIZE"`
+	Password              string        `envconfig:"MONGODB_PASSWORD"                 json:"-"`
+	ReadPreference        string        `envconfig:"MONGODB_READ_PREFERENCE"`
+	TLSInsecureSkipVerify bool          `envconfig:"MONGODB_TLS_INSECURE_SKIP_VERIFY"`
+	Username              string        `envconfig:"MONGODB_USERNAME"`
 }
 
 var cfg *Configuration
@@ -34,14 +64,44 @@ func Get() (*Configuration, error) {
 
 	cfg = &Configuration{
 		BindAddr:                ":10400",
+		CacheMutableTTL:         5 * time.Second,
+		CachePublishedTTL:       time.Hour,
+		CacheSize:               10000,
 		CodeListAPIURL:          "http://localhost:22400",
+		DefaultMaxLimit:         1000,
+		DefaultLimit:            20,
+		DefaultOffset:           0,
+		EnablePrivateEndpoints:  false,
+		FTBCubesDir:             "",
 		FTBDatasetAPIURL:        "http://localhost:10400",
+		FixturesDir:             "",
 		GracefulShutdownTimeout: 5 * time.Second,
+		HealthCheckInterval:     30 * time.Second,
+		HealthCheckTimeout:      10 * time.Second,
+		HealthCriticalTimeout:   90 * time.Second,
+		InMemoryStore:           false,
+		InProcessSearch:         false,
+		RedirectLatest:          false,
+		RequestTimeout:          30 * time.Second,
+		SearchRefreshInterval:   time.Minute,
+		ServiceAuthToken:        "",
 		WebsiteURL:              "http://localhost:20000",
 		MongoConfig: MongoConfig{
-			BindAddr:   "localhost:27017",
-			Collection: "datasets",
-			Database:   "ftb-datasets",
+			AuthSource:            "",
+			BindAddr:              "localhost:27017",
+			CAFilePath:            "",
+			Collection:            "datasets",
+			ConnectTimeout:        5 * time.Second,
+			Database:              "ftb-datasets",
+			EnableTLS:             false,
+			EnsureIndexes:         true,
+			MaxConnIdleTime:       0,
+			MaxPoolSize:           100,
+			MinPoolSize:           0,
+			Password:              "",
+			ReadPreference:        "primary",
+			TLSInsecureSkipVerify: false,
+			Username:              "",
 		},
 	}
 
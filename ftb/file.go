@@ -0,0 +1,129 @@
+package ftb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+)
+
+// FileClient is a stub of the FTB table engine that answers cross-tabulations from fixture cubes, so the
+// observations of a version can be queried offline. Each cube is a JSON encoded models.CrossTab, holding a
+// cell for every combination of codes, stored at <dir>/<dataset_id>/<edition>/<version>.json.
+type FileClient struct {
+	dir string
+}
+
+// NewFileClient returns a FileClient serving the cubes in dir
+func NewFileClient(dir string) *FileClient {
+	return &FileClient{dir: dir}
+}
+
+// Query cross-tabulates the cube of a version by the dimensions of the query, summing the cells of the
+// dimensions left out of the query
+func (c *FileClient) Query(ctx context.Context, query *models.CrossTabQuery) (*models.CrossTab, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	cube, err := c.load(query.DatasetID, query.Edition, query.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	// position of each query dimension within the codes of a cube cell
+	indexes := make([]int, len(query.Dimensions))
+	codes := make([]map[string]bool, len(query.Dimensions))
+	for i, dimension := range query.Dimensions {
+		indexes[i] = indexOf(cube.Dimensions, dimension.Name)
+		if indexes[i] < 0 {
+			return nil, fmt.Errorf("dimension %s is not in the cube for version %s of %s/%s",
+				dimension.Name, query.Version, query.DatasetID, query.Edition)
+		}
+
+		if len(dimension.Codes) > 0 {
+			codes[i] = make(map[string]bool)
+			for _, code := range dimension.Codes {
+				codes[i][code] = true
+			}
+		}
+	}
+
+	crossTab := &models.CrossTab{Dimensions: make([]string, len(query.Dimensions)), Cells: []models.CrossTabCell{}}
+	for i, dimension := range query.Dimensions {
+		crossTab.Dimensions[i] = dimension.Name
+	}
+
+	cells := make(map[string]int)
+	for _, cell := range cube.Cells {
+		key, ok := cellCodes(cell, indexes, codes)
+		if !ok {
+			continue
+		}
+
+		id := strings.Join(key, "\x00")
+		if i, ok := cells[id]; ok {
+			crossTab.Cells[i].Value += cell.Value
+			continue
+		}
+
+		cells[id] = len(crossTab.Cells)
+		crossTab.Cells = append(crossTab.Cells, models.CrossTabCell{Codes: key, Value: cell.Value})
+	}
+
+	return crossTab, nil
+}
+
+func (c *FileClient) load(datasetID, edition, version string) (*models.CrossTab, error) {
+	path := filepath.Join(c.dir, filepath.Base(datasetID), filepath.Base(edition), filepath.Base(version)+".json")
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errs.ErrObservationsNotFound
+		}
+		return nil, err
+	}
+
+	var cube models.CrossTab
+	if err = json.Unmarshal(b, &cube); err != nil {
+		return nil, fmt.Errorf("failed to parse cube %s: %w", path, err)
+	}
+
+	return &cube, nil
+}
+
+// cellCodes returns the codes of a cube cell for the query dimensions, and whether the cell is within the codes
+// the query is restricted to
+func cellCodes(cell models.CrossTabCell, indexes []int, codes []map[string]bool) ([]string, bool) {
+	key := make([]string, len(indexes))
+	for i, index := range indexes {
+		if index >= len(cell.Codes) {
+			return nil, false
+		}
+
+		code := cell.Codes[index]
+		if codes[i] != nil && !codes[i][code] {
+			return nil, false
+		}
+		key[i] = code
+	}
+
+	return key, true
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,83 @@
+package ftb
+
+import (
+	"context"
+	"testing"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFileClient_Query(t *testing.T) {
+
+	Convey("Given a file client serving the fixture cubes", t, func() {
+		client := NewFileClient("../fixtures/cubes")
+		ctx := context.Background()
+
+		Convey("When a version is cross-tabulated by one of its two dimensions", func() {
+			crossTab, err := client.Query(ctx, &models.CrossTabQuery{
+				DatasetID:  "People",
+				Edition:    "2011",
+				Version:    "1",
+				Dimensions: []models.CrossTabDimension{{Name: "SEX"}},
+			})
+
+			Convey("Then the cells are summed over the other dimension", func() {
+				So(err, ShouldBeNil)
+				So(crossTab.Dimensions, ShouldResemble, []string{"SEX"})
+				So(crossTab.Cells, ShouldResemble, []models.CrossTabCell{
+					{Codes: []string{"1"}, Value: 27936127},
+					{Codes: []string{"2"}, Value: 28872948},
+				})
+			})
+		})
+
+		Convey("When a version is cross-tabulated by restricted codes in the order of the query", func() {
+			crossTab, err := client.Query(ctx, &models.CrossTabQuery{
+				DatasetID: "People",
+				Edition:   "2011",
+				Version:   "1",
+				Dimensions: []models.CrossTabDimension{
+					{Name: "SEX", Codes: []string{"2"}},
+					{Name: "AGE", Codes: []string{"0-15", "65+"}},
+				},
+			})
+
+			Convey("Then only the cells with those codes are returned", func() {
+				So(err, ShouldBeNil)
+				So(crossTab.Dimensions, ShouldResemble, []string{"SEX", "AGE"})
+				So(crossTab.Cells, ShouldResemble, []models.CrossTabCell{
+					{Codes: []string{"2", "0-15"}, Value: 5185763},
+					{Codes: []string{"2", "65+"}, Value: 5126153},
+				})
+			})
+		})
+
+		Convey("When a version without a cube is cross-tabulated", func() {
+			_, err := client.Query(ctx, &models.CrossTabQuery{
+				DatasetID:  "People",
+				Edition:    "2011",
+				Version:    "2",
+				Dimensions: []models.CrossTabDimension{{Name: "SEX"}},
+			})
+
+			Convey("Then no observations are found", func() {
+				So(err, ShouldEqual, errs.ErrObservationsNotFound)
+			})
+		})
+
+		Convey("When a dimension not in the cube is requested", func() {
+			_, err := client.Query(ctx, &models.CrossTabQuery{
+				DatasetID:  "People",
+				Edition:    "2011",
+				Version:    "1",
+				Dimensions: []models.CrossTabDimension{{Name: "REGION"}},
+			})
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
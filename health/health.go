@@ -0,0 +1,54 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	dphttp "github.com/ONSdigital/dp-net/http"
+	"github.com/ONSdigital/log.go/log"
+)
+
+const healthPath = "/health"
+
+// NewHTTPChecker returns a checker that reports the health of a downstream service from the status code returned
+// by its health endpoint. A 429 response is reported as a warning, any other failure as critical.
+func NewHTTPChecker(name string, client dphttp.Clienter, host string) healthcheck.Checker {
+	url := host + healthPath
+
+	return func(ctx context.Context, state *healthcheck.CheckState) error {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			return state.Update(healthcheck.StatusCritical, err.Error(), 0)
+		}
+
+		resp, err := client.Do(ctx, req)
+		if err != nil {
+			log.Event(ctx, "health check request failed", log.WARN, log.Error(err), log.Data{"check": name, "url": url})
+			return state.Update(healthcheck.StatusCritical, err.Error(), 0)
+		}
+		defer resp.Body.Close()
+
+		switch {
+		case resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices:
+			return state.Update(healthcheck.StatusOK, fmt.Sprintf("%s is ok", name), resp.StatusCode)
+		case resp.StatusCode == http.StatusTooManyRequests:
+			return state.Update(healthcheck.StatusWarning, fmt.Sprintf("%s is degraded, but at least partially functioning", name), resp.StatusCode)
+		default:
+			return state.Update(healthcheck.StatusCritical, fmt.Sprintf("%s functionality is unavailable or non-functioning", name), resp.StatusCode)
+		}
+	}
+}
+
+// WithTimeout limits how long a single run of a checker can take, so a hanging dependency is reported as
+// critical rather than leaving its last known state in place
+func WithTimeout(checker healthcheck.Checker, timeout time.Duration) healthcheck.Checker {
+	return func(ctx context.Context, state *healthcheck.CheckState) error {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		return checker(ctx, state)
+	}
+}
@@ -0,0 +1,517 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// GetDatasets retrieves a page of dataset documents along with the total number of datasets. When authorised only
+// datasets with a next revision are returned, and otherwise only those with a current, published, revision.
+func (s *Store) GetDatasets(ctx context.Context, offset, limit int, authorised bool) (*models.DatasetUpdateResults, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	datasets, err := s.datasets(func(dataset *models.DatasetUpdate) bool { return dataset.Revision(authorised) != nil })
+	if err != nil {
+		return nil, err
+	}
+
+	// Sort by id so that pages are stable between requests
+	sort.Slice(datasets, func(i, j int) bool { return datasets[i].ID < datasets[j].ID })
+
+	results := []models.DatasetUpdate{}
+	if limit > 0 {
+		start, end := pageBounds(len(datasets), offset, limit)
+		results = append(results, datasets[start:end]...)
+	}
+
+	return &models.DatasetUpdateResults{
+		Count:      len(results),
+		Items:      results,
+		Limit:      limit,
+		Offset:     offset,
+		TotalCount: len(datasets),
+	}, nil
+}
+
+// GetDataset retrieves a dataset document
+func (s *Store) GetDataset(ctx context.Context, id string) (*models.DatasetUpdate, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	datasets, err := s.datasets(func(dataset *models.DatasetUpdate) bool { return dataset.ID == id })
+	if err != nil {
+		return nil, err
+	}
+
+	if len(datasets) < 1 {
+		return nil, errs.ErrDatasetNotFound
+	}
+
+	return &datasets[0], nil
+}
+
+// UpsertDataset adds or overrides an existing dataset document
+func (s *Store) UpsertDataset(ctx context.Context, id string, datasetDoc *models.DatasetUpdate) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	fields, err := normalise(datasetDoc)
+	if err != nil {
+		return err
+	}
+
+	i := s.findIndex(datasetsCollection, func(doc bson.M) bool { return doc["_id"] == id })
+	if i < 0 {
+		doc := bson.M{"_id": id, "last_updated": time.Now()}
+		s.documents[datasetsCollection] = append(s.documents[datasetsCollection], doc)
+		i = len(s.documents[datasetsCollection]) - 1
+	}
+
+	return set(s.documents[datasetsCollection][i], fields.(bson.M))
+}
+
+// UpdateDataset updates the next sub-document of an existing dataset with the fields provided
+func (s *Store) UpdateDataset(ctx context.Context, id string, dataset *models.Dataset, currentState string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	i := s.findIndex(datasetsCollection, func(doc bson.M) bool { return doc["_id"] == id })
+	if i < 0 {
+		return errs.ErrDatasetNotFound
+	}
+
+	return set(s.documents[datasetsCollection][i], models.DatasetUpdateFields(dataset, currentState))
+}
+
+// datasets returns every dataset document accepted by the match function. The caller must hold the store lock.
+func (s *Store) datasets(match func(dataset *models.DatasetUpdate) bool) ([]models.DatasetUpdate, error) {
+	var results []models.DatasetUpdate
+	for _, doc := range s.documents[datasetsCollection] {
+		var dataset models.DatasetUpdate
+		if err := decode(doc, &dataset); err != nil {
+			return nil, err
+		}
+
+		if match(&dataset) {
+			results = append(results, dataset)
+		}
+	}
+
+	return results, nil
+}
+
+// GetEditions retrieves the edition documents for a dataset that match the query, which may be nil
+func (s *Store) GetEditions(ctx context.Context, id, state string, query *models.EditionsQuery) (*models.EditionUpdateResults, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	editions, err := s.editions(func(edition *models.EditionUpdate) bool {
+		if !editionMatches(edition, id, "", state) {
+			return false
+		}
+
+		// The state of the revision read is filtered, as in buildEditionsQuery in the mongo store
+		if query != nil && query.State != "" {
+			return edition.Revision(state == "").State == query.State
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(editions) < 1 {
+		return nil, errs.ErrEditionNotFound
+	}
+
+	results := make([]*models.EditionUpdate, len(editions))
+	for i := range editions {
+		results[i] = &editions[i]
+	}
+
+	return &models.EditionUpdateResults{Items: results}, nil
+}
+
+// GetEdition retrieves an edition document for a dataset
+func (s *Store) GetEdition(ctx context.Context, id, editionID, state string) (*models.EditionUpdate, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	editions, err := s.editions(func(edition *models.EditionUpdate) bool {
+		return editionMatches(edition, id, editionID, state)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(editions) < 1 {
+		return nil, errs.ErrEditionNotFound
+	}
+
+	return &editions[0], nil
+}
+
+// editionMatches applies the same selection as buildEditionsQuery and buildEditionQuery in the mongo store. With a
+// state the current sub-document is matched, otherwise the next sub-document is used. An empty editionID matches
+// every edition of the dataset.
+func editionMatches(edition *models.EditionUpdate, id, editionID, state string) bool {
+	doc := edition.Next
+	if state != "" {
+		doc = edition.Current
+	}
+
+	if doc == nil || doc.Links == nil || doc.Links.Dataset == nil || doc.Links.Dataset.ID != id {
+		return false
+	}
+
+	if editionID != "" && doc.Edition != editionID {
+		return false
+	}
+
+	return state == "" || doc.State == state
+}
+
+// UpsertEdition adds or overrides an existing edition document
+func (s *Store) UpsertEdition(ctx context.Context, datasetID, edition string, editionDoc *models.EditionUpdate) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	editionDoc.Next.LastUpdated = time.Now()
+
+	fields, err := normalise(editionDoc)
+	if err != nil {
+		return err
+	}
+
+	i := s.findIndex(editionsCollection, func(doc bson.M) bool {
+		return lookup(doc, "next.edition") == edition && lookup(doc, "next.links.dataset.id") == datasetID
+	})
+	if i < 0 {
+		doc := bson.M{"next": bson.M{"edition": edition, "links": bson.M{"dataset": bson.M{"id": datasetID}}}}
+		s.documents[editionsCollection] = append(s.documents[editionsCollection], doc)
+		i = len(s.documents[editionsCollection]) - 1
+	}
+
+	return set(s.documents[editionsCollection][i], fields.(bson.M))
+}
+
+// editions returns every edition document accepted by the match function. The caller must hold the store lock.
+func (s *Store) editions(match func(edition *models.EditionUpdate) bool) ([]models.EditionUpdate, error) {
+	var results []models.EditionUpdate
+	for _, doc := range s.documents[editionsCollection] {
+		var edition models.EditionUpdate
+		if err := decode(doc, &edition); err != nil {
+			return nil, err
+		}
+
+		if match(&edition) {
+			results = append(results, edition)
+		}
+	}
+
+	return results, nil
+}
+
+// GetNextVersion retrieves the latest version for an edition of a dataset
+func (s *Store) GetNextVersion(ctx context.Context, datasetID, edition string) (int, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	versions, err := s.versions(func(version *models.Version) bool {
+		return versionBelongsTo(version, datasetID, edition)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	nextVersion := 1
+	for _, version := range versions {
+		if version.Version >= nextVersion {
+			nextVersion = version.Version + 1
+		}
+	}
+
+	return nextVersion, nil
+}
+
+// GetVersions retrieves the version documents for a dataset edition that match the query, which may be nil, in
+// the order it sorts them by
+func (s *Store) GetVersions(ctx context.Context, id, editionID, state string, query *models.VersionsQuery) (*models.VersionResults, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	results, err := s.versions(func(version *models.Version) bool {
+		if !versionBelongsTo(version, id, editionID) {
+			return false
+		}
+
+		if query != nil && !versionMatchesQuery(version, query) {
+			return false
+		}
+
+		// Without a state only versions that have been confirmed against an edition are returned, as in
+		// buildVersionsQuery in the mongo store
+		if state == "" {
+			return version.State == models.EditionConfirmedState ||
+				version.State == models.AssociatedState ||
+				version.State == models.PublishedState
+		}
+
+		return version.State == state
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) < 1 {
+		return nil, errs.ErrVersionNotFound
+	}
+
+	if query != nil {
+		sortVersions(results, query.Sort)
+	}
+
+	for i := 0; i < len(results); i++ {
+		links := results[i].Links
+		if links == nil || links.Version == nil {
+			continue
+		}
+
+		if links.Self == nil {
+			links.Self = &models.LinkObject{}
+		}
+		links.Self.HRef = links.Version.HRef
+	}
+
+	return &models.VersionResults{Items: results}, nil
+}
+
+// versionMatchesQuery applies the filters of buildVersionsQuery in the mongo store, where release dates are
+// compared as strings
+func versionMatchesQuery(version *models.Version, query *models.VersionsQuery) bool {
+	if query.State != "" && version.State != query.State {
+		return false
+	}
+
+	if query.ReleaseDateFrom != "" && version.ReleaseDate < query.ReleaseDateFrom {
+		return false
+	}
+
+	if query.ReleaseDateTo != "" && version.ReleaseDate > query.ReleaseDateTo {
+		return false
+	}
+
+	return query.Version == 0 || version.Version == query.Version
+}
+
+// sortVersions sorts versions as buildVersionsSort in the mongo store does, leaving them in the order they are
+// stored when no sort is given
+func sortVersions(versions []models.Version, order string) {
+	less := map[string]func(a, b *models.Version) bool{
+		models.VersionSortVersion: func(a, b *models.Version) bool {
+			return a.Version < b.Version
+		},
+		models.VersionSortVersionDesc: func(a, b *models.Version) bool {
+			return a.Version > b.Version
+		},
+		models.VersionSortReleaseDate: func(a, b *models.Version) bool {
+			if a.ReleaseDate != b.ReleaseDate {
+				return a.ReleaseDate < b.ReleaseDate
+			}
+			return a.Version < b.Version
+		},
+		models.VersionSortReleaseDateDesc: func(a, b *models.Version) bool {
+			if a.ReleaseDate != b.ReleaseDate {
+				return a.ReleaseDate > b.ReleaseDate
+			}
+			return a.Version > b.Version
+		},
+	}[order]
+
+	if less == nil {
+		return
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		return less(&versions[i], &versions[j])
+	})
+}
+
+// GetVersion retrieves a version document for a dataset edition
+func (s *Store) GetVersion(ctx context.Context, id, editionID, versionID, state string) (*models.Version, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	versionNumber, err := strconv.Atoi(versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := s.versions(func(version *models.Version) bool {
+		if !versionBelongsTo(version, id, editionID) || version.Version != versionNumber {
+			return false
+		}
+
+		// The state is only used to restrict the match to published versions, as in buildVersionQuery in the
+		// mongo store
+		return state != models.PublishedState || version.State == state
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) < 1 {
+		return nil, errs.ErrVersionNotFound
+	}
+
+	return &results[0], nil
+}
+
+// versionBelongsTo checks a version is part of the given edition of a dataset
+func versionBelongsTo(version *models.Version, datasetID, edition string) bool {
+	return version.Edition == edition && version.Links != nil && version.Links.Dataset != nil &&
+		version.Links.Dataset.ID == datasetID
+}
+
+// UpdateVersion updates an existing version document with the fields provided
+func (s *Store) UpdateVersion(ctx context.Context, id string, version *models.Version) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	i := s.findIndex(instancesCollection, func(doc bson.M) bool { return doc["id"] == id })
+	if i < 0 {
+		return errs.ErrVersionNotFound
+	}
+
+	doc := s.documents[instancesCollection][i]
+	if err := set(doc, models.VersionUpdateFields(version)); err != nil {
+		return err
+	}
+
+	// A published version no longer belongs to a collection
+	if version.State == models.PublishedState {
+		delete(doc, "collection_id")
+	}
+
+	return nil
+}
+
+// versions returns every instance document, decoded as a version, accepted by the match function. The caller
+// must hold the store lock.
+func (s *Store) versions(match func(version *models.Version) bool) ([]models.Version, error) {
+	var results []models.Version
+	for _, doc := range s.documents[instancesCollection] {
+		var version models.Version
+		if err := decode(doc, &version); err != nil {
+			return nil, err
+		}
+
+		if match(&version) {
+			results = append(results, version)
+		}
+	}
+
+	return results, nil
+}
+
+// CheckDatasetExists checks that the dataset exists
+func (s *Store) CheckDatasetExists(ctx context.Context, id, state string) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	datasets, err := s.datasets(func(dataset *models.DatasetUpdate) bool {
+		if dataset.ID != id {
+			return false
+		}
+
+		return state == "" || (dataset.Current != nil && dataset.Current.State == state)
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(datasets) == 0 {
+		return errs.ErrDatasetNotFound
+	}
+
+	return nil
+}
+
+// CheckEditionExists checks that the edition of a dataset exists
+func (s *Store) CheckEditionExists(ctx context.Context, id, editionID, state string) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	editions, err := s.editions(func(edition *models.EditionUpdate) bool {
+		return editionMatches(edition, id, editionID, state)
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(editions) == 0 {
+		return errs.ErrEditionNotFound
+	}
+
+	return nil
+}
+
+// AddTableLinks appends the links to a table onto the dataset, edition and version of the ftb-blob it was created
+// from, unless they are already linked, so that the links can be added again. The current sub-documents of the
+// dataset and edition are only updated once they have been published.
+func (s *Store) AddTableLinks(ctx context.Context, datasetID, editionID, instanceID string, links *models.TableLinks) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	i := s.findIndex(datasetsCollection, func(doc bson.M) bool { return doc["_id"] == datasetID })
+	if i < 0 {
+		return errs.ErrDatasetNotFound
+	}
+
+	if err := addTableToSet(s.documents[datasetsCollection][i], links.Dataset); err != nil {
+		return err
+	}
+
+	i = s.findIndex(editionsCollection, func(doc bson.M) bool {
+		return lookup(doc, "next.edition") == editionID && lookup(doc, "next.links.dataset.id") == datasetID
+	})
+	if i < 0 {
+		return errs.ErrEditionNotFound
+	}
+
+	if err := addTableToSet(s.documents[editionsCollection][i], links.Edition); err != nil {
+		return err
+	}
+
+	i = s.findIndex(instancesCollection, func(doc bson.M) bool { return doc["id"] == instanceID })
+	if i < 0 {
+		return errs.ErrVersionNotFound
+	}
+
+	doc := s.documents[instancesCollection][i]
+	doc["last_updated"] = time.Now()
+
+	return addToSet(doc, "tables", links.Version)
+}
+
+// addTableToSet appends a table to the next, and if published the current, sub-document of a dataset or edition,
+// unless it already links to the table
+func addTableToSet(doc bson.M, table models.Table) error {
+	if err := addToSet(doc, "next.tables", table); err != nil {
+		return err
+	}
+
+	if _, ok := doc["current"].(bson.M); ok {
+		return addToSet(doc, "current.tables", table)
+	}
+
+	return nil
+}
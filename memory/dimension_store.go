@@ -0,0 +1,211 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// GetDimensionsFromInstance returns a list of dimensions and their options for an instance resource
+func (s *Store) GetDimensionsFromInstance(ctx context.Context, id string) (*models.DimensionNodeResults, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	dimensions, err := s.dimensionOptions(func(option *models.DimensionOption) bool { return option.InstanceID == id })
+	if err != nil {
+		return nil, err
+	}
+
+	// The instance id and last updated fields are not selected by the mongo store
+	for i := range dimensions {
+		dimensions[i].InstanceID = ""
+		dimensions[i].LastUpdated = time.Time{}
+	}
+
+	return &models.DimensionNodeResults{Items: dimensions}, nil
+}
+
+// GetUniqueDimensionAndOptions returns a list of dimension options for an instance resource
+func (s *Store) GetUniqueDimensionAndOptions(ctx context.Context, id, dimension string) (*models.DimensionValues, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	options, err := s.dimensionOptions(func(option *models.DimensionOption) bool {
+		return option.InstanceID == id && option.Name == dimension
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var values []string
+	seen := make(map[string]bool)
+	for _, option := range options {
+		if !seen[option.Option] {
+			seen[option.Option] = true
+			values = append(values, option.Option)
+		}
+	}
+
+	if len(values) == 0 {
+		return nil, errs.ErrDimensionNodeNotFound
+	}
+
+	return &models.DimensionValues{Name: dimension, Options: values}, nil
+}
+
+// GetDimensions returns the first option of each dimension of a version, as grouped by the aggregation of the
+// mongo store
+func (s *Store) GetDimensions(ctx context.Context, datasetID, versionID string) ([]models.DimensionOption, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	seen := make(map[string]bool)
+	results, err := s.dimensionOptions(func(option *models.DimensionOption) bool {
+		if option.InstanceID != versionID || seen[option.Name] {
+			return false
+		}
+		seen[option.Name] = true
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) < 1 {
+		return nil, errs.ErrDimensionsNotFound
+	}
+
+	return results, nil
+}
+
+// GetDimensionOptions returns a page of dimension options for a dimension within a dataset, along with the
+// total number of options for that dimension.
+func (s *Store) GetDimensionOptions(ctx context.Context, version *models.Version, dimension string, offset, limit int) (*models.DimensionOptionResults, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	options, err := s.publicDimensionOptions(version, dimension, func(option *models.DimensionOption) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+
+	values := []models.PublicDimensionOption{}
+	if limit > 0 {
+		start, end := pageBounds(len(options), offset, limit)
+		values = append(values, options[start:end]...)
+	}
+
+	return &models.DimensionOptionResults{
+		Count:      len(values),
+		Items:      values,
+		Limit:      limit,
+		Offset:     offset,
+		TotalCount: len(options),
+	}, nil
+}
+
+// GetDimensionOptionsFromIDs returns the dimension options for a dimension within a dataset that match the
+// provided list of option IDs. The options are returned as a single page, so the limit is the number of IDs
+// requested and the total count is the number of options found, which is fewer than the limit for IDs that do not
+// exist.
+func (s *Store) GetDimensionOptionsFromIDs(ctx context.Context, version *models.Version, dimension string, ids []string) (*models.DimensionOptionResults, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	requested := make(map[string]bool)
+	for _, id := range ids {
+		requested[id] = true
+	}
+
+	values, err := s.publicDimensionOptions(version, dimension, func(option *models.DimensionOption) bool {
+		return requested[option.Option]
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.DimensionOptionResults{
+		Count:      len(values),
+		Items:      values,
+		Limit:      len(ids),
+		Offset:     0,
+		TotalCount: len(values),
+	}, nil
+}
+
+// publicDimensionOptions returns the options of a dimension within a version accepted by the match function,
+// sorted by option and linked to the version. The caller must hold the store lock.
+func (s *Store) publicDimensionOptions(version *models.Version, dimension string, match func(option *models.DimensionOption) bool) ([]models.PublicDimensionOption, error) {
+	options, err := s.dimensionOptions(func(option *models.DimensionOption) bool {
+		return option.InstanceID == version.ID && option.Name == dimension && match(option)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Sort by option so that pages are stable between requests
+	sort.SliceStable(options, func(i, j int) bool { return options[i].Option < options[j].Option })
+
+	values := make([]models.PublicDimensionOption, len(options))
+	for i, option := range options {
+		values[i] = models.PublicDimensionOption{
+			Label:       option.Label,
+			LastUpdated: option.LastUpdated,
+			Links:       option.Links,
+			Name:        option.Name,
+			Option:      option.Option,
+		}
+
+		// options of a version that does not link to itself are left unlinked
+		if version.Links != nil && version.Links.Self != nil {
+			values[i].Links.Version = *version.Links.Self
+		}
+	}
+
+	return values, nil
+}
+
+// dimensionOptions returns every dimension option document accepted by the match function. The caller must hold
+// the store lock.
+func (s *Store) dimensionOptions(match func(option *models.DimensionOption) bool) ([]models.DimensionOption, error) {
+	var results []models.DimensionOption
+	for _, doc := range s.documents[dimensionOptionsCollection] {
+		var option models.DimensionOption
+		if err := decode(doc, &option); err != nil {
+			return nil, err
+		}
+
+		if match(&option) {
+			results = append(results, option)
+		}
+	}
+
+	return results, nil
+}
+
+// AddDimensionOptions upserts a set of dimension options, replacing any option of the same instance, dimension
+// and name
+func (s *Store) AddDimensionOptions(ctx context.Context, options []*models.DimensionOption) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	lastUpdated := time.Now().UTC()
+	for _, option := range options {
+		option.LastUpdated = lastUpdated
+
+		doc, err := normalise(option)
+		if err != nil {
+			return err
+		}
+
+		s.replace(dimensionOptionsCollection, doc.(bson.M), func(existing bson.M) bool {
+			return existing["instance_id"] == option.InstanceID && existing["name"] == option.Name && existing["option"] == option.Option
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// GetInstances returns the instances in any of the given states and datasets, most recently added first
+func (s *Store) GetInstances(ctx context.Context, states []string, datasets []string) (*models.InstanceResults, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	docs := s.documents[instancesCollection]
+
+	results := []models.Instance{}
+	for i := len(docs) - 1; i >= 0; i-- {
+		var instance models.Instance
+		if err := decode(docs[i], &instance); err != nil {
+			return nil, err
+		}
+
+		if len(states) > 0 && !contains(states, instance.State) {
+			continue
+		}
+
+		if len(datasets) > 0 {
+			if instance.Links == nil || instance.Links.Dataset == nil || !contains(datasets, instance.Links.Dataset.ID) {
+				continue
+			}
+		}
+
+		results = append(results, instance)
+	}
+
+	return &models.InstanceResults{Items: results}, nil
+}
+
+// GetInstance returns a single instance from an ID
+func (s *Store) GetInstance(ctx context.Context, ID string) (*models.Instance, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	i := s.findIndex(instancesCollection, func(doc bson.M) bool { return doc["id"] == ID })
+	if i < 0 {
+		return nil, errs.ErrInstanceNotFound
+	}
+
+	var instance models.Instance
+	if err := decode(s.documents[instancesCollection][i], &instance); err != nil {
+		return nil, err
+	}
+
+	return &instance, nil
+}
+
+// contains checks whether a value is in a list of values
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AddVersion upserts a version document into the instances collection, replacing any version with the same id
+func (s *Store) AddVersion(ctx context.Context, version *models.Version) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	version.LastUpdated = time.Now().UTC()
+
+	doc, err := normalise(version)
+	if err != nil {
+		return err
+	}
+
+	s.replace(instancesCollection, doc.(bson.M), func(existing bson.M) bool { return existing["id"] == version.ID })
+	return nil
+}
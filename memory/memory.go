@@ -0,0 +1,206 @@
+package memory
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	datasetsCollection         = "datasets"
+	dimensionOptionsCollection = "dimension.options"
+	editionsCollection         = "editions"
+	instancesCollection        = "instances"
+)
+
+// collections lists every collection that can be loaded from a fixture directory
+var collections = []string{
+	datasetsCollection,
+	dimensionOptionsCollection,
+	editionsCollection,
+	instancesCollection,
+}
+
+// check that Store satisfies the store.Storer interface
+var _ store.Storer = (*Store)(nil)
+
+// Store is an in-memory replacement for the mongo store, used to run the API without a database.
+// Documents are held in their bson form so that they match what the mongo store would return.
+type Store struct {
+	mutex     sync.RWMutex
+	documents map[string][]bson.M
+}
+
+// New creates an empty in-memory store
+func New() *Store {
+	return &Store{
+		documents: make(map[string][]bson.M),
+	}
+}
+
+// Load reads a JSON array of documents for each collection from the fixture directory, where each file is named
+// after its collection e.g. datasets.json or dimension.options.json. Files use the same field names as the mongo
+// documents (including mongo extended JSON such as {"$date": ...}), so a collection exported with mongoexport
+// --jsonArray can be used as is. Missing files leave that collection empty.
+func (s *Store) Load(dir string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, collection := range collections {
+		path := filepath.Join(dir, collection+".json")
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+
+		// extended JSON can only be read as a document, so the array is read as a field of one
+		var fixture struct {
+			Docs []bson.M `bson:"docs"`
+		}
+		b = append(append([]byte(`{"docs":`), b...), '}')
+		if err = bson.UnmarshalExtJSON(b, false, &fixture); err != nil {
+			return fmt.Errorf("failed to parse fixture file %s: %v", path, err)
+		}
+
+		for _, doc := range fixture.Docs {
+			normalised, err := normalise(doc)
+			if err != nil {
+				return fmt.Errorf("failed to parse fixture file %s: %v", path, err)
+			}
+			s.documents[collection] = append(s.documents[collection], normalised.(bson.M))
+		}
+	}
+
+	return nil
+}
+
+// findIndex returns the position of the first document in a collection that the match function accepts, or -1.
+// The caller must hold the store lock.
+func (s *Store) findIndex(collection string, match func(doc bson.M) bool) int {
+	for i, doc := range s.documents[collection] {
+		if match(doc) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// replace swaps the first document of a collection accepted by the match function for doc, or adds doc if there
+// is none, mirroring a mongo upsert. The caller must hold the store lock.
+func (s *Store) replace(collection string, doc bson.M, match func(existing bson.M) bool) {
+	if i := s.findIndex(collection, match); i >= 0 {
+		s.documents[collection][i] = doc
+		return
+	}
+
+	s.documents[collection] = append(s.documents[collection], doc)
+}
+
+// decode converts a bson document into the provided value using its bson tags
+func decode(doc bson.M, value interface{}) error {
+	b, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	return bson.Unmarshal(b, value)
+}
+
+// normalise converts a value into the form it would take once stored in and read back from mongo,
+// so that structs become bson.M documents and numbers take their stored type
+func normalise(value interface{}) (interface{}, error) {
+	b, err := bson.Marshal(bson.M{"value": value})
+	if err != nil {
+		return nil, err
+	}
+
+	var doc bson.M
+	if err = bson.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+
+	return doc["value"], nil
+}
+
+// set applies the fields of a mongo $set update, where keys may be dotted paths, to a document
+func set(doc bson.M, updates bson.M) error {
+	for path, value := range updates {
+		normalised, err := normalise(value)
+		if err != nil {
+			return err
+		}
+
+		keys := strings.Split(path, ".")
+		parent := doc
+		for _, key := range keys[:len(keys)-1] {
+			child, ok := parent[key].(bson.M)
+			if !ok {
+				child = bson.M{}
+				parent[key] = child
+			}
+			parent = child
+		}
+		parent[keys[len(keys)-1]] = normalised
+	}
+
+	return nil
+}
+
+// addToSet appends a value to the array held at a dotted path within a document unless it already holds an equal
+// value, mirroring a mongo $addToSet update
+func addToSet(doc bson.M, path string, value interface{}) error {
+	normalised, err := normalise(value)
+	if err != nil {
+		return err
+	}
+
+	values, _ := lookup(doc, path).(bson.A)
+	for _, existing := range values {
+		if reflect.DeepEqual(existing, normalised) {
+			return nil
+		}
+	}
+
+	return set(doc, bson.M{path: append(values, normalised)})
+}
+
+// lookup returns the value held at a dotted path within a document, or nil if it is not present
+func lookup(doc bson.M, path string) interface{} {
+	var value interface{} = doc
+	for _, key := range strings.Split(path, ".") {
+		parent, ok := value.(bson.M)
+		if !ok {
+			return nil
+		}
+		value = parent[key]
+	}
+
+	return value
+}
+
+// pageBounds returns the start and end indexes of a page of results, mirroring skip and limit in mongo
+func pageBounds(total, offset, limit int) (int, int) {
+	start := offset
+	if start > total {
+		start = total
+	}
+
+	end := start + limit
+	if end > total {
+		end = total
+	}
+
+	return start, end
+}
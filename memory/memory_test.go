@@ -0,0 +1,255 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store/storertest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const fixturesDir = "../fixtures"
+
+func TestLoad(t *testing.T) {
+
+	Convey("Given a directory of fixtures", t, func() {
+		s := New()
+
+		Convey("When the fixtures are loaded", func() {
+			err := s.Load(fixturesDir)
+
+			Convey("Then every collection is populated", func() {
+				So(err, ShouldBeNil)
+				So(s.documents[datasetsCollection], ShouldHaveLength, 1)
+				So(s.documents[editionsCollection], ShouldHaveLength, 2)
+				So(s.documents[instancesCollection], ShouldHaveLength, 2)
+				So(s.documents[dimensionOptionsCollection], ShouldHaveLength, 5)
+			})
+		})
+	})
+
+	Convey("Given a directory without fixtures", t, func() {
+		s := New()
+
+		Convey("Then the store is left empty", func() {
+			So(s.Load("does-not-exist"), ShouldBeNil)
+
+			results, err := s.GetDatasets(context.Background(), 0, 20, true)
+			So(err, ShouldBeNil)
+			So(results.TotalCount, ShouldEqual, 0)
+		})
+	})
+}
+
+func TestGetDatasets(t *testing.T) {
+
+	Convey("Given a published dataset and a dataset that has not been published", t, func() {
+		s := New()
+		So(s.Load(fixturesDir), ShouldBeNil)
+		So(s.UpsertDataset(context.Background(), "Households", &models.DatasetUpdate{ID: "Households", Next: &models.Dataset{State: models.CreatedState}}), ShouldBeNil)
+
+		Convey("When authorised datasets are requested", func() {
+			results, err := s.GetDatasets(context.Background(), 0, 20, true)
+
+			Convey("Then both datasets are returned", func() {
+				So(err, ShouldBeNil)
+				So(results.TotalCount, ShouldEqual, 2)
+			})
+		})
+
+		Convey("When unauthorised datasets are requested", func() {
+			results, err := s.GetDatasets(context.Background(), 0, 20, false)
+
+			Convey("Then only the published dataset is returned", func() {
+				So(err, ShouldBeNil)
+				So(results.TotalCount, ShouldEqual, 1)
+				So(results.Items[0].ID, ShouldEqual, "People")
+			})
+		})
+	})
+}
+
+func TestGetEditions(t *testing.T) {
+
+	Convey("Given a dataset with a published and an unpublished edition", t, func() {
+		s := New()
+		So(s.Load(fixturesDir), ShouldBeNil)
+
+		Convey("When editions are requested without a state", func() {
+			results, err := s.GetEditions(context.Background(), "People", "", nil)
+
+			Convey("Then every edition is returned from its next document", func() {
+				So(err, ShouldBeNil)
+				So(results.Items, ShouldHaveLength, 2)
+			})
+		})
+
+		Convey("When published editions are requested", func() {
+			results, err := s.GetEditions(context.Background(), "People", models.PublishedState, nil)
+
+			Convey("Then only editions with a published current document are returned", func() {
+				So(err, ShouldBeNil)
+				So(results.Items, ShouldHaveLength, 1)
+				So(results.Items[0].Current.Edition, ShouldEqual, "2011")
+			})
+		})
+
+		Convey("When the unpublished edition is requested as published", func() {
+			_, err := s.GetEdition(context.Background(), "People", "2021", models.PublishedState)
+
+			Convey("Then the edition is not found", func() {
+				So(err, ShouldEqual, errs.ErrEditionNotFound)
+			})
+		})
+	})
+}
+
+func TestGetVersions(t *testing.T) {
+
+	Convey("Given an edition with a published and a completed version", t, func() {
+		s := New()
+		So(s.Load(fixturesDir), ShouldBeNil)
+
+		Convey("When versions are requested without a state", func() {
+			results, err := s.GetVersions(context.Background(), "People", "2011", "", nil)
+
+			Convey("Then versions that have not been confirmed against an edition are excluded", func() {
+				So(err, ShouldBeNil)
+				So(results.Items, ShouldHaveLength, 1)
+				So(results.Items[0].Version, ShouldEqual, 1)
+				So(results.Items[0].Links.Self.HRef, ShouldEqual, results.Items[0].Links.Version.HRef)
+			})
+		})
+
+		Convey("When the completed version is requested as published", func() {
+			_, err := s.GetVersion(context.Background(), "People", "2011", "2", models.PublishedState)
+
+			Convey("Then the version is not found", func() {
+				So(err, ShouldEqual, errs.ErrVersionNotFound)
+			})
+		})
+
+		Convey("When the next version number is requested", func() {
+			next, err := s.GetNextVersion(context.Background(), "People", "2011")
+
+			Convey("Then it follows the latest version", func() {
+				So(err, ShouldBeNil)
+				So(next, ShouldEqual, 3)
+			})
+		})
+	})
+}
+
+func TestUpdateDataset(t *testing.T) {
+
+	Convey("Given a published dataset", t, func() {
+		s := New()
+		So(s.Load(fixturesDir), ShouldBeNil)
+
+		Convey("When the dataset is updated", func() {
+			err := s.UpdateDataset(context.Background(), "People", &models.Dataset{Title: "All people"}, models.PublishedState)
+
+			Convey("Then only the next document changes and it becomes a new unpublished revision", func() {
+				So(err, ShouldBeNil)
+
+				dataset, err := s.GetDataset(context.Background(), "People")
+				So(err, ShouldBeNil)
+				So(dataset.Next.Title, ShouldEqual, "All people")
+				So(dataset.Next.State, ShouldEqual, models.CreatedState)
+				So(dataset.Next.Description, ShouldEqual, "Census 2011 population counts")
+				So(dataset.Current.Title, ShouldEqual, "People")
+			})
+		})
+
+		Convey("When a dataset that does not exist is updated", func() {
+			err := s.UpdateDataset(context.Background(), "Households", &models.Dataset{Title: "Households"}, "")
+
+			Convey("Then the dataset is not found", func() {
+				So(err, ShouldEqual, errs.ErrDatasetNotFound)
+			})
+		})
+	})
+}
+
+func TestGetDimensionOptions(t *testing.T) {
+
+	Convey("Given a version with dimension options", t, func() {
+		s := New()
+		So(s.Load(fixturesDir), ShouldBeNil)
+
+		version, err := s.GetVersion(context.Background(), "People", "2011", "1", models.PublishedState)
+		So(err, ShouldBeNil)
+		version.Links.Self = version.Links.Version
+
+		Convey("When a page of options is requested", func() {
+			results, err := s.GetDimensionOptions(context.Background(), version, "AGE", 1, 1)
+
+			Convey("Then the page is taken from the options sorted by option", func() {
+				So(err, ShouldBeNil)
+				So(results.TotalCount, ShouldEqual, 3)
+				So(results.Items, ShouldHaveLength, 1)
+				So(results.Items[0].Option, ShouldEqual, "16-64")
+			})
+		})
+
+		Convey("When options are requested by id", func() {
+			results, err := s.GetDimensionOptionsFromIDs(context.Background(), version, "SEX", []string{"2", "3"})
+
+			Convey("Then only the options that exist are returned", func() {
+				So(err, ShouldBeNil)
+				So(results.Items, ShouldHaveLength, 1)
+				So(results.Items[0].Label, ShouldEqual, "Female")
+			})
+		})
+	})
+}
+
+func TestAddTableLinks(t *testing.T) {
+
+	Convey("Given a published ftb-blob", t, func() {
+		s := New()
+		So(s.Load(fixturesDir), ShouldBeNil)
+
+		links := &models.TableLinks{
+			Dataset: models.Table{HRef: "http://localhost:10400/datasets/people-by-sex", Title: "People by sex"},
+			Edition: models.Table{HRef: "http://localhost:10400/datasets/people-by-sex/editions/2011", Title: "People by sex"},
+			Version: models.Table{HRef: "http://localhost:10400/datasets/people-by-sex/editions/2011/versions/1", Title: "People by sex"},
+		}
+
+		Convey("When the links to a table are added", func() {
+			err := s.AddTableLinks(context.Background(), "People", "2011", "c9b2f1a4-7d3e-4f60-8b21-6a5e4d3c2b10", links)
+
+			Convey("Then the table is appended to the current and next dataset, edition and version", func() {
+				So(err, ShouldBeNil)
+
+				dataset, err := s.GetDataset(context.Background(), "People")
+				So(err, ShouldBeNil)
+				So(*dataset.Current.Tables, ShouldResemble, []models.Table{links.Dataset})
+				So(*dataset.Next.Tables, ShouldResemble, []models.Table{links.Dataset})
+
+				edition, err := s.GetEdition(context.Background(), "People", "2011", models.PublishedState)
+				So(err, ShouldBeNil)
+				So(*edition.Current.Tables, ShouldResemble, []models.Table{links.Edition})
+
+				version, err := s.GetVersion(context.Background(), "People", "2011", "1", "")
+				So(err, ShouldBeNil)
+				So(*version.Tables, ShouldResemble, []models.Table{links.Version})
+			})
+		})
+
+		Convey("When the links to a table are added to an edition that does not exist", func() {
+			err := s.AddTableLinks(context.Background(), "People", "2031", "c9b2f1a4-7d3e-4f60-8b21-6a5e4d3c2b10", links)
+
+			Convey("Then edition not found is returned", func() {
+				So(err, ShouldEqual, errs.ErrEditionNotFound)
+			})
+		})
+	})
+}
+
+func TestStorer(t *testing.T) {
+	storertest.Run(t, func() store.Storer { return New() })
+}
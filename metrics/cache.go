@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/cache"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CacheStatter is implemented by cache.Store, whose Stats returns its number of entries and the hits and misses of
+// each cached method
+type CacheStatter interface {
+	Stats() cache.Stats
+}
+
+// RegisterCache adds counters of the hits and misses of each method of a cache, and a gauge of its number of
+// entries
+func (m *Metrics) RegisterCache(statter CacheStatter) error {
+	return m.registry.Register(&cacheCollector{
+		statter: statter,
+		hits: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cache", "hits_total"),
+			"Number of store calls answered from the cache, by method.",
+			[]string{"method"}, nil,
+		),
+		misses: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cache", "misses_total"),
+			"Number of store calls that were not found in the cache, by method.",
+			[]string{"method"}, nil,
+		),
+		entries: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "cache", "entries"),
+			"Number of entries held in the cache, including those that have expired but not yet been evicted.",
+			nil, nil,
+		),
+	})
+}
+
+// cacheCollector collects the stats of a cache
+type cacheCollector struct {
+	statter CacheStatter
+	hits    *prometheus.Desc
+	misses  *prometheus.Desc
+	entries *prometheus.Desc
+}
+
+// Describe implements prometheus.Collector
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.entries
+}
+
+// Collect implements prometheus.Collector
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.statter.Stats()
+
+	for method, methodStats := range stats.Methods {
+		ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(methodStats.Hits), method)
+		ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(methodStats.Misses), method)
+	}
+	ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, float64(stats.Entries))
+}
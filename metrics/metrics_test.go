@@ -0,0 +1,160 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/cache"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	storetest "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store/datastoretest"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// scrape returns the body of the /metrics endpoint
+func scrape(m *Metrics) string {
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	So(w.Code, ShouldEqual, http.StatusOK)
+	return w.Body.String()
+}
+
+func TestMiddleware(t *testing.T) {
+
+	Convey("Given a router with the metrics middleware", t, func() {
+		m := New()
+		router := mux.NewRouter()
+		router.Use(m.Middleware)
+		router.HandleFunc("/datasets/{dataset_id}", func(w http.ResponseWriter, r *http.Request) {
+			if mux.Vars(r)["dataset_id"] == "missing" {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+		router.NotFoundHandler = m.Middleware(http.NotFoundHandler())
+
+		Convey("When requests are served", func() {
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/datasets/People", nil))
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/datasets/Households", nil))
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/datasets/missing", nil))
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/nope", nil))
+
+			Convey("Then they are counted by route template and status", func() {
+				So(testutil.ToFloat64(m.requests.WithLabelValues("GET", "/datasets/{dataset_id}", "200")), ShouldEqual, 2)
+				So(testutil.ToFloat64(m.requests.WithLabelValues("GET", "/datasets/{dataset_id}", "404")), ShouldEqual, 1)
+				So(testutil.ToFloat64(m.requests.WithLabelValues("GET", unmatchedRoute, "404")), ShouldEqual, 1)
+			})
+
+			Convey("Then their durations are exposed in the Prometheus text format", func() {
+				body := scrape(m)
+				So(body, ShouldContainSubstring, `ftb_dataset_api_http_request_duration_seconds_count{method="GET",route="/datasets/{dataset_id}",status="200"} 2`)
+				So(body, ShouldContainSubstring, "go_goroutines")
+			})
+		})
+	})
+}
+
+func TestInstrumentStorer(t *testing.T) {
+
+	Convey("Given a store instrumented with metrics", t, func() {
+		m := New()
+		mockedDataStore := &storetest.StorerMock{
+			GetDatasetFunc: func(ctx context.Context, ID string) (*models.DatasetUpdate, error) {
+				if ID == "missing" {
+					return nil, errs.ErrDatasetNotFound
+				}
+				return &models.DatasetUpdate{ID: ID}, nil
+			},
+		}
+		storer := m.InstrumentStorer(mockedDataStore)
+
+		Convey("When its methods are called", func() {
+			dataset, err := storer.GetDataset(context.Background(), "People")
+			So(err, ShouldBeNil)
+			So(dataset.ID, ShouldEqual, "People")
+
+			_, err = storer.GetDataset(context.Background(), "missing")
+			So(err, ShouldEqual, errs.ErrDatasetNotFound)
+
+			Convey("Then the calls are passed to the store and timed by method and result", func() {
+				So(mockedDataStore.GetDatasetCalls(), ShouldHaveLength, 2)
+
+				body := scrape(m)
+				So(body, ShouldContainSubstring, `ftb_dataset_api_store_operation_duration_seconds_count{method="GetDataset",result="success"} 1`)
+				So(body, ShouldContainSubstring, `ftb_dataset_api_store_operation_duration_seconds_count{method="GetDataset",result="error"} 1`)
+			})
+		})
+	})
+}
+
+type pingerFunc func(ctx context.Context) (time.Time, error)
+
+func (f pingerFunc) Ping(ctx context.Context) (time.Time, error) {
+	return f(ctx)
+}
+
+func TestRegisterMongo(t *testing.T) {
+
+	Convey("Given metrics with mongo registered", t, func() {
+		m := New()
+		pingTime := time.Unix(1600000000, 0)
+		var pingErr error
+		var hasDeadline bool
+
+		// metrics are collected on other goroutines, so the ping only records what it was given
+		So(m.RegisterMongo(pingerFunc(func(ctx context.Context) (time.Time, error) {
+			_, hasDeadline = ctx.Deadline()
+			return pingTime, pingErr
+		}), time.Second), ShouldBeNil)
+
+		Convey("When mongo answers its ping", func() {
+			body := scrape(m)
+
+			Convey("Then it is reported as up, with the time of the ping", func() {
+				So(hasDeadline, ShouldBeTrue)
+				So(body, ShouldContainSubstring, "ftb_dataset_api_mongo_up 1\n")
+				So(body, ShouldContainSubstring, "ftb_dataset_api_mongo_last_ping_timestamp_seconds 1.6e+09\n")
+			})
+		})
+
+		Convey("When the ping of mongo fails", func() {
+			pingErr = errors.New("no reachable servers")
+			body := scrape(m)
+
+			Convey("Then it is reported as down", func() {
+				So(body, ShouldContainSubstring, "ftb_dataset_api_mongo_up 0\n")
+			})
+		})
+	})
+}
+
+type statterFunc func() cache.Stats
+
+func (f statterFunc) Stats() cache.Stats {
+	return f()
+}
+
+func TestRegisterCache(t *testing.T) {
+
+	Convey("Given metrics with a cache registered", t, func() {
+		m := New()
+		So(m.RegisterCache(statterFunc(func() cache.Stats {
+			return cache.Stats{Entries: 3, Methods: map[string]cache.MethodStats{"GetVersion": {Hits: 5, Misses: 2}}}
+		})), ShouldBeNil)
+
+		Convey("When the metrics are scraped", func() {
+			body := scrape(m)
+
+			Convey("Then the hits, misses and entries of the cache are reported", func() {
+				So(body, ShouldContainSubstring, `ftb_dataset_api_cache_hits_total{method="GetVersion"} 5`)
+				So(body, ShouldContainSubstring, `ftb_dataset_api_cache_misses_total{method="GetVersion"} 2`)
+				So(body, ShouldContainSubstring, "ftb_dataset_api_cache_entries 3")
+			})
+		})
+	})
+}
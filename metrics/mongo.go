@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Pinger is implemented by mongo.Mongo, whose Ping returns the time of the last ping and its result
+type Pinger interface {
+	Ping(ctx context.Context) (time.Time, error)
+}
+
+// RegisterMongo adds gauges reporting whether mongo answered its last ping and when that ping was made. Mongo is
+// pinged as the metrics are scraped, within timeout.
+func (m *Metrics) RegisterMongo(pinger Pinger, timeout time.Duration) error {
+	return m.registry.Register(&pingCollector{
+		pinger:  pinger,
+		timeout: timeout,
+		up: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "mongo", "up"),
+			"Whether the last ping of mongo succeeded (1) or failed (0).",
+			nil, nil,
+		),
+		lastPing: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "mongo", "last_ping_timestamp_seconds"),
+			"Time of the last ping of mongo, in seconds since the epoch.",
+			nil, nil,
+		),
+	})
+}
+
+// pingCollector collects the ping gauges of mongo
+type pingCollector struct {
+	pinger   Pinger
+	timeout  time.Duration
+	up       *prometheus.Desc
+	lastPing *prometheus.Desc
+}
+
+// Describe implements prometheus.Collector
+func (c *pingCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.up
+	ch <- c.lastPing
+}
+
+// Collect implements prometheus.Collector
+func (c *pingCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	up := 1.0
+	pingTime, err := c.pinger.Ping(ctx)
+	if err != nil {
+		up = 0
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, up)
+	ch <- prometheus.MustNewConstMetric(c.lastPing, prometheus.GaugeValue, float64(pingTime.UnixNano())/1e9)
+}
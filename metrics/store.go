@@ -0,0 +1,195 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// check that instrumentedStorer satisfies the store.Storer interface
+var _ store.Storer = (*instrumentedStorer)(nil)
+
+// InstrumentStorer returns a store.Storer that times every call to storer
+func (m *Metrics) InstrumentStorer(storer store.Storer) store.Storer {
+	return &instrumentedStorer{storer: storer, duration: m.storeDuration}
+}
+
+// instrumentedStorer decorates a store.Storer, observing the duration and result of each call by method name
+type instrumentedStorer struct {
+	storer   store.Storer
+	duration *prometheus.HistogramVec
+}
+
+// observe records a call to method that started at start and returned err
+func (s *instrumentedStorer) observe(method string, start time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+
+	s.duration.WithLabelValues(method, result).Observe(time.Since(start).Seconds())
+}
+
+func (s *instrumentedStorer) AddDimensionOptions(ctx context.Context, options []*models.DimensionOption) error {
+	start := time.Now()
+	err := s.storer.AddDimensionOptions(ctx, options)
+	s.observe("AddDimensionOptions", start, err)
+	return err
+}
+
+func (s *instrumentedStorer) AddTableLinks(ctx context.Context, datasetID, editionID, instanceID string, links *models.TableLinks) error {
+	start := time.Now()
+	err := s.storer.AddTableLinks(ctx, datasetID, editionID, instanceID, links)
+	s.observe("AddTableLinks", start, err)
+	return err
+}
+
+func (s *instrumentedStorer) AddVersion(ctx context.Context, version *models.Version) error {
+	start := time.Now()
+	err := s.storer.AddVersion(ctx, version)
+	s.observe("AddVersion", start, err)
+	return err
+}
+
+func (s *instrumentedStorer) CheckDatasetExists(ctx context.Context, ID, state string) error {
+	start := time.Now()
+	err := s.storer.CheckDatasetExists(ctx, ID, state)
+	s.observe("CheckDatasetExists", start, err)
+	return err
+}
+
+func (s *instrumentedStorer) CheckEditionExists(ctx context.Context, ID, editionID, state string) error {
+	start := time.Now()
+	err := s.storer.CheckEditionExists(ctx, ID, editionID, state)
+	s.observe("CheckEditionExists", start, err)
+	return err
+}
+
+func (s *instrumentedStorer) GetDataset(ctx context.Context, ID string) (*models.DatasetUpdate, error) {
+	start := time.Now()
+	result, err := s.storer.GetDataset(ctx, ID)
+	s.observe("GetDataset", start, err)
+	return result, err
+}
+
+func (s *instrumentedStorer) GetDatasets(ctx context.Context, offset, limit int, authorised bool) (*models.DatasetUpdateResults, error) {
+	start := time.Now()
+	result, err := s.storer.GetDatasets(ctx, offset, limit, authorised)
+	s.observe("GetDatasets", start, err)
+	return result, err
+}
+
+func (s *instrumentedStorer) GetDimensionsFromInstance(ctx context.Context, ID string) (*models.DimensionNodeResults, error) {
+	start := time.Now()
+	result, err := s.storer.GetDimensionsFromInstance(ctx, ID)
+	s.observe("GetDimensionsFromInstance", start, err)
+	return result, err
+}
+
+func (s *instrumentedStorer) GetDimensions(ctx context.Context, datasetID, versionID string) ([]models.DimensionOption, error) {
+	start := time.Now()
+	result, err := s.storer.GetDimensions(ctx, datasetID, versionID)
+	s.observe("GetDimensions", start, err)
+	return result, err
+}
+
+func (s *instrumentedStorer) GetDimensionOptions(ctx context.Context, version *models.Version, dimension string, offset, limit int) (*models.DimensionOptionResults, error) {
+	start := time.Now()
+	result, err := s.storer.GetDimensionOptions(ctx, version, dimension, offset, limit)
+	s.observe("GetDimensionOptions", start, err)
+	return result, err
+}
+
+func (s *instrumentedStorer) GetDimensionOptionsFromIDs(ctx context.Context, version *models.Version, dimension string, ids []string) (*models.DimensionOptionResults, error) {
+	start := time.Now()
+	result, err := s.storer.GetDimensionOptionsFromIDs(ctx, version, dimension, ids)
+	s.observe("GetDimensionOptionsFromIDs", start, err)
+	return result, err
+}
+
+func (s *instrumentedStorer) GetEdition(ctx context.Context, ID, editionID, state string) (*models.EditionUpdate, error) {
+	start := time.Now()
+	result, err := s.storer.GetEdition(ctx, ID, editionID, state)
+	s.observe("GetEdition", start, err)
+	return result, err
+}
+
+func (s *instrumentedStorer) GetEditions(ctx context.Context, ID, state string, query *models.EditionsQuery) (*models.EditionUpdateResults, error) {
+	start := time.Now()
+	result, err := s.storer.GetEditions(ctx, ID, state, query)
+	s.observe("GetEditions", start, err)
+	return result, err
+}
+
+func (s *instrumentedStorer) GetInstances(ctx context.Context, states []string, datasets []string) (*models.InstanceResults, error) {
+	start := time.Now()
+	result, err := s.storer.GetInstances(ctx, states, datasets)
+	s.observe("GetInstances", start, err)
+	return result, err
+}
+
+func (s *instrumentedStorer) GetInstance(ctx context.Context, ID string) (*models.Instance, error) {
+	start := time.Now()
+	result, err := s.storer.GetInstance(ctx, ID)
+	s.observe("GetInstance", start, err)
+	return result, err
+}
+
+func (s *instrumentedStorer) GetNextVersion(ctx context.Context, datasetID, editionID string) (int, error) {
+	start := time.Now()
+	result, err := s.storer.GetNextVersion(ctx, datasetID, editionID)
+	s.observe("GetNextVersion", start, err)
+	return result, err
+}
+
+func (s *instrumentedStorer) GetUniqueDimensionAndOptions(ctx context.Context, ID, dimension string) (*models.DimensionValues, error) {
+	start := time.Now()
+	result, err := s.storer.GetUniqueDimensionAndOptions(ctx, ID, dimension)
+	s.observe("GetUniqueDimensionAndOptions", start, err)
+	return result, err
+}
+
+func (s *instrumentedStorer) GetVersion(ctx context.Context, datasetID, editionID, version, state string) (*models.Version, error) {
+	start := time.Now()
+	result, err := s.storer.GetVersion(ctx, datasetID, editionID, version, state)
+	s.observe("GetVersion", start, err)
+	return result, err
+}
+
+func (s *instrumentedStorer) GetVersions(ctx context.Context, datasetID, editionID, state string, query *models.VersionsQuery) (*models.VersionResults, error) {
+	start := time.Now()
+	result, err := s.storer.GetVersions(ctx, datasetID, editionID, state, query)
+	s.observe("GetVersions", start, err)
+	return result, err
+}
+
+func (s *instrumentedStorer) UpdateDataset(ctx context.Context, ID string, dataset *models.Dataset, currentState string) error {
+	start := time.Now()
+	err := s.storer.UpdateDataset(ctx, ID, dataset, currentState)
+	s.observe("UpdateDataset", start, err)
+	return err
+}
+
+func (s *instrumentedStorer) UpdateVersion(ctx context.Context, ID string, version *models.Version) error {
+	start := time.Now()
+	err := s.storer.UpdateVersion(ctx, ID, version)
+	s.observe("UpdateVersion", start, err)
+	return err
+}
+
+func (s *instrumentedStorer) UpsertDataset(ctx context.Context, ID string, datasetDoc *models.DatasetUpdate) error {
+	start := time.Now()
+	err := s.storer.UpsertDataset(ctx, ID, datasetDoc)
+	s.observe("UpsertDataset", start, err)
+	return err
+}
+
+func (s *instrumentedStorer) UpsertEdition(ctx context.Context, datasetID, edition string, editionDoc *models.EditionUpdate) error {
+	start := time.Now()
+	err := s.storer.UpsertEdition(ctx, datasetID, edition, editionDoc)
+	s.observe("UpsertEdition", start, err)
+	return err
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"strings"
+)
+
+// DatasetCSVHeader is the fixed column order used when a list of datasets is returned as CSV
+var DatasetCSVHeader = []string{
+	"id",
+	"title",
+	"description",
+	"state",
+	"ftb_type",
+	"type",
+	"theme",
+	"keywords",
+	"license",
+	"national_statistic",
+	"next_release",
+	"release_frequency",
+	"unit_of_measure",
+	"uri",
+	"href",
+	"latest_version_href",
+}
+
+// DimensionOptionCSVHeader is the fixed column order used when a list of dimension options is returned as CSV
+var DimensionOptionCSVHeader = []string{
+	"dimension",
+	"option",
+	"label",
+	"code",
+	"code_href",
+	"code_list",
+	"code_list_href",
+	"version_href",
+}
+
+// MarshalCSV returns the datasets as CSV, with one row for each dataset
+func (results *DatasetResults) MarshalCSV() ([]byte, error) {
+	rows := make([][]string, 0, len(results.Items))
+	for _, dataset := range results.Items {
+		rows = append(rows, datasetCSVRow(dataset))
+	}
+
+	return marshalCSV(DatasetCSVHeader, rows)
+}
+
+func datasetCSVRow(dataset *Dataset) []string {
+	var nationalStatistic string
+	if dataset.NationalStatistic != nil {
+		nationalStatistic = strconv.FormatBool(*dataset.NationalStatistic)
+	}
+
+	var href, latestVersionHRef string
+	if dataset.Links != nil {
+		if dataset.Links.Self != nil {
+			href = dataset.Links.Self.HRef
+		}
+		if dataset.Links.LatestVersion != nil {
+			latestVersionHRef = dataset.Links.LatestVersion.HRef
+		}
+	}
+
+	return []string{
+		dataset.ID,
+		dataset.Title,
+		dataset.Description,
+		dataset.State,
+		dataset.FTBType,
+		dataset.Type,
+		dataset.Theme,
+		strings.Join(dataset.Keywords, ";"),
+		dataset.License,
+		nationalStatistic,
+		dataset.NextRelease,
+		dataset.ReleaseFrequency,
+		dataset.UnitOfMeasure,
+		dataset.URI,
+		href,
+		latestVersionHRef,
+	}
+}
+
+// MarshalCSV returns the dimension options as CSV, with one row for each option
+func (results *DimensionOptionResults) MarshalCSV() ([]byte, error) {
+	rows := make([][]string, 0, len(results.Items))
+	for _, option := range results.Items {
+		rows = append(rows, []string{
+			option.Name,
+			option.Option,
+			option.Label,
+			option.Links.Code.ID,
+			option.Links.Code.HRef,
+			option.Links.CodeList.ID,
+			option.Links.CodeList.HRef,
+			option.Links.Version.HRef,
+		})
+	}
+
+	return marshalCSV(DimensionOptionCSVHeader, rows)
+}
+
+func marshalCSV(header []string, rows [][]string) ([]byte, error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
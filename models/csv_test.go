@@ -0,0 +1,58 @@
+package models
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDatasetResultsMarshalCSV(t *testing.T) {
+
+	Convey("Given a list of datasets", t, func() {
+		nationalStatistic := true
+		results := &DatasetResults{
+			Items: []*Dataset{
+				{
+					ID:                "people",
+					Title:             "People, \"usual residents\"",
+					Keywords:          []string{"census", "population"},
+					NationalStatistic: &nationalStatistic,
+					Links:             &DatasetLinks{Self: &LinkObject{HRef: "http://localhost:10400/datasets/people"}},
+				},
+				{ID: "households", Title: "Households", State: "created"},
+			},
+		}
+
+		Convey("Then a row is written for each dataset, in the order of the header", func() {
+			b, err := results.MarshalCSV()
+			So(err, ShouldBeNil)
+			So(string(b), ShouldEqual, "id,title,description,state,ftb_type,type,theme,keywords,license,national_statistic,next_release,release_frequency,unit_of_measure,uri,href,latest_version_href\n"+
+				"people,\"People, \"\"usual residents\"\"\",,,,,,census;population,,true,,,,,http://localhost:10400/datasets/people,\n"+
+				"households,Households,,created,,,,,,,,,,,,\n")
+		})
+	})
+}
+
+func TestCreateCSVWMetadata(t *testing.T) {
+
+	Convey("Given the metadata of a version with two dimensions", t, func() {
+		metadata := &Metadata{
+			Dimensions: []Dimension{
+				{Name: "AGE", Label: "Age", HRef: "http://localhost:22400/code-lists/age"},
+				{Name: "SEX"},
+			},
+			Downloads:     &DownloadList{CSV: &DownloadObject{HRef: "http://localhost:23600/downloads/people.csv"}},
+			UnitOfMeasure: "Persons",
+		}
+
+		Convey("Then a column is described for each dimension followed by the observation", func() {
+			csvw := CreateCSVWMetadata(metadata)
+			So(csvw.URL, ShouldEqual, "http://localhost:23600/downloads/people.csv")
+			So(csvw.TableSchema.Columns, ShouldResemble, []CSVWColumn{
+				{Name: "AGE", Titles: "Age", ValueURL: "http://localhost:22400/code-lists/age/codes/{AGE}", Required: true},
+				{Name: "SEX", Titles: "SEX", Required: true},
+				{Name: ObservationColumn, Titles: "Persons", Datatype: "integer", Required: true},
+			})
+		})
+	})
+}
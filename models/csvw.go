@@ -0,0 +1,111 @@
+package models
+
+// CSVWContext is the JSON-LD context of a CSVW metadata document
+const CSVWContext = "http://www.w3.org/ns/csvw"
+
+// CSVW represents a CSV on the Web metadata document describing the CSV download of a version
+type CSVW struct {
+	Context      []interface{}   `json:"@context"`
+	URL          string          `json:"url,omitempty"`
+	Title        string          `json:"dc:title,omitempty"`
+	Description  string          `json:"dc:description,omitempty"`
+	Issued       string          `json:"dc:issued,omitempty"`
+	Publisher    string          `json:"dc:publisher,omitempty"`
+	License      string          `json:"dc:license,omitempty"`
+	Frequency    string          `json:"dc:accrualPeriodicity,omitempty"`
+	Keywords     []string        `json:"dcat:keyword,omitempty"`
+	ContactPoint []CSVWContact   `json:"dcat:contactPoint,omitempty"`
+	TableSchema  CSVWTableSchema `json:"tableSchema"`
+}
+
+// CSVWContact represents a contact for the data described by a CSVW metadata document
+type CSVWContact struct {
+	Email     string `json:"vcard:email,omitempty"`
+	Name      string `json:"vcard:fn,omitempty"`
+	Telephone string `json:"vcard:tel,omitempty"`
+}
+
+// CSVWTableSchema describes the columns of a CSV download
+type CSVWTableSchema struct {
+	Columns []CSVWColumn `json:"columns"`
+}
+
+// CSVWColumn describes a single column of a CSV download
+type CSVWColumn struct {
+	Name        string `json:"name"`
+	Titles      string `json:"titles,omitempty"`
+	Description string `json:"dc:description,omitempty"`
+	Datatype    string `json:"datatype,omitempty"`
+	ValueURL    string `json:"valueUrl,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// ObservationColumn is the name of the final column of a CSV download, holding the observation for each
+// combination of dimension options
+const ObservationColumn = "observation"
+
+// CreateCSVWMetadata creates a CSVW metadata document from the metadata of a version. The table schema has a
+// column for each of the version's dimensions, in order, followed by the observation column.
+func CreateCSVWMetadata(metadata *Metadata) *CSVW {
+	csvw := &CSVW{
+		Context:     []interface{}{CSVWContext, map[string]string{"@language": "en"}},
+		Title:       metadata.Title,
+		Description: metadata.Description,
+		Issued:      metadata.ReleaseDate,
+		License:     metadata.License,
+		Frequency:   metadata.ReleaseFrequency,
+		Keywords:    metadata.Keywords,
+		TableSchema: CSVWTableSchema{Columns: []CSVWColumn{}},
+	}
+
+	if metadata.Downloads != nil && metadata.Downloads.CSV != nil {
+		csvw.URL = metadata.Downloads.CSV.HRef
+	}
+
+	if metadata.Publisher != nil {
+		csvw.Publisher = metadata.Publisher.Name
+	}
+
+	for _, contact := range metadata.Contacts {
+		csvw.ContactPoint = append(csvw.ContactPoint, CSVWContact{
+			Email:     contact.Email,
+			Name:      contact.Name,
+			Telephone: contact.Telephone,
+		})
+	}
+
+	for _, dimension := range metadata.Dimensions {
+		name := dimension.Name
+		if name == "" {
+			name = dimension.ID
+		}
+
+		titles := dimension.Label
+		if titles == "" {
+			titles = name
+		}
+
+		column := CSVWColumn{
+			Name:        name,
+			Titles:      titles,
+			Description: dimension.Description,
+			Required:    true,
+		}
+
+		// Each value in a dimension column is a code from the dimension's code list
+		if dimension.HRef != "" {
+			column.ValueURL = dimension.HRef + "/codes/{" + name + "}"
+		}
+
+		csvw.TableSchema.Columns = append(csvw.TableSchema.Columns, column)
+	}
+
+	csvw.TableSchema.Columns = append(csvw.TableSchema.Columns, CSVWColumn{
+		Name:     ObservationColumn,
+		Titles:   metadata.UnitOfMeasure,
+		Datatype: "integer",
+		Required: true,
+	})
+
+	return csvw
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	neturl "net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
@@ -14,6 +16,8 @@ import (
 	"github.com/ONSdigital/log.go/log"
 	"github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
 )
 
 // List of error variables
@@ -22,17 +26,32 @@ var (
 	ErrPublishedVersionCollectionIDInvalid  = errors.New("unexpected collection_id in published version")
 	ErrVersionStateInvalid                  = errors.New("incorrect state, can be one of the following: edition-confirmed, associated or published")
 	ErrEditionLinksInvalid                  = errors.New("editions links do not exist")
+	ErrVersionReleaseDateInvalid            = errors.New("incorrect release_date, must be an RFC 3339 timestamp or a date")
 )
 
-// DatasetResults represents a structure for a list of datasets
+// A list of FTB data types a dataset, edition or version can hold
+const (
+	FTBBlobType  = "ftb-blob"
+	FTBTableType = "ftb-table"
+)
+
+// DatasetResults represents a structure for a page of datasets
 type DatasetResults struct {
-	Items []*Dataset `json:"items"`
+	Count      int        `json:"count"`
+	Items      []*Dataset `json:"items"`
+	Limit      int        `json:"limit"`
+	Offset     int        `json:"offset"`
+	TotalCount int        `json:"total_count"`
 }
 
 // DatasetUpdateResults represents a structure for a list of evolving dataset
 // with the current dataset and the updated dataset
 type DatasetUpdateResults struct {
-	Items []DatasetUpdate `json:"items"`
+	Count      int             `json:"count"`
+	Items      []DatasetUpdate `json:"items"`
+	Limit      int             `json:"limit"`
+	Offset     int             `json:"offset"`
+	TotalCount int             `json:"total_count"`
 }
 
 // EditionResults represents a structure for a list of editions for a dataset
@@ -51,6 +70,49 @@ type VersionResults struct {
 	Items []Version `json:"items"`
 }
 
+// Orders in which a list of versions can be sorted, where a leading "-" sorts in descending order
+const (
+	VersionSortReleaseDate     = "release_date"
+	VersionSortReleaseDateDesc = "-release_date"
+	VersionSortVersion         = "version"
+	VersionSortVersionDesc     = "-version"
+)
+
+// ReleaseDateLayout is the layout of the release dates of versions, which are compared as strings
+const ReleaseDateLayout = "2006-01-02T15:04:05.000Z"
+
+// ReleaseDateDayLayout is the layout of a release date given without a time, which is the start of the day
+const ReleaseDateDayLayout = "2006-01-02"
+
+// NormaliseReleaseDate converts a release date given as an RFC 3339 timestamp, or as a date for the start of that
+// day, into ReleaseDateLayout in UTC, so that release dates can be compared as they are stored
+func NormaliseReleaseDate(releaseDate string) (string, error) {
+	t, err := time.Parse(ReleaseDateDayLayout, releaseDate)
+	if err != nil {
+		if t, err = time.Parse(time.RFC3339, releaseDate); err != nil {
+			return "", err
+		}
+	}
+
+	return t.UTC().Format(ReleaseDateLayout), nil
+}
+
+// EditionsQuery represents the filters of a list of the editions of a dataset. An empty field does not filter.
+type EditionsQuery struct {
+	State string
+}
+
+// VersionsQuery represents the filters and order of a list of the versions of an edition. An empty field does not
+// filter, and versions are listed in the order they are stored unless sorted. Release dates are inclusive bounds
+// in ReleaseDateLayout.
+type VersionsQuery struct {
+	ReleaseDateFrom string
+	ReleaseDateTo   string
+	Sort            string
+	State           string
+	Version         int
+}
+
 // DatasetUpdate represents an evolving dataset with the current dataset and the updated dataset
 type DatasetUpdate struct {
 	ID      string   `bson:"_id,omitempty"         json:"id,omitempty"`
@@ -58,6 +120,21 @@ type DatasetUpdate struct {
 	Next    *Dataset `bson:"next,omitempty"        json:"next,omitempty"`
 }
 
+// Revision returns the next revision of the dataset when next is true, otherwise the current, published, revision.
+// The id of the document is set on the revision, as the id of each revision is optional.
+func (d *DatasetUpdate) Revision(next bool) *Dataset {
+	revision := d.Current
+	if next {
+		revision = d.Next
+	}
+
+	if revision != nil && revision.ID == "" {
+		revision.ID = d.ID
+	}
+
+	return revision
+}
+
 // Dataset represents information related to a single dataset
 type Dataset struct {
 	CollectionID      string           `bson:"collection_id,omitempty"          json:"collection_id,omitempty"`
@@ -144,6 +221,21 @@ type EditionUpdate struct {
 	Next    *Edition `bson:"next,omitempty"        json:"next,omitempty"`
 }
 
+// Revision returns the next revision of the edition when next is true, otherwise the current, published, revision.
+// The id of the document is set on the revision, as the id of each revision is optional.
+func (e *EditionUpdate) Revision(next bool) *Edition {
+	revision := e.Current
+	if next {
+		revision = e.Next
+	}
+
+	if revision != nil && revision.ID == "" {
+		revision.ID = e.ID
+	}
+
+	return revision
+}
+
 // EditionUpdateLinks represents those links common the both the current and next edition
 type EditionUpdateLinks struct {
 	Dataset       *LinkObject `bson:"dataset,omitempty"        json:"dataset,omitempty"`
@@ -215,9 +307,95 @@ type DownloadObject struct {
 	HRef    string `bson:"href,omitempty"  json:"href,omitempty"`
 	Private string `bson:"private,omitempty" json:"private,omitempty"`
 	Public  string `bson:"public,omitempty" json:"public,omitempty"`
-	// TODO size is in bytes and probably should be an int64 instead of a string this
-	// will have to change for several services (filter API, exporter services and web)
-	Size string `bson:"size,omitempty" json:"size,omitempty"`
+	// Size is in bytes. It is stored as an int64 but remains a string in JSON, as the filter API, exporter services
+	// and web expect of the dataset API
+	Size int64 `bson:"size,omitempty" json:"size,string,omitempty"`
+}
+
+// UnmarshalBSON decodes a download whose size is stored either as a number or, as the dp-dataset-api and documents
+// written before the sizes were migrated hold it, as a string
+func (d *DownloadObject) UnmarshalBSON(b []byte) error {
+	var doc struct {
+		HRef    string        `bson:"href"`
+		Private string        `bson:"private"`
+		Public  string        `bson:"public"`
+		Size    bson.RawValue `bson:"size"`
+	}
+	if err := bson.Unmarshal(b, &doc); err != nil {
+		return err
+	}
+
+	*d = DownloadObject{HRef: doc.HRef, Private: doc.Private, Public: doc.Public}
+
+	switch doc.Size.Type {
+	case 0, bsontype.Null:
+	case bsontype.String:
+		size, err := ParseDownloadSize(doc.Size.StringValue())
+		if err != nil {
+			return fmt.Errorf("invalid download size: %v", err)
+		}
+		d.Size = size
+	case bsontype.Int32:
+		d.Size = int64(doc.Size.Int32())
+	case bsontype.Int64:
+		d.Size = doc.Size.Int64()
+	case bsontype.Double:
+		d.Size = int64(doc.Size.Double())
+	default:
+		return fmt.Errorf("cannot decode %v into a download size", doc.Size.Type)
+	}
+
+	return nil
+}
+
+// UnmarshalJSON decodes a download whose size is sent either as a string, which may be empty, or as a number, as
+// clients of the dp-dataset-api send either
+func (d *DownloadObject) UnmarshalJSON(b []byte) error {
+	var doc struct {
+		HRef    string          `json:"href"`
+		Private string          `json:"private"`
+		Public  string          `json:"public"`
+		Size    json.RawMessage `json:"size"`
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return err
+	}
+
+	*d = DownloadObject{HRef: doc.HRef, Private: doc.Private, Public: doc.Public}
+
+	if len(doc.Size) == 0 || string(doc.Size) == "null" {
+		return nil
+	}
+
+	var size string
+	if doc.Size[0] == '"' {
+		if err := json.Unmarshal(doc.Size, &size); err != nil {
+			return err
+		}
+	} else {
+		var number json.Number
+		if err := json.Unmarshal(doc.Size, &number); err != nil {
+			return fmt.Errorf("cannot decode %s into a download size", doc.Size)
+		}
+		size = number.String()
+	}
+
+	parsed, err := ParseDownloadSize(size)
+	if err != nil {
+		return fmt.Errorf("invalid download size: %v", err)
+	}
+	d.Size = parsed
+
+	return nil
+}
+
+// ParseDownloadSize parses the size of a download held as a string, where an empty size is zero
+func ParseDownloadSize(s string) (int64, error) {
+	if strings.TrimSpace(s) == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseInt(strings.TrimSpace(s), 10, 64)
 }
 
 // LatestChange represents an object contining
@@ -267,6 +445,44 @@ func CreateDataset(reader io.Reader) (*Dataset, error) {
 	return &dataset, nil
 }
 
+// ValidateDataset checks the content of the dataset structure
+func ValidateDataset(dataset *Dataset) error {
+	var invalidFields []string
+
+	switch dataset.FTBType {
+	case "", FTBBlobType, FTBTableType:
+	default:
+		invalidFields = append(invalidFields, "ftb_type")
+	}
+
+	if dataset.URI != "" {
+		if _, err := neturl.ParseRequestURI(dataset.URI); err != nil {
+			invalidFields = append(invalidFields, "uri")
+		}
+	}
+
+	if dataset.QMI != nil && dataset.QMI.HRef != "" {
+		if _, err := neturl.ParseRequestURI(dataset.QMI.HRef); err != nil {
+			invalidFields = append(invalidFields, "qmi.href")
+		}
+	}
+
+	if dataset.IsBasedOn != nil {
+		for _, basedOn := range *dataset.IsBasedOn {
+			if basedOn.ID == "" {
+				invalidFields = append(invalidFields, "is_based_on.@id")
+				break
+			}
+		}
+	}
+
+	if invalidFields != nil {
+		return fmt.Errorf("invalid fields: %v", invalidFields)
+	}
+
+	return nil
+}
+
 // CreateVersion manages the creation of a version from a reader
 func CreateVersion(reader io.Reader) (*Version, error) {
 	b, err := ioutil.ReadAll(reader)
@@ -424,6 +640,161 @@ func (ed *EditionUpdate) PublishLinks(ctx context.Context, host string, versionL
 	return nil
 }
 
+// DatasetUpdateFields returns the fields of the next sub-document of a dataset changed by an update, keyed by
+// their dotted path, for a store to $set. Only the fields provided are changed.
+func DatasetUpdateFields(dataset *Dataset, currentState string) bson.M {
+	updates := make(bson.M)
+
+	if dataset.CollectionID != "" {
+		updates["next.collection_id"] = dataset.CollectionID
+	}
+
+	if dataset.Contacts != nil {
+		updates["next.contacts"] = dataset.Contacts
+	}
+
+	if dataset.Description != "" {
+		updates["next.description"] = dataset.Description
+	}
+
+	if dataset.FTBType != "" {
+		updates["next.ftb_type"] = dataset.FTBType
+	}
+
+	if dataset.IsBasedOn != nil {
+		updates["next.is_based_on"] = dataset.IsBasedOn
+	}
+
+	if dataset.Keywords != nil {
+		updates["next.keywords"] = dataset.Keywords
+	}
+
+	if dataset.License != "" {
+		updates["next.license"] = dataset.License
+	}
+
+	if dataset.Links != nil {
+		if dataset.Links.AccessRights != nil && dataset.Links.AccessRights.HRef != "" {
+			updates["next.links.access_rights.href"] = dataset.Links.AccessRights.HRef
+		}
+
+		if dataset.Links.Taxonomy != nil && dataset.Links.Taxonomy.HRef != "" {
+			updates["next.links.taxonomy.href"] = dataset.Links.Taxonomy.HRef
+		}
+	}
+
+	if dataset.Methodologies != nil {
+		updates["next.methodologies"] = dataset.Methodologies
+	}
+
+	if dataset.NationalStatistic != nil {
+		updates["next.national_statistic"] = dataset.NationalStatistic
+	}
+
+	if dataset.NextRelease != "" {
+		updates["next.next_release"] = dataset.NextRelease
+	}
+
+	if dataset.Publications != nil {
+		updates["next.publications"] = dataset.Publications
+	}
+
+	if dataset.Publisher != nil {
+		updates["next.publisher"] = dataset.Publisher
+	}
+
+	if dataset.QMI != nil {
+		updates["next.qmi"] = dataset.QMI
+	}
+
+	if dataset.RelatedDatasets != nil {
+		updates["next.related_datasets"] = dataset.RelatedDatasets
+	}
+
+	if dataset.ReleaseFrequency != "" {
+		updates["next.release_frequency"] = dataset.ReleaseFrequency
+	}
+
+	// Any change to a published dataset creates a new unpublished revision
+	if currentState == PublishedState {
+		updates["next.state"] = CreatedState
+	}
+
+	if dataset.Tables != nil {
+		updates["next.tables"] = dataset.Tables
+	}
+
+	if dataset.Theme != "" {
+		updates["next.theme"] = dataset.Theme
+	}
+
+	if dataset.Title != "" {
+		updates["next.title"] = dataset.Title
+	}
+
+	if dataset.Type != "" {
+		updates["next.type"] = dataset.Type
+	}
+
+	if dataset.UnitOfMeasure != "" {
+		updates["next.unit_of_measure"] = dataset.UnitOfMeasure
+	}
+
+	if dataset.URI != "" {
+		updates["next.uri"] = dataset.URI
+	}
+
+	updates["next.last_updated"] = time.Now()
+
+	return updates
+}
+
+// VersionUpdateFields returns the fields of a version changed by an update, keyed by their dotted path, for a store
+// to $set. Only the fields provided are changed.
+func VersionUpdateFields(version *Version) bson.M {
+	updates := make(bson.M)
+
+	if version.Alerts != nil {
+		updates["alerts"] = version.Alerts
+	}
+
+	if version.CollectionID != "" {
+		updates["collection_id"] = version.CollectionID
+	}
+
+	if version.Downloads != nil {
+		updates["downloads"] = version.Downloads
+	}
+
+	if version.LatestChanges != nil {
+		updates["latest_changes"] = version.LatestChanges
+	}
+
+	if version.Links != nil && version.Links.Spatial != nil && version.Links.Spatial.HRef != "" {
+		updates["links.spatial.href"] = version.Links.Spatial.HRef
+	}
+
+	if version.ReleaseDate != "" {
+		updates["release_date"] = version.ReleaseDate
+	}
+
+	if version.State != "" {
+		updates["state"] = version.State
+	}
+
+	if version.Temporal != nil {
+		updates["temporal"] = version.Temporal
+	}
+
+	if version.UsageNotes != nil {
+		updates["usage_notes"] = version.UsageNotes
+	}
+
+	updates["last_updated"] = time.Now()
+
+	return updates
+}
+
 // ValidateVersion checks the content of the version structure
 func ValidateVersion(version *Version) error {
 
@@ -448,6 +819,8 @@ func ValidateVersion(version *Version) error {
 
 	if version.ReleaseDate == "" {
 		missingFields = append(missingFields, "release_date")
+	} else if _, err := NormaliseReleaseDate(version.ReleaseDate); err != nil {
+		invalidFields = append(invalidFields, "release_date is not an RFC 3339 timestamp or date")
 	}
 
 	if version.Downloads != nil {
@@ -455,11 +828,11 @@ func ValidateVersion(version *Version) error {
 			if version.Downloads.XLS.HRef == "" {
 				missingFields = append(missingFields, "Downloads.XLS.HRef")
 			}
-			if version.Downloads.XLS.Size == "" {
+			if version.Downloads.XLS.Size == 0 {
 				missingFields = append(missingFields, "Downloads.XLS.Size")
 			}
-			if _, err := strconv.Atoi(version.Downloads.XLS.Size); err != nil {
-				invalidFields = append(invalidFields, "Downloads.XLS.Size not a number")
+			if version.Downloads.XLS.Size < 0 {
+				invalidFields = append(invalidFields, "Downloads.XLS.Size is negative")
 			}
 		}
 
@@ -467,11 +840,11 @@ func ValidateVersion(version *Version) error {
 			if version.Downloads.CSV.HRef == "" {
 				missingFields = append(missingFields, "Downloads.CSV.HRef")
 			}
-			if version.Downloads.CSV.Size == "" {
+			if version.Downloads.CSV.Size == 0 {
 				missingFields = append(missingFields, "Downloads.CSV.Size")
 			}
-			if _, err := strconv.Atoi(version.Downloads.CSV.Size); err != nil {
-				invalidFields = append(invalidFields, "Downloads.CSV.Size not a number")
+			if version.Downloads.CSV.Size < 0 {
+				invalidFields = append(invalidFields, "Downloads.CSV.Size is negative")
 			}
 		}
 
@@ -479,11 +852,11 @@ func ValidateVersion(version *Version) error {
 			if version.Downloads.CSVW.HRef == "" {
 				missingFields = append(missingFields, "Downloads.CSVW.HRef")
 			}
-			if version.Downloads.CSVW.Size == "" {
+			if version.Downloads.CSVW.Size == 0 {
 				missingFields = append(missingFields, "Downloads.CSVW.Size")
 			}
-			if _, err := strconv.Atoi(version.Downloads.CSVW.Size); err != nil {
-				invalidFields = append(invalidFields, "Downloads.CSVW.Size not a number")
+			if version.Downloads.CSVW.Size < 0 {
+				invalidFields = append(invalidFields, "Downloads.CSVW.Size is negative")
 			}
 		}
 	}
@@ -0,0 +1,250 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestParseDownloadSize(t *testing.T) {
+
+	Convey("Given the sizes of downloads held as strings", t, func() {
+		Convey("Then numbers are parsed as int64", func() {
+			size, err := ParseDownloadSize("5000000000")
+			So(err, ShouldBeNil)
+			So(size, ShouldEqual, int64(5000000000))
+
+			size, err = ParseDownloadSize(" 1024 ")
+			So(err, ShouldBeNil)
+			So(size, ShouldEqual, int64(1024))
+		})
+
+		Convey("Then an empty size is zero", func() {
+			size, err := ParseDownloadSize("")
+			So(err, ShouldBeNil)
+			So(size, ShouldEqual, 0)
+		})
+
+		Convey("Then a size that is not a number is an error", func() {
+			_, err := ParseDownloadSize("1kB")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestDownloadObjectUnmarshalBSON(t *testing.T) {
+
+	Convey("Given a version document whose download sizes are stored as strings and numbers", t, func() {
+		b, err := bson.Marshal(bson.M{
+			"id": "instance-1",
+			"downloads": bson.M{
+				"csv":  bson.M{"href": "http://localhost/1.csv", "size": "5000000000"},
+				"csvw": bson.M{"href": "http://localhost/1.csvw", "size": int32(512)},
+				"xls":  bson.M{"href": "http://localhost/1.xls", "size": int64(4096), "public": "http://public/1.xls"},
+			},
+		})
+		So(err, ShouldBeNil)
+
+		Convey("When it is decoded", func() {
+			var version Version
+			err := bson.Unmarshal(b, &version)
+
+			Convey("Then every size is read as a number", func() {
+				So(err, ShouldBeNil)
+				So(version.Downloads.CSV, ShouldResemble, &DownloadObject{HRef: "http://localhost/1.csv", Size: 5000000000})
+				So(version.Downloads.CSVW.Size, ShouldEqual, 512)
+				So(version.Downloads.XLS, ShouldResemble, &DownloadObject{HRef: "http://localhost/1.xls", Public: "http://public/1.xls", Size: 4096})
+			})
+		})
+	})
+
+	Convey("Given a download without a size", t, func() {
+		b, err := bson.Marshal(bson.M{"href": "http://localhost/1.csv"})
+		So(err, ShouldBeNil)
+
+		Convey("Then its size is zero", func() {
+			var download DownloadObject
+			So(bson.Unmarshal(b, &download), ShouldBeNil)
+			So(download, ShouldResemble, DownloadObject{HRef: "http://localhost/1.csv"})
+		})
+	})
+
+	Convey("Given a download whose size is not a number", t, func() {
+		b, err := bson.Marshal(bson.M{"href": "http://localhost/1.csv", "size": "1kB"})
+		So(err, ShouldBeNil)
+
+		Convey("Then it cannot be decoded", func() {
+			var download DownloadObject
+			So(bson.Unmarshal(b, &download), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestDownloadObjectUnmarshalJSON(t *testing.T) {
+
+	Convey("Given downloads whose sizes are sent in each of the forms clients send them", t, func() {
+		decode := func(body string) (*DownloadObject, error) {
+			var download DownloadObject
+			err := json.Unmarshal([]byte(body), &download)
+			return &download, err
+		}
+
+		Convey("Then a numeric string is read as a number", func() {
+			download, err := decode(`{"href": "http://localhost/1.csv", "size": "5000000000"}`)
+			So(err, ShouldBeNil)
+			So(download, ShouldResemble, &DownloadObject{HRef: "http://localhost/1.csv", Size: 5000000000})
+		})
+
+		Convey("Then a number is read as is", func() {
+			download, err := decode(`{"href": "http://localhost/1.csv", "size": 1024, "public": "http://public/1.csv"}`)
+			So(err, ShouldBeNil)
+			So(download, ShouldResemble, &DownloadObject{HRef: "http://localhost/1.csv", Public: "http://public/1.csv", Size: 1024})
+		})
+
+		Convey("Then an empty, null or missing size is zero", func() {
+			for _, body := range []string{`{"href": "http://localhost/1.csv", "size": ""}`, `{"href": "http://localhost/1.csv", "size": null}`, `{"href": "http://localhost/1.csv"}`} {
+				download, err := decode(body)
+				So(err, ShouldBeNil)
+				So(download, ShouldResemble, &DownloadObject{HRef: "http://localhost/1.csv"})
+			}
+		})
+
+		Convey("Then a size that is not a whole number is an error", func() {
+			for _, body := range []string{`{"size": "1kB"}`, `{"size": 1.5}`, `{"size": true}`} {
+				_, err := decode(body)
+				So(err, ShouldNotBeNil)
+			}
+		})
+
+		Convey("Then the size of a version's downloads is still written as a string", func() {
+			version, err := CreateVersion(strings.NewReader(`{"downloads": {"csv": {"href": "http://localhost/1.csv", "size": 512}, "xls": {"size": ""}}}`))
+			So(err, ShouldBeNil)
+			So(version.Downloads.CSV.Size, ShouldEqual, 512)
+			So(version.Downloads.XLS.Size, ShouldEqual, 0)
+
+			b, err := json.Marshal(version.Downloads.CSV)
+			So(err, ShouldBeNil)
+			So(string(b), ShouldEqual, `{"href":"http://localhost/1.csv","size":"512"}`)
+		})
+	})
+}
+
+func TestValidateDataset(t *testing.T) {
+
+	Convey("Given a dataset with valid fields", t, func() {
+		dataset := &Dataset{
+			FTBType:   FTBTableType,
+			URI:       "http://localhost/datasets/People",
+			QMI:       &GeneralDetails{HRef: "http://localhost/qmi"},
+			IsBasedOn: &[]IsBasedOn{{ID: "UR", Type: "CensusTable"}},
+		}
+
+		Convey("Then it is valid", func() {
+			So(ValidateDataset(dataset), ShouldBeNil)
+			So(ValidateDataset(&Dataset{}), ShouldBeNil)
+		})
+
+		Convey("Then an unknown ftb type is invalid", func() {
+			dataset.FTBType = "ftb-cube"
+			So(ValidateDataset(dataset), ShouldResemble, errors.New("invalid fields: [ftb_type]"))
+		})
+
+		Convey("Then every invalid field is reported", func() {
+			dataset.URI = "not a uri"
+			dataset.QMI.HRef = "qmi"
+			dataset.IsBasedOn = &[]IsBasedOn{{Type: "CensusTable"}}
+			So(ValidateDataset(dataset), ShouldResemble, errors.New("invalid fields: [uri qmi.href is_based_on.@id]"))
+		})
+	})
+}
+
+func TestNormaliseReleaseDate(t *testing.T) {
+
+	Convey("Given release dates as timestamps and dates", t, func() {
+		Convey("Then timestamps are converted to UTC in the stored layout", func() {
+			releaseDate, err := NormaliseReleaseDate("2013-01-30T10:30:00+01:00")
+			So(err, ShouldBeNil)
+			So(releaseDate, ShouldEqual, "2013-01-30T09:30:00.000Z")
+		})
+
+		Convey("Then a date is the start of that day", func() {
+			releaseDate, err := NormaliseReleaseDate("2021-03-21")
+			So(err, ShouldBeNil)
+			So(releaseDate, ShouldEqual, "2021-03-21T00:00:00.000Z")
+		})
+
+		Convey("Then a release date already in the stored layout is unchanged", func() {
+			releaseDate, err := NormaliseReleaseDate("2013-01-30T09:30:00.000Z")
+			So(err, ShouldBeNil)
+			So(releaseDate, ShouldEqual, "2013-01-30T09:30:00.000Z")
+		})
+
+		Convey("Then other layouts are an error", func() {
+			_, err := NormaliseReleaseDate("22/03/2012")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestValidateVersionReleaseDate(t *testing.T) {
+
+	Convey("Given a published version", t, func() {
+		version := &Version{State: PublishedState}
+
+		Convey("Then a release date is required", func() {
+			So(ValidateVersion(version), ShouldNotBeNil)
+
+			version.ReleaseDate = "2021-03-21"
+			So(ValidateVersion(version), ShouldBeNil)
+		})
+
+		Convey("Then a release date that is not a timestamp or date is invalid", func() {
+			version.ReleaseDate = "22/03/2012"
+			err := ValidateVersion(version)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldStartWith, "invalid fields:")
+		})
+	})
+}
+
+func TestDatasetUpdateFields(t *testing.T) {
+
+	Convey("Given an update of some fields of a published dataset", t, func() {
+		dataset := &Dataset{
+			Title: "People",
+			Links: &DatasetLinks{Taxonomy: &LinkObject{HRef: "http://localhost/taxonomy"}},
+		}
+
+		Convey("Then only those fields of the next revision are set, which is no longer published", func() {
+			updates := DatasetUpdateFields(dataset, PublishedState)
+			So(updates, ShouldContainKey, "next.last_updated")
+			delete(updates, "next.last_updated")
+			So(updates, ShouldResemble, bson.M{
+				"next.title":               "People",
+				"next.links.taxonomy.href": "http://localhost/taxonomy",
+				"next.state":               CreatedState,
+			})
+		})
+	})
+}
+
+func TestVersionUpdateFields(t *testing.T) {
+
+	Convey("Given an update of the state and release date of a version", t, func() {
+		version := &Version{State: PublishedState, ReleaseDate: "2013-01-30T09:30:00.000Z"}
+
+		Convey("Then only those fields are set", func() {
+			updates := VersionUpdateFields(version)
+			So(updates, ShouldContainKey, "last_updated")
+			delete(updates, "last_updated")
+			So(updates, ShouldResemble, bson.M{
+				"release_date": "2013-01-30T09:30:00.000Z",
+				"state":        PublishedState,
+			})
+		})
+	})
+}
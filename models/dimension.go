@@ -9,7 +9,11 @@ type DatasetDimensionResults struct {
 
 // DimensionOptionResults represents a structure for a list of dimension options
 type DimensionOptionResults struct {
-	Items []PublicDimensionOption `json:"items"`
+	Count      int                     `json:"count"`
+	Items      []PublicDimensionOption `json:"items"`
+	Limit      int                     `json:"limit"`
+	Offset     int                     `json:"offset"`
+	TotalCount int                     `json:"total_count"`
 }
 
 // Dimension represents an overview for a single dimension. This includes a link to the code list API
@@ -57,10 +61,11 @@ type DimensionOption struct {
 
 // PublicDimensionOption hides values which are only used by internal services
 type PublicDimensionOption struct {
-	Label  string               `bson:"label,omitempty"          json:"label"`
-	Links  DimensionOptionLinks `bson:"links,omitempty"          json:"links"`
-	Name   string               `bson:"name,omitempty"           json:"dimension"`
-	Option string               `bson:"option,omitempty"         json:"option"`
+	Label       string               `bson:"label,omitempty"          json:"label"`
+	LastUpdated time.Time            `bson:"last_updated,omitempty"   json:"-"`
+	Links       DimensionOptionLinks `bson:"links,omitempty"          json:"links"`
+	Name        string               `bson:"name,omitempty"           json:"dimension"`
+	Option      string               `bson:"option,omitempty"         json:"option"`
 }
 
 // DimensionOptionLinks represents a list of link objects related to dimension options
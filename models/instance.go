@@ -6,7 +6,7 @@ import (
 
 	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
 
-	"github.com/globalsign/mgo/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // Instance which presents a single dataset being imported
@@ -27,7 +27,7 @@ type Instance struct {
 	State             string               `bson:"state,omitempty"                       json:"state,omitempty"`
 	Temporal          *[]TemporalFrequency `bson:"temporal,omitempty"                    json:"temporal,omitempty"`
 	TotalObservations *int                 `bson:"total_observations,omitempty"          json:"total_observations,omitempty"`
-	UniqueTimestamp   bson.MongoTimestamp  `bson:"unique_timestamp"                      json:"-"`
+	UniqueTimestamp   primitive.Timestamp  `bson:"unique_timestamp"                      json:"-"`
 	Version           int                  `bson:"version,omitempty"                     json:"version,omitempty"`
 }
 
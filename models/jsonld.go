@@ -0,0 +1,169 @@
+package models
+
+import "strconv"
+
+// JSONLDContext maps the prefixes used in a JSON-LD metadata document to their vocabularies
+var JSONLDContext = map[string]string{
+	"dcat":   "http://www.w3.org/ns/dcat#",
+	"dct":    "http://purl.org/dc/terms/",
+	"foaf":   "http://xmlns.com/foaf/0.1/",
+	"schema": "http://schema.org/",
+	"vcard":  "http://www.w3.org/2006/vcard/ns#",
+	"xsd":    "http://www.w3.org/2001/XMLSchema#",
+}
+
+// Media types of each download format, as given to the distributions of a JSON-LD metadata document
+const (
+	csvMediaType  = "text/csv"
+	csvwMediaType = "application/csvm+json"
+	xlsMediaType  = "application/vnd.ms-excel"
+)
+
+// DCATDataset represents a version as a DCAT-AP and schema.org dataset, so that it can be harvested by data
+// catalogues
+type DCATDataset struct {
+	Context            map[string]string  `json:"@context"`
+	ID                 string             `json:"@id,omitempty"`
+	Type               []string           `json:"@type"`
+	Title              string             `json:"dct:title,omitempty"`
+	Description        string             `json:"dct:description,omitempty"`
+	Issued             string             `json:"dct:issued,omitempty"`
+	License            string             `json:"dct:license,omitempty"`
+	AccrualPeriodicity string             `json:"dct:accrualPeriodicity,omitempty"`
+	Temporal           []DCATPeriodOfTime `json:"dct:temporal,omitempty"`
+	Spatial            *JSONLDReference   `json:"dct:spatial,omitempty"`
+	Publisher          *DCATAgent         `json:"dct:publisher,omitempty"`
+	Keywords           []string           `json:"dcat:keyword,omitempty"`
+	Theme              string             `json:"dcat:theme,omitempty"`
+	LandingPage        *JSONLDReference   `json:"dcat:landingPage,omitempty"`
+	ContactPoint       []DCATContact      `json:"dcat:contactPoint,omitempty"`
+	Distribution       []DCATDistribution `json:"dcat:distribution,omitempty"`
+	IsBasedOn          []IsBasedOn        `json:"schema:isBasedOn,omitempty"`
+}
+
+// JSONLDReference represents a link to another resource in a JSON-LD document
+type JSONLDReference struct {
+	ID string `json:"@id"`
+}
+
+// DCATAgent represents the organisation that publishes a dataset
+type DCATAgent struct {
+	ID   string `json:"@id,omitempty"`
+	Type string `json:"@type"`
+	Name string `json:"foaf:name,omitempty"`
+}
+
+// DCATContact represents a contact for a dataset as a vCard
+type DCATContact struct {
+	Type      string `json:"@type"`
+	Name      string `json:"vcard:fn,omitempty"`
+	Email     string `json:"vcard:hasEmail,omitempty"`
+	Telephone string `json:"vcard:hasTelephone,omitempty"`
+}
+
+// DCATPeriodOfTime represents the period of time a dataset covers
+type DCATPeriodOfTime struct {
+	Type      string `json:"@type"`
+	StartDate string `json:"dcat:startDate,omitempty"`
+	EndDate   string `json:"dcat:endDate,omitempty"`
+}
+
+// DCATDistribution represents a downloadable file of a dataset
+type DCATDistribution struct {
+	Type        string           `json:"@type"`
+	Title       string           `json:"dct:title,omitempty"`
+	Format      string           `json:"dct:format,omitempty"`
+	MediaType   string           `json:"dcat:mediaType,omitempty"`
+	DownloadURL *JSONLDReference `json:"dcat:downloadURL,omitempty"`
+	ByteSize    string           `json:"dcat:byteSize,omitempty"`
+}
+
+// CreateDCATMetadata creates a JSON-LD dataset from the metadata of a version, with a distribution for each of
+// the version's downloads
+func CreateDCATMetadata(metadata *Metadata) *DCATDataset {
+	dataset := &DCATDataset{
+		Context:            JSONLDContext,
+		Type:               []string{"dcat:Dataset", "schema:Dataset"},
+		Title:              metadata.Title,
+		Description:        metadata.Description,
+		Issued:             metadata.ReleaseDate,
+		License:            metadata.License,
+		AccrualPeriodicity: metadata.ReleaseFrequency,
+		Keywords:           metadata.Keywords,
+		Theme:              metadata.Theme,
+	}
+
+	if metadata.Links != nil {
+		if metadata.Links.Self != nil {
+			dataset.ID = metadata.Links.Self.HRef
+		}
+		dataset.Spatial = newJSONLDReference(metadata.Links.Spatial)
+		dataset.LandingPage = newJSONLDReference(metadata.Links.WebsiteVersion)
+	}
+
+	if metadata.Publisher != nil {
+		dataset.Publisher = &DCATAgent{
+			ID:   metadata.Publisher.HRef,
+			Type: "foaf:Organization",
+			Name: metadata.Publisher.Name,
+		}
+	}
+
+	for _, contact := range metadata.Contacts {
+		c := DCATContact{Type: "vcard:Kind", Name: contact.Name, Telephone: contact.Telephone}
+		if contact.Email != "" {
+			c.Email = "mailto:" + contact.Email
+		}
+		dataset.ContactPoint = append(dataset.ContactPoint, c)
+	}
+
+	if metadata.Temporal != nil {
+		for _, temporal := range *metadata.Temporal {
+			dataset.Temporal = append(dataset.Temporal, DCATPeriodOfTime{
+				Type:      "dct:PeriodOfTime",
+				StartDate: temporal.StartDate,
+				EndDate:   temporal.EndDate,
+			})
+		}
+	}
+
+	if metadata.IsBasedOn != nil {
+		dataset.IsBasedOn = *metadata.IsBasedOn
+	}
+
+	if metadata.Downloads != nil {
+		dataset.Distribution = appendDistribution(dataset.Distribution, metadata.Downloads.CSV, "CSV", csvMediaType)
+		dataset.Distribution = appendDistribution(dataset.Distribution, metadata.Downloads.CSVW, "CSVW", csvwMediaType)
+		dataset.Distribution = appendDistribution(dataset.Distribution, metadata.Downloads.XLS, "XLS", xlsMediaType)
+	}
+
+	return dataset
+}
+
+func appendDistribution(distribution []DCATDistribution, download *DownloadObject, format, mediaType string) []DCATDistribution {
+	if download == nil || download.HRef == "" {
+		return distribution
+	}
+
+	var byteSize string
+	if download.Size > 0 {
+		byteSize = strconv.FormatInt(download.Size, 10)
+	}
+
+	return append(distribution, DCATDistribution{
+		Type:        "dcat:Distribution",
+		Title:       format + " download",
+		Format:      format,
+		MediaType:   mediaType,
+		DownloadURL: &JSONLDReference{ID: download.HRef},
+		ByteSize:    byteSize,
+	})
+}
+
+func newJSONLDReference(link *LinkObject) *JSONLDReference {
+	if link == nil || link.HRef == "" {
+		return nil
+	}
+
+	return &JSONLDReference{ID: link.HRef}
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCreateDCATMetadata(t *testing.T) {
+
+	Convey("Given the metadata of a version with a CSV and XLS download", t, func() {
+		metadata := &Metadata{
+			Title:     "People",
+			Contacts:  []ContactDetails{{Name: "Census", Email: "census@ons.gov.uk"}},
+			Publisher: &Publisher{HRef: "https://www.ons.gov.uk", Name: "Office for National Statistics"},
+			IsBasedOn: &[]IsBasedOn{{ID: "UR", Type: "ftb"}},
+			Downloads: &DownloadList{
+				CSV: &DownloadObject{HRef: "http://localhost:23600/downloads/people.csv", Size: 1024},
+				XLS: &DownloadObject{HRef: "http://localhost:23600/downloads/people.xls"},
+			},
+			Links: &MetadataLinks{
+				Self:           &LinkObject{HRef: "http://localhost:10400/datasets/People/editions/2011/versions/1/metadata"},
+				WebsiteVersion: &LinkObject{HRef: "http://localhost:20000/datasets/People/editions/2011/versions/1"},
+			},
+		}
+
+		Convey("Then a dataset is created with a distribution for each download", func() {
+			dataset := CreateDCATMetadata(metadata)
+			So(dataset.ID, ShouldEqual, "http://localhost:10400/datasets/People/editions/2011/versions/1/metadata")
+			So(dataset.Type, ShouldResemble, []string{"dcat:Dataset", "schema:Dataset"})
+			So(dataset.LandingPage, ShouldResemble, &JSONLDReference{ID: "http://localhost:20000/datasets/People/editions/2011/versions/1"})
+			So(dataset.Publisher.Type, ShouldEqual, "foaf:Organization")
+			So(dataset.ContactPoint[0].Email, ShouldEqual, "mailto:census@ons.gov.uk")
+			So(dataset.IsBasedOn, ShouldResemble, []IsBasedOn{{ID: "UR", Type: "ftb"}})
+			So(dataset.Distribution, ShouldResemble, []DCATDistribution{
+				{
+					Type:        "dcat:Distribution",
+					Title:       "CSV download",
+					Format:      "CSV",
+					MediaType:   "text/csv",
+					DownloadURL: &JSONLDReference{ID: "http://localhost:23600/downloads/people.csv"},
+					ByteSize:    "1024",
+				},
+				{
+					Type:        "dcat:Distribution",
+					Title:       "XLS download",
+					Format:      "XLS",
+					MediaType:   "application/vnd.ms-excel",
+					DownloadURL: &JSONLDReference{ID: "http://localhost:23600/downloads/people.xls"},
+				},
+			})
+		})
+	})
+}
@@ -0,0 +1,57 @@
+package models
+
+// CrossTabQuery represents a cross-tabulation of a version by the FTB table engine. Observations are broken down
+// by each of the query dimensions, in order, and summed over every dimension of the version not in the query.
+type CrossTabQuery struct {
+	DatasetID  string
+	Edition    string
+	Version    string
+	Dimensions []CrossTabDimension
+}
+
+// CrossTabDimension represents a dimension of a cross-tabulation and the codes it is restricted to. A
+// dimension without codes is broken down by all of its codes.
+type CrossTabDimension struct {
+	Name  string
+	Codes []string
+}
+
+// CrossTab represents the cells returned by the FTB table engine for a cross-tabulation. The codes of each cell
+// are in the order of the dimensions.
+type CrossTab struct {
+	Dimensions []string       `json:"dimensions"`
+	Cells      []CrossTabCell `json:"cells"`
+}
+
+// CrossTabCell represents a single cell of a cross-tabulation
+type CrossTabCell struct {
+	Codes []string `json:"codes"`
+	Value float64  `json:"value"`
+}
+
+// ObservationResults represents the observations returned for a query against a version
+type ObservationResults struct {
+	Count        int               `json:"count"`
+	Dimensions   []string          `json:"dimensions"`
+	Links        *ObservationLinks `json:"links"`
+	Observations []Observation     `json:"observations"`
+}
+
+// ObservationLinks represents a list of link objects related to the observations of a version
+type ObservationLinks struct {
+	Self    *LinkObject `json:"self"`
+	Version *LinkObject `json:"version"`
+}
+
+// Observation represents a single cell value and the dimension option it was returned for in each dimension
+type Observation struct {
+	Dimensions  map[string]*ObservationOption `json:"dimensions"`
+	Observation float64                       `json:"observation"`
+}
+
+// ObservationOption represents the dimension option of an observation
+type ObservationOption struct {
+	HRef  string `json:"href,omitempty"`
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
@@ -0,0 +1,11 @@
+package models
+
+// Problem represents an RFC 7807 problem details body, returned for every unsuccessful request
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
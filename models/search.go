@@ -0,0 +1,244 @@
+package models
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Fields of a dataset that are searched
+const (
+	SearchFieldDescription = "description"
+	SearchFieldDimensions  = "dimensions"
+	SearchFieldKeywords    = "keywords"
+	SearchFieldTables      = "tables"
+	SearchFieldTitle       = "title"
+)
+
+// SearchFieldWeights are the relative weights given to a match in each searched field when ranking results
+var SearchFieldWeights = map[string]int{
+	SearchFieldTitle:       10,
+	SearchFieldKeywords:    5,
+	SearchFieldTables:      3,
+	SearchFieldDimensions:  2,
+	SearchFieldDescription: 1,
+}
+
+var (
+	searchWordPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+	// searchStopWords are common words that are not indexed or searched for
+	searchStopWords = map[string]bool{
+		"a": true, "an": true, "and": true, "by": true, "for": true, "in": true, "of": true, "on": true, "or": true,
+		"the": true, "to": true, "with": true,
+	}
+)
+
+// SearchQuery represents a full-text search of the published datasets
+type SearchQuery struct {
+	Dimension string
+	FTBType   string
+	Limit     int
+	Offset    int
+	Q         string
+}
+
+// SearchResults represents a page of datasets matching a search, ranked by score
+type SearchResults struct {
+	Count      int            `json:"count"`
+	Items      []SearchResult `json:"items"`
+	Limit      int            `json:"limit"`
+	Offset     int            `json:"offset"`
+	TotalCount int            `json:"total_count"`
+}
+
+// SearchResult represents a dataset matching a search, with the query terms highlighted in each field that
+// matched them
+type SearchResult struct {
+	Description string              `json:"description,omitempty"`
+	FTBType     string              `json:"ftb_type,omitempty"`
+	Highlights  map[string][]string `json:"highlights,omitempty"`
+	ID          string              `json:"id"`
+	Links       *SearchResultLinks  `json:"links"`
+	Score       float64             `json:"score"`
+	Title       string              `json:"title,omitempty"`
+}
+
+// SearchResultLinks represents the links to the dataset resources of a search result
+type SearchResultLinks struct {
+	Dataset       *LinkObject `json:"dataset,omitempty"`
+	Editions      *LinkObject `json:"editions,omitempty"`
+	LatestVersion *LinkObject `json:"latest_version,omitempty"`
+}
+
+// SearchDocument represents a published dataset as it is searched, along with the dimensions of its published
+// versions
+type SearchDocument struct {
+	Dataset    *Dataset
+	Dimensions []Dimension
+}
+
+// Fields returns the searchable text of the document by field
+func (d *SearchDocument) Fields() map[string][]string {
+	fields := map[string][]string{
+		SearchFieldTitle:       {d.Dataset.Title},
+		SearchFieldDescription: {d.Dataset.Description},
+		SearchFieldKeywords:    d.Dataset.Keywords,
+	}
+
+	if d.Dataset.Tables != nil {
+		for _, table := range *d.Dataset.Tables {
+			fields[SearchFieldTables] = append(fields[SearchFieldTables], table.Title)
+		}
+	}
+
+	for _, dimension := range d.Dimensions {
+		if dimension.Label != "" {
+			fields[SearchFieldDimensions] = append(fields[SearchFieldDimensions], dimension.Label)
+		} else {
+			fields[SearchFieldDimensions] = append(fields[SearchFieldDimensions], dimension.Name)
+		}
+	}
+
+	return fields
+}
+
+// HasDimension reports whether any published version of the document has a dimension with the given id or name
+func (d *SearchDocument) HasDimension(dimension string) bool {
+	for _, candidate := range d.Dimensions {
+		if strings.EqualFold(candidate.ID, dimension) || strings.EqualFold(candidate.Name, dimension) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AddDimensions adds the dimensions of a published version to the document, skipping those already added
+func (d *SearchDocument) AddDimensions(dimensions []Dimension) {
+	for _, dimension := range dimensions {
+		name := dimension.Name
+		if name == "" {
+			name = dimension.ID
+		}
+
+		if !d.HasDimension(name) {
+			d.Dimensions = append(d.Dimensions, dimension)
+		}
+	}
+}
+
+// NewSearchResult creates the search result for a matching document, highlighting the terms in its fields
+func NewSearchResult(doc *SearchDocument, terms []string, score float64) SearchResult {
+	dataset := doc.Dataset
+	result := SearchResult{
+		Description: dataset.Description,
+		FTBType:     dataset.FTBType,
+		ID:          dataset.ID,
+		Links:       &SearchResultLinks{},
+		Score:       score,
+		Title:       dataset.Title,
+	}
+
+	if dataset.Links != nil {
+		result.Links.Dataset = dataset.Links.Self
+		result.Links.Editions = dataset.Links.Editions
+		result.Links.LatestVersion = dataset.Links.LatestVersion
+	}
+
+	match := make(map[string]bool)
+	for _, term := range terms {
+		match[term] = true
+	}
+
+	for field, values := range doc.Fields() {
+		for _, value := range values {
+			if highlighted, ok := Highlight(value, match); ok {
+				if result.Highlights == nil {
+					result.Highlights = make(map[string][]string)
+				}
+				result.Highlights[field] = append(result.Highlights[field], highlighted)
+			}
+		}
+	}
+
+	return result
+}
+
+// SearchTerms splits text into the lower case terms that are indexed and searched for, without stop words and
+// with plurals reduced to their singular form
+func SearchTerms(text string) []string {
+	var terms []string
+	for _, word := range searchWordPattern.FindAllString(text, -1) {
+		if term := searchTerm(word); term != "" {
+			terms = append(terms, term)
+		}
+	}
+
+	return terms
+}
+
+// Highlight wraps the words of text that match one of the terms in <em> tags, returning false when no words match
+func Highlight(text string, terms map[string]bool) (string, bool) {
+	var b strings.Builder
+	var matched bool
+
+	last := 0
+	for _, loc := range searchWordPattern.FindAllStringIndex(text, -1) {
+		word := text[loc[0]:loc[1]]
+		if !terms[searchTerm(word)] {
+			continue
+		}
+
+		matched = true
+		b.WriteString(text[last:loc[0]])
+		b.WriteString("<em>" + word + "</em>")
+		last = loc[1]
+	}
+	b.WriteString(text[last:])
+
+	return b.String(), matched
+}
+
+// searchTerm normalises a word into a term, returning an empty string for stop words. Stemming is limited to
+// removing the trailing s of a plural (but not of words such as census or analysis), which is enough for the
+// titles and labels of datasets.
+func searchTerm(word string) string {
+	term := strings.ToLower(word)
+	if searchStopWords[term] {
+		return ""
+	}
+
+	if len(term) > 3 && strings.HasSuffix(term, "s") && !strings.HasSuffix(term, "ss") && !strings.HasSuffix(term, "us") &&
+		!strings.HasSuffix(term, "is") {
+		term = strings.TrimSuffix(term, "s")
+	}
+
+	return term
+}
+
+// NewSearchResults ranks the results of a search by score, with ties in order of id, and returns a page of them
+func NewSearchResults(results []SearchResult, offset, limit int) *SearchResults {
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+
+	start, end := offset, offset+limit
+	if start > len(results) {
+		start = len(results)
+	}
+	if end > len(results) {
+		end = len(results)
+	}
+
+	return &SearchResults{
+		Count:      end - start,
+		Items:      append([]SearchResult{}, results[start:end]...),
+		Limit:      limit,
+		Offset:     offset,
+		TotalCount: len(results),
+	}
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSearchTerms(t *testing.T) {
+
+	Convey("Given a search query", t, func() {
+		terms := SearchTerms("Usual residents by SEX, age-groups and the Census")
+
+		Convey("Then it is split into lower case terms without stop words or plurals", func() {
+			So(terms, ShouldResemble, []string{"usual", "resident", "sex", "age", "group", "census"})
+		})
+	})
+}
+
+func TestHighlight(t *testing.T) {
+
+	Convey("Given a set of search terms", t, func() {
+		terms := map[string]bool{"resident": true, "sex": true}
+
+		Convey("When text contains the terms", func() {
+			highlighted, ok := Highlight("Usual residents by sex", terms)
+
+			Convey("Then each matching word is highlighted as written", func() {
+				So(ok, ShouldBeTrue)
+				So(highlighted, ShouldEqual, "Usual <em>residents</em> by <em>sex</em>")
+			})
+		})
+
+		Convey("When text does not contain the terms", func() {
+			highlighted, ok := Highlight("Population by age", terms)
+
+			Convey("Then the text is returned unchanged", func() {
+				So(ok, ShouldBeFalse)
+				So(highlighted, ShouldEqual, "Population by age")
+			})
+		})
+	})
+}
@@ -32,6 +32,55 @@ var validStates = map[string]int{
 	PublishedState:        1,
 }
 
+// versionStatePredecessors maps each version state to the only state a version can move into it from
+var versionStatePredecessors = map[string]string{
+	SubmittedState:        CreatedState,
+	CompletedState:        SubmittedState,
+	EditionConfirmedState: CompletedState,
+	AssociatedState:       EditionConfirmedState,
+	PublishedState:        AssociatedState,
+}
+
+// expectedStateErrors maps a version state to the error returned when a version was expected to be in it
+var expectedStateErrors = map[string]error{
+	CreatedState:          errs.ErrExpectedResourceStateOfCreated,
+	SubmittedState:        errs.ErrExpectedResourceStateOfSubmitted,
+	CompletedState:        errs.ErrExpectedResourceStateOfCompleted,
+	EditionConfirmedState: errs.ErrExpectedResourceStateOfEditionConfirmed,
+	AssociatedState:       errs.ErrExpectedResourceStateOfAssociated,
+}
+
+// ValidateVersionStateTransition checks a version can move from its current state to the requested state.
+// An empty requested state leaves the state unchanged, and a version may be updated without changing state
+// until it has been published.
+func ValidateVersionStateTransition(currentState, requestedState string) error {
+	if currentState == PublishedState {
+		return errs.ErrResourcePublished
+	}
+
+	if requestedState == "" || requestedState == currentState {
+		return nil
+	}
+
+	if requestedState == DetachedState {
+		if currentState != EditionConfirmedState && currentState != AssociatedState {
+			return errs.ErrIncorrectStateToDetach
+		}
+		return nil
+	}
+
+	expectedState, ok := versionStatePredecessors[requestedState]
+	if !ok {
+		return ErrVersionStateInvalid
+	}
+
+	if currentState != expectedState {
+		return expectedStateErrors[expectedState]
+	}
+
+	return nil
+}
+
 // ValidateStateFilter checks the list of filter states from a whitelist
 func ValidateStateFilter(filterList []string) error {
 	var invalidFilterStateValues []string
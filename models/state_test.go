@@ -0,0 +1,55 @@
+package models
+
+import (
+	"testing"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestValidateVersionStateTransition(t *testing.T) {
+
+	Convey("Given a version moving through each state in order", t, func() {
+
+		Convey("Then every transition is allowed", func() {
+			So(ValidateVersionStateTransition(CreatedState, SubmittedState), ShouldBeNil)
+			So(ValidateVersionStateTransition(SubmittedState, CompletedState), ShouldBeNil)
+			So(ValidateVersionStateTransition(CompletedState, EditionConfirmedState), ShouldBeNil)
+			So(ValidateVersionStateTransition(EditionConfirmedState, AssociatedState), ShouldBeNil)
+			So(ValidateVersionStateTransition(AssociatedState, PublishedState), ShouldBeNil)
+		})
+	})
+
+	Convey("Given a version that is not changing state", t, func() {
+
+		Convey("Then the update is allowed until the version is published", func() {
+			So(ValidateVersionStateTransition(AssociatedState, ""), ShouldBeNil)
+			So(ValidateVersionStateTransition(AssociatedState, AssociatedState), ShouldBeNil)
+			So(ValidateVersionStateTransition(PublishedState, ""), ShouldEqual, errs.ErrResourcePublished)
+		})
+	})
+
+	Convey("Given a version skipping a state", t, func() {
+
+		Convey("Then the error names the state the version was expected to be in", func() {
+			So(ValidateVersionStateTransition(CreatedState, CompletedState), ShouldEqual, errs.ErrExpectedResourceStateOfSubmitted)
+			So(ValidateVersionStateTransition(EditionConfirmedState, PublishedState), ShouldEqual, errs.ErrExpectedResourceStateOfAssociated)
+		})
+	})
+
+	Convey("Given a version being detached", t, func() {
+
+		Convey("Then only edition-confirmed and associated versions can be detached", func() {
+			So(ValidateVersionStateTransition(EditionConfirmedState, DetachedState), ShouldBeNil)
+			So(ValidateVersionStateTransition(AssociatedState, DetachedState), ShouldBeNil)
+			So(ValidateVersionStateTransition(CompletedState, DetachedState), ShouldEqual, errs.ErrIncorrectStateToDetach)
+		})
+	})
+
+	Convey("Given a version being moved back to created", t, func() {
+
+		Convey("Then the state is rejected as invalid", func() {
+			So(ValidateVersionStateTransition(SubmittedState, CreatedState), ShouldEqual, ErrVersionStateInvalid)
+		})
+	})
+}
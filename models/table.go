@@ -0,0 +1,90 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+)
+
+var (
+	tableIDPattern      = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	tableIDInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// NewTable represents a request to create an FTB table from a subset of the dimensions of an ftb-blob version
+type NewTable struct {
+	Description string   `json:"description,omitempty"`
+	Dimensions  []string `json:"dimensions"`
+	ID          string   `json:"id,omitempty"`
+	Title       string   `json:"title"`
+}
+
+// TableLinks represents the links to a table added to the dataset, edition and version of the ftb-blob it was
+// created from
+type TableLinks struct {
+	Dataset Table
+	Edition Table
+	Version Table
+}
+
+// CreateNewTable manages the creation of a table request from a reader
+func CreateNewTable(reader io.Reader) (*NewTable, error) {
+	b, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, errs.ErrUnableToReadMessage
+	}
+
+	var table NewTable
+	if err = json.Unmarshal(b, &table); err != nil {
+		return nil, errs.ErrUnableToParseJSON
+	}
+
+	// The dataset id of a table defaults to its title in lower kebab case
+	if table.ID == "" {
+		table.ID = strings.Trim(tableIDInvalidChars.ReplaceAllString(strings.ToLower(table.Title), "-"), "-")
+	}
+
+	return &table, nil
+}
+
+// ValidateNewTable checks the content of the table request
+func ValidateNewTable(table *NewTable) error {
+	var missingFields []string
+	var invalidFields []string
+
+	if table.Title == "" {
+		missingFields = append(missingFields, "title")
+	}
+
+	if len(table.Dimensions) == 0 {
+		missingFields = append(missingFields, "dimensions")
+	}
+
+	if missingFields != nil {
+		return fmt.Errorf("missing mandatory fields: %v", missingFields)
+	}
+
+	if !tableIDPattern.MatchString(table.ID) {
+		invalidFields = append(invalidFields, "id")
+	}
+
+	seen := make(map[string]bool)
+	for _, dimension := range table.Dimensions {
+		if dimension == "" || seen[dimension] {
+			invalidFields = append(invalidFields, "dimensions")
+			break
+		}
+		seen[dimension] = true
+	}
+
+	if invalidFields != nil {
+		return fmt.Errorf("invalid fields: %v", invalidFields)
+	}
+
+	return nil
+}
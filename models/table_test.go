@@ -0,0 +1,41 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCreateNewTable(t *testing.T) {
+
+	Convey("Given a table request without an id", t, func() {
+		reader := strings.NewReader(`{"title": "2011 Census - People by Age & Sex", "dimensions": ["AGE", "SEX"]}`)
+
+		Convey("Then the id is created from the title", func() {
+			table, err := CreateNewTable(reader)
+			So(err, ShouldBeNil)
+			So(table.ID, ShouldEqual, "2011-census-people-by-age-sex")
+			So(ValidateNewTable(table), ShouldBeNil)
+		})
+	})
+}
+
+func TestValidateNewTable(t *testing.T) {
+
+	Convey("Given a table request without a title or dimensions", t, func() {
+		table := &NewTable{ID: "people"}
+
+		Convey("Then the missing fields are returned", func() {
+			So(ValidateNewTable(table).Error(), ShouldEqual, "missing mandatory fields: [title dimensions]")
+		})
+	})
+
+	Convey("Given a table request with an invalid id and a repeated dimension", t, func() {
+		table := &NewTable{ID: "People By Sex", Title: "People by sex", Dimensions: []string{"SEX", "SEX"}}
+
+		Convey("Then the invalid fields are returned", func() {
+			So(ValidateNewTable(table).Error(), ShouldEqual, "invalid fields: [id dimensions]")
+		})
+	})
+}
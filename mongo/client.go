@@ -0,0 +1,145 @@
+package mongo
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/config"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
+)
+
+// NewClientOptions returns the options used to connect to the mongo deployment described by cfg. The bind address
+// is either a host and port, optionally preceded by credentials, or a full connection string, whose options are
+// overridden by those set in cfg. Writes are acknowledged by a majority of the replica set, as they were with mgo.
+func NewClientOptions(cfg config.MongoConfig) (*options.ClientOptions, error) {
+	uri := cfg.BindAddr
+	if !strings.HasPrefix(uri, "mongodb://") && !strings.HasPrefix(uri, "mongodb+srv://") {
+		uri = "mongodb://" + uri
+	}
+
+	opts := options.Client().
+		ApplyURI(uri).
+		SetMaxPoolSize(cfg.MaxPoolSize).
+		SetMinPoolSize(cfg.MinPoolSize).
+		SetWriteConcern(writeconcern.New(writeconcern.WMajority()))
+
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	if cfg.ConnectTimeout > 0 {
+		opts.SetConnectTimeout(cfg.ConnectTimeout)
+	}
+
+	if cfg.MaxConnIdleTime > 0 {
+		opts.SetMaxConnIdleTime(cfg.MaxConnIdleTime)
+	}
+
+	if cfg.ReadPreference != "" {
+		mode, err := readpref.ModeFromString(cfg.ReadPreference)
+		if err != nil {
+			return nil, err
+		}
+
+		readPreference, err := readpref.New(mode)
+		if err != nil {
+			return nil, err
+		}
+		opts.SetReadPreference(readPreference)
+	}
+
+	if cfg.Username != "" {
+		opts.SetAuth(options.Credential{
+			AuthSource: cfg.AuthSource,
+			Username:   cfg.Username,
+			Password:   cfg.Password,
+		})
+	}
+
+	if cfg.EnableTLS {
+		tlsConfig, err := newTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		opts.SetTLSConfig(tlsConfig)
+	}
+
+	return opts, nil
+}
+
+// newTLSConfig returns the TLS configuration of connections to mongo, trusting the certificates in the CA file of
+// cfg, if one is given, as well as those of the system
+func newTLSConfig(cfg config.MongoConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.TLSInsecureSkipVerify}
+	if cfg.CAFilePath == "" {
+		return tlsConfig, nil
+	}
+
+	pem, err := ioutil.ReadFile(cfg.CAFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", cfg.CAFilePath)
+	}
+	tlsConfig.RootCAs = roots
+
+	return tlsConfig, nil
+}
+
+// Init connects to the mongo deployment described by cfg, checking that it can be reached within the connect
+// timeout.
+func (m *Mongo) Init(ctx context.Context, cfg config.MongoConfig) error {
+	if m.Client != nil {
+		return errors.New("client already exists")
+	}
+
+	opts, err := NewClientOptions(cfg)
+	if err != nil {
+		return err
+	}
+
+	client, err := mongodriver.Connect(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	pingCtx := ctx
+	if cfg.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		pingCtx, cancel = context.WithTimeout(ctx, cfg.ConnectTimeout)
+		defer cancel()
+	}
+
+	if err = client.Ping(pingCtx, nil); err != nil {
+		client.Disconnect(ctx)
+		return err
+	}
+
+	m.Client = client
+	return nil
+}
+
+// Close disconnects from mongo, waiting for the operations in progress to complete until ctx is done
+func (m *Mongo) Close(ctx context.Context) error {
+	return m.Client.Disconnect(ctx)
+}
+
+// collection returns a collection of the database of the store
+func (m *Mongo) collection(name string) *mongodriver.Collection {
+	return m.Client.Database(m.Database).Collection(name)
+}
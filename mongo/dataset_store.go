@@ -2,82 +2,82 @@ package mongo
 
 import (
 	"context"
-	"errors"
 	"strconv"
 	"sync"
 	"time"
 
-	"github.com/globalsign/mgo"
-	"github.com/globalsign/mgo/bson"
-
 	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
 	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
 	"github.com/ONSdigital/log.go/log"
+	"go.mongodb.org/mongo-driver/bson"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // Mongo represents a simplistic MongoDB configuration.
 type Mongo struct {
+	Client         *mongodriver.Client
 	CodeListURL    string
 	Collection     string
 	Database       string
 	DatasetURL     string
-	Session        *mgo.Session
-	URI            string
 	lastPingTime   time.Time
 	lastPingResult error
+	pingMutex      sync.Mutex
 }
 
 const (
 	editionsCollection = "editions"
 )
 
-// Init creates a new mgo.Session with a strong consistency and a write mode of "majortiy".
-func (m *Mongo) Init() (session *mgo.Session, err error) {
-	if session != nil {
-		return nil, errors.New("session already exists")
+// GetDatasets retrieves a page of dataset documents along with the total number of datasets. When authorised only
+// datasets with a next revision are returned, and otherwise only those with a current, published, revision.
+func (m *Mongo) GetDatasets(ctx context.Context, offset, limit int, authorised bool) (*models.DatasetUpdateResults, error) {
+	// Only datasets holding the revision that is listed are matched, the next when authorised and otherwise the
+	// current, so that the count and pages agree with the datasets listed
+	revision := "current"
+	if authorised {
+		revision = "next"
 	}
+	selector := bson.M{revision: bson.M{"$type": "object"}}
+
+	c := m.collection("datasets")
 
-	if session, err = mgo.Dial(m.URI); err != nil {
+	totalCount, err := c.CountDocuments(ctx, selector)
+	if err != nil {
+		log.Event(ctx, "error counting items", log.ERROR, log.Error(err))
 		return nil, err
 	}
 
-	session.EnsureSafe(&mgo.Safe{WMode: "majority"})
-	session.SetMode(mgo.Strong, true)
-	return session, nil
-}
-
-// GetDatasets retrieves all dataset documents
-func (m *Mongo) GetDatasets(ctx context.Context) ([]models.DatasetUpdate, error) {
-	s := m.Session.Copy()
-	defer s.Close()
-
-	iter := s.DB(m.Database).C("datasets").Find(nil).Iter()
-	defer func() {
-		err := iter.Close()
+	results := []models.DatasetUpdate{}
+	if limit > 0 {
+		// Sort by id so that pages are stable between requests
+		opts := options.Find().SetSort(bson.M{"_id": 1}).SetSkip(int64(offset)).SetLimit(int64(limit))
+		cursor, err := c.Find(ctx, selector, opts)
 		if err != nil {
-			log.Event(ctx, "error closing iterator", log.ERROR, log.Error(err))
+			return nil, err
 		}
-	}()
 
-	results := []models.DatasetUpdate{}
-	if err := iter.All(&results); err != nil {
-		if err == mgo.ErrNotFound {
-			return nil, errs.ErrDatasetNotFound
+		if err = cursor.All(ctx, &results); err != nil {
+			return nil, err
 		}
-		return nil, err
 	}
 
-	return results, nil
+	return &models.DatasetUpdateResults{
+		Count:      len(results),
+		Items:      results,
+		Limit:      limit,
+		Offset:     offset,
+		TotalCount: int(totalCount),
+	}, nil
 }
 
 // GetDataset retrieves a dataset document
-func (m *Mongo) GetDataset(id string) (*models.DatasetUpdate, error) {
-	s := m.Session.Copy()
-	defer s.Close()
+func (m *Mongo) GetDataset(ctx context.Context, id string) (*models.DatasetUpdate, error) {
 	var dataset models.DatasetUpdate
-	err := s.DB(m.Database).C("datasets").Find(bson.M{"_id": id}).One(&dataset)
+	err := m.collection("datasets").FindOne(ctx, bson.M{"_id": id}).Decode(&dataset)
 	if err != nil {
-		if err == mgo.ErrNotFound {
+		if err == mongodriver.ErrNoDocuments {
 			return nil, errs.ErrDatasetNotFound
 		}
 		return nil, err
@@ -86,26 +86,48 @@ func (m *Mongo) GetDataset(id string) (*models.DatasetUpdate, error) {
 	return &dataset, nil
 }
 
-// GetEditions retrieves all edition documents for a dataset
-func (m *Mongo) GetEditions(ctx context.Context, id, state string) (*models.EditionUpdateResults, error) {
-	s := m.Session.Copy()
-	defer s.Close()
+// UpsertDataset adds or overrides an existing dataset document
+func (m *Mongo) UpsertDataset(ctx context.Context, id string, datasetDoc *models.DatasetUpdate) (err error) {
+	update := bson.M{
+		"$set": datasetDoc,
+		"$setOnInsert": bson.M{
+			"last_updated": time.Now(),
+		},
+	}
 
-	selector := buildEditionsQuery(id, state)
+	_, err = m.collection("datasets").UpdateOne(ctx, bson.M{"_id": id}, update, options.Update().SetUpsert(true))
+	return
+}
 
-	iter := s.DB(m.Database).C(editionsCollection).Find(selector).Iter()
-	defer func() {
-		err := iter.Close()
-		if err != nil {
-			log.Event(ctx, "error closing edition iterator", log.ERROR, log.Error(err), log.Data{"selector": selector})
-		}
-	}()
+// UpdateDataset updates the next sub-document of an existing dataset with the fields provided
+func (m *Mongo) UpdateDataset(ctx context.Context, id string, dataset *models.Dataset, currentState string) (err error) {
+	updates := models.DatasetUpdateFields(dataset, currentState)
+	log.Event(ctx, "built update query for dataset resource", log.INFO, log.Data{"dataset_id": id, "updates": updates})
+
+	result, err := m.collection("datasets").UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": updates})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errs.ErrDatasetNotFound
+	}
+
+	return nil
+}
+
+// GetEditions retrieves the edition documents for a dataset that match the query, which may be nil
+func (m *Mongo) GetEditions(ctx context.Context, id, state string, query *models.EditionsQuery) (*models.EditionUpdateResults, error) {
+	selector := buildEditionsQuery(id, state, query)
+
+	cursor, err := m.collection(editionsCollection).Find(ctx, selector)
+	if err != nil {
+		return nil, err
+	}
 
 	var results []*models.EditionUpdate
-	if err := iter.All(&results); err != nil {
-		if err == mgo.ErrNotFound {
-			return nil, errs.ErrEditionNotFound
-		}
+	if err = cursor.All(ctx, &results); err != nil {
+		log.Event(ctx, "error reading editions", log.ERROR, log.Error(err), log.Data{"selector": selector})
 		return nil, err
 	}
 
@@ -115,7 +137,7 @@ func (m *Mongo) GetEditions(ctx context.Context, id, state string) (*models.Edit
 	return &models.EditionUpdateResults{Items: results}, nil
 }
 
-func buildEditionsQuery(id, state string) bson.M {
+func buildEditionsQuery(id, state string, query *models.EditionsQuery) bson.M {
 	var selector bson.M
 	if state != "" {
 		selector = bson.M{
@@ -128,20 +150,37 @@ func buildEditionsQuery(id, state string) bson.M {
 		}
 	}
 
+	if query != nil && query.State != "" {
+		if state != "" {
+			selector["current.state"] = bson.M{"$in": filterStates([]string{state}, query.State)}
+		} else {
+			selector["next.state"] = query.State
+		}
+	}
+
 	return selector
 }
 
-// GetEdition retrieves an edition document for a dataset
-func (m *Mongo) GetEdition(id, editionID, state string) (*models.EditionUpdate, error) {
-	s := m.Session.Copy()
-	defer s.Close()
+// filterStates returns those of states that a state filter accepts
+func filterStates(states []string, filter string) []string {
+	filtered := []string{}
+	for _, state := range states {
+		if state == filter {
+			filtered = append(filtered, state)
+		}
+	}
+
+	return filtered
+}
 
+// GetEdition retrieves an edition document for a dataset
+func (m *Mongo) GetEdition(ctx context.Context, id, editionID, state string) (*models.EditionUpdate, error) {
 	selector := buildEditionQuery(id, editionID, state)
 
 	var edition models.EditionUpdate
-	err := s.DB(m.Database).C(editionsCollection).Find(selector).One(&edition)
+	err := m.collection(editionsCollection).FindOne(ctx, selector).Decode(&edition)
 	if err != nil {
-		if err == mgo.ErrNotFound {
+		if err == mongodriver.ErrNoDocuments {
 			return nil, errs.ErrEditionNotFound
 		}
 		return nil, err
@@ -167,10 +206,25 @@ func buildEditionQuery(id, editionID, state string) bson.M {
 	return selector
 }
 
+// UpsertEdition adds or overrides an existing edition document
+func (m *Mongo) UpsertEdition(ctx context.Context, datasetID, edition string, editionDoc *models.EditionUpdate) (err error) {
+	selector := bson.M{
+		"next.edition":          edition,
+		"next.links.dataset.id": datasetID,
+	}
+
+	editionDoc.Next.LastUpdated = time.Now()
+
+	update := bson.M{
+		"$set": editionDoc,
+	}
+
+	_, err = m.collection(editionsCollection).UpdateOne(ctx, selector, update, options.Update().SetUpsert(true))
+	return
+}
+
 // GetNextVersion retrieves the latest version for an edition of a dataset
-func (m *Mongo) GetNextVersion(datasetID, edition string) (int, error) {
-	s := m.Session.Copy()
-	defer s.Close()
+func (m *Mongo) GetNextVersion(ctx context.Context, datasetID, edition string) (int, error) {
 	var version models.Version
 	var nextVersion int
 
@@ -180,9 +234,10 @@ func (m *Mongo) GetNextVersion(datasetID, edition string) (int, error) {
 	}
 
 	// Results are sorted in reverse order to get latest version
-	err := s.DB(m.Database).C("instances").Find(selector).Sort("-version").One(&version)
+	opts := options.FindOne().SetSort(bson.M{"version": -1})
+	err := m.collection("instances").FindOne(ctx, selector, opts).Decode(&version)
 	if err != nil {
-		if err == mgo.ErrNotFound {
+		if err == mongodriver.ErrNoDocuments {
 			return 1, nil
 		}
 		return nextVersion, err
@@ -193,26 +248,24 @@ func (m *Mongo) GetNextVersion(datasetID, edition string) (int, error) {
 	return nextVersion, nil
 }
 
-// GetVersions retrieves all version documents for a dataset edition
-func (m *Mongo) GetVersions(ctx context.Context, id, editionID, state string) (*models.VersionResults, error) {
-	s := m.Session.Copy()
-	defer s.Close()
+// GetVersions retrieves the version documents for a dataset edition that match the query, which may be nil, in
+// the order it sorts them by
+func (m *Mongo) GetVersions(ctx context.Context, id, editionID, state string, query *models.VersionsQuery) (*models.VersionResults, error) {
+	selector := buildVersionsQuery(id, editionID, state, query)
 
-	selector := buildVersionsQuery(id, editionID, state)
+	opts := options.Find()
+	if query != nil && query.Sort != "" {
+		opts.SetSort(buildVersionsSort(query.Sort))
+	}
 
-	iter := s.DB(m.Database).C("instances").Find(selector).Iter()
-	defer func() {
-		err := iter.Close()
-		if err != nil {
-			log.Event(ctx, "error closing instance iterator", log.ERROR, log.Error(err), log.Data{"selector": selector})
-		}
-	}()
+	cursor, err := m.collection("instances").Find(ctx, selector, opts)
+	if err != nil {
+		return nil, err
+	}
 
 	var results []models.Version
-	if err := iter.All(&results); err != nil {
-		if err == mgo.ErrNotFound {
-			return nil, errs.ErrVersionNotFound
-		}
+	if err = cursor.All(ctx, &results); err != nil {
+		log.Event(ctx, "error reading versions", log.ERROR, log.Error(err), log.Data{"selector": selector})
 		return nil, err
 	}
 
@@ -228,7 +281,7 @@ func (m *Mongo) GetVersions(ctx context.Context, id, editionID, state string) (*
 	return &models.VersionResults{Items: results}, nil
 }
 
-func buildVersionsQuery(id, editionID, state string) bson.M {
+func buildVersionsQuery(id, editionID, state string, query *models.VersionsQuery) bson.M {
 	var selector bson.M
 	if state == "" {
 		selector = bson.M{
@@ -248,14 +301,57 @@ func buildVersionsQuery(id, editionID, state string) bson.M {
 		}
 	}
 
+	if query == nil {
+		return selector
+	}
+
+	if query.State != "" {
+		states := []string{models.EditionConfirmedState, models.AssociatedState, models.PublishedState}
+		if state != "" {
+			states = []string{state}
+		}
+
+		delete(selector, "$or")
+		selector["state"] = bson.M{"$in": filterStates(states, query.State)}
+	}
+
+	releaseDate := bson.M{}
+	if query.ReleaseDateFrom != "" {
+		releaseDate["$gte"] = query.ReleaseDateFrom
+	}
+	if query.ReleaseDateTo != "" {
+		releaseDate["$lte"] = query.ReleaseDateTo
+	}
+	if len(releaseDate) > 0 {
+		selector["release_date"] = releaseDate
+	}
+
+	if query.Version != 0 {
+		selector["version"] = query.Version
+	}
+
 	return selector
 }
 
-// GetVersion retrieves a version document for a dataset edition
-func (m *Mongo) GetVersion(id, editionID, versionID, state string) (*models.Version, error) {
-	s := m.Session.Copy()
-	defer s.Close()
+// buildVersionsSort returns the sort of a list of versions, where versions released at the same time are listed
+// in order of version
+func buildVersionsSort(sort string) bson.D {
+	switch sort {
+	case models.VersionSortVersion:
+		return bson.D{{Key: "version", Value: 1}}
+	case models.VersionSortVersionDesc:
+		return bson.D{{Key: "version", Value: -1}}
+	case models.VersionSortReleaseDate:
+		return bson.D{{Key: "release_date", Value: 1}, {Key: "version", Value: 1}}
+	case models.VersionSortReleaseDateDesc:
+		return bson.D{{Key: "release_date", Value: -1}, {Key: "version", Value: -1}}
+	}
 
+	return nil
+}
+
+// GetVersion retrieves a version document for a dataset edition
+func (m *Mongo) GetVersion(ctx context.Context, id, editionID, versionID, state string) (*models.Version, error) {
 	versionNumber, err := strconv.Atoi(versionID)
 	if err != nil {
 		return nil, err
@@ -263,9 +359,9 @@ func (m *Mongo) GetVersion(id, editionID, versionID, state string) (*models.Vers
 	selector := buildVersionQuery(id, editionID, state, versionNumber)
 
 	var version models.Version
-	err = s.DB(m.Database).C("instances").Find(selector).One(&version)
+	err = m.collection("instances").FindOne(ctx, selector).Decode(&version)
 	if err != nil {
-		if err == mgo.ErrNotFound {
+		if err == mongodriver.ErrNoDocuments {
 			return nil, errs.ErrVersionNotFound
 		}
 		return nil, err
@@ -293,11 +389,75 @@ func buildVersionQuery(id, editionID, state string, versionID int) bson.M {
 	return selector
 }
 
-// CheckDatasetExists checks that the dataset exists
-func (m *Mongo) CheckDatasetExists(id, state string) error {
-	s := m.Session.Copy()
-	defer s.Close()
+// UpdateVersion updates an existing version document with the fields provided
+func (m *Mongo) UpdateVersion(ctx context.Context, id string, version *models.Version) (err error) {
+	update := bson.M{"$set": models.VersionUpdateFields(version)}
+
+	// A published version no longer belongs to a collection
+	if version.State == models.PublishedState {
+		update["$unset"] = bson.M{"collection_id": ""}
+	}
+
+	result, err := m.collection("instances").UpdateOne(ctx, bson.M{"id": id}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errs.ErrVersionNotFound
+	}
+
+	return nil
+}
+
+// AddTableLinks appends the links to a table onto the dataset, edition and version of the ftb-blob it was created
+// from, unless they are already linked, so that the links can be added again. The current sub-documents of the
+// dataset and edition are only updated once they have been published.
+func (m *Mongo) AddTableLinks(ctx context.Context, datasetID, editionID, instanceID string, links *models.TableLinks) error {
+	datasets := m.collection("datasets")
+	editions := m.collection(editionsCollection)
+
+	result, err := datasets.UpdateOne(ctx, bson.M{"_id": datasetID}, bson.M{"$addToSet": bson.M{"next.tables": links.Dataset}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errs.ErrDatasetNotFound
+	}
 
+	published := bson.M{"_id": datasetID, "current": bson.M{"$type": "object"}}
+	if _, err = datasets.UpdateOne(ctx, published, bson.M{"$addToSet": bson.M{"current.tables": links.Dataset}}); err != nil {
+		return err
+	}
+
+	selector := bson.M{"next.links.dataset.id": datasetID, "next.edition": editionID}
+	result, err = editions.UpdateOne(ctx, selector, bson.M{"$addToSet": bson.M{"next.tables": links.Edition}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errs.ErrEditionNotFound
+	}
+
+	selector["current"] = bson.M{"$type": "object"}
+	if _, err = editions.UpdateOne(ctx, selector, bson.M{"$addToSet": bson.M{"current.tables": links.Edition}}); err != nil {
+		return err
+	}
+
+	update := bson.M{"$addToSet": bson.M{"tables": links.Version}, "$set": bson.M{"last_updated": time.Now()}}
+	result, err = m.collection("instances").UpdateOne(ctx, bson.M{"id": instanceID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return errs.ErrVersionNotFound
+	}
+
+	return nil
+}
+
+// CheckDatasetExists checks that the dataset exists
+func (m *Mongo) CheckDatasetExists(ctx context.Context, id, state string) error {
 	var query bson.M
 	if state == "" {
 		query = bson.M{
@@ -310,7 +470,7 @@ func (m *Mongo) CheckDatasetExists(id, state string) error {
 		}
 	}
 
-	count, err := s.DB(m.Database).C("datasets").Find(query).Count()
+	count, err := m.collection("datasets").CountDocuments(ctx, query, options.Count().SetLimit(1))
 	if err != nil {
 		return err
 	}
@@ -323,10 +483,7 @@ func (m *Mongo) CheckDatasetExists(id, state string) error {
 }
 
 // CheckEditionExists checks that the edition of a dataset exists
-func (m *Mongo) CheckEditionExists(id, editionID, state string) error {
-	s := m.Session.Copy()
-	defer s.Close()
-
+func (m *Mongo) CheckEditionExists(ctx context.Context, id, editionID, state string) error {
 	var query bson.M
 	if state == "" {
 		query = bson.M{
@@ -341,7 +498,7 @@ func (m *Mongo) CheckEditionExists(id, editionID, state string) error {
 		}
 	}
 
-	count, err := s.DB(m.Database).C(editionsCollection).Find(query).Count()
+	count, err := m.collection(editionsCollection).CountDocuments(ctx, query, options.Count().SetLimit(1))
 	if err != nil {
 		return err
 	}
@@ -353,40 +510,21 @@ func (m *Mongo) CheckEditionExists(id, editionID, state string) error {
 	return nil
 }
 
-// Ping the mongodb database
+// Ping the mongodb database. The result is cached for a second, as it is read by both the health check and the
+// metrics endpoint.
 func (m *Mongo) Ping(ctx context.Context) (time.Time, error) {
+	m.pingMutex.Lock()
+	defer m.pingMutex.Unlock()
+
 	if time.Since(m.lastPingTime) < 1*time.Second {
 		return m.lastPingTime, m.lastPingResult
 	}
 
-	s := m.Session.Copy()
-	defer s.Close()
-
 	m.lastPingTime = time.Now()
-	pingDoneChan := make(chan error)
-	var wg sync.WaitGroup
-
-	wg.Add(1)
-	go func() {
-		log.Event(ctx, "db ping", log.INFO)
-		err := s.Ping()
-		if err != nil {
-			log.Event(ctx, "Ping mongo", log.ERROR, log.Error(err))
-		}
-		pingDoneChan <- err
-		wg.Done()
-	}()
-
-	go func() {
-		wg.Wait()
-		close(pingDoneChan)
-	}()
-
-	select {
-	case err := <-pingDoneChan:
-		m.lastPingResult = err
-	case <-ctx.Done():
-		m.lastPingResult = ctx.Err()
+	m.lastPingResult = m.Client.Ping(ctx, nil)
+	if m.lastPingResult != nil {
+		log.Event(ctx, "Ping mongo", log.ERROR, log.Error(m.lastPingResult))
 	}
+
 	return m.lastPingTime, m.lastPingResult
 }
@@ -0,0 +1,82 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestBuildVersionsQuery(t *testing.T) {
+
+	Convey("Given a query for the versions of an edition in any state", t, func() {
+		query := &models.VersionsQuery{
+			ReleaseDateFrom: "2013-01-01T00:00:00.000Z",
+			ReleaseDateTo:   "2013-12-31T23:59:59.999Z",
+			State:           models.AssociatedState,
+			Version:         2,
+		}
+
+		Convey("When the selector is built", func() {
+			selector := buildVersionsQuery("People", "2011", "", query)
+
+			Convey("Then the state filter replaces the states a version is listed in", func() {
+				So(selector, ShouldResemble, bson.M{
+					"links.dataset.id": "People",
+					"edition":          "2011",
+					"state":            bson.M{"$in": []string{models.AssociatedState}},
+					"release_date":     bson.M{"$gte": query.ReleaseDateFrom, "$lte": query.ReleaseDateTo},
+					"version":          2,
+				})
+			})
+		})
+
+		Convey("When the selector is built for published versions", func() {
+			selector := buildVersionsQuery("People", "2011", models.PublishedState, query)
+
+			Convey("Then the state filter selects nothing", func() {
+				So(selector["state"], ShouldResemble, bson.M{"$in": []string{}})
+			})
+		})
+	})
+
+	Convey("Given no query", t, func() {
+		Convey("Then only versions confirmed against an edition are selected", func() {
+			selector := buildVersionsQuery("People", "2011", "", nil)
+			So(selector, ShouldContainKey, "$or")
+			So(selector, ShouldNotContainKey, "state")
+		})
+	})
+}
+
+func TestBuildEditionsQuery(t *testing.T) {
+
+	Convey("Given a query for editions in a state", t, func() {
+		query := &models.EditionsQuery{State: models.EditionConfirmedState}
+
+		Convey("Then the next revision is filtered when reading editions in any state", func() {
+			So(buildEditionsQuery("People", "", query), ShouldResemble, bson.M{
+				"next.links.dataset.id": "People",
+				"next.state":            models.EditionConfirmedState,
+			})
+		})
+
+		Convey("Then the current revision is filtered when reading published editions", func() {
+			So(buildEditionsQuery("People", models.PublishedState, query), ShouldResemble, bson.M{
+				"current.links.dataset.id": "People",
+				"current.state":            bson.M{"$in": []string{}},
+			})
+		})
+	})
+}
+
+func TestBuildVersionsSort(t *testing.T) {
+
+	Convey("Given the sorts of a list of versions", t, func() {
+		Convey("Then versions released together are sorted by version", func() {
+			So(buildVersionsSort(models.VersionSortReleaseDateDesc), ShouldResemble, bson.D{{Key: "release_date", Value: -1}, {Key: "version", Value: -1}})
+			So(buildVersionsSort(models.VersionSortVersion), ShouldResemble, bson.D{{Key: "version", Value: 1}})
+		})
+	})
+}
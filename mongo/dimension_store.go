@@ -1,26 +1,29 @@
 package mongo
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
 	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
-	"github.com/globalsign/mgo/bson"
+	"go.mongodb.org/mongo-driver/bson"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const dimensionOptions = "dimension.options"
 
 // GetDimensionsFromInstance returns a list of dimensions and their options for an instance resource
-func (m *Mongo) GetDimensionsFromInstance(id string) (*models.DimensionNodeResults, error) {
-	s := m.Session.Copy()
-	defer s.Close()
+func (m *Mongo) GetDimensionsFromInstance(ctx context.Context, id string) (*models.DimensionNodeResults, error) {
+	opts := options.Find().SetProjection(bson.M{"id": 0, "last_updated": 0, "instance_id": 0})
+	cursor, err := m.collection(dimensionOptions).Find(ctx, bson.M{"instance_id": id}, opts)
+	if err != nil {
+		return nil, err
+	}
 
 	var dimensions []models.DimensionOption
-	iter := s.DB(m.Database).C(dimensionOptions).Find(bson.M{"instance_id": id}).Select(bson.M{"id": 0, "last_updated": 0, "instance_id": 0}).Iter()
-
-	err := iter.All(&dimensions)
-	if err != nil {
+	if err = cursor.All(ctx, &dimensions); err != nil {
 		return nil, err
 	}
 
@@ -28,77 +31,166 @@ func (m *Mongo) GetDimensionsFromInstance(id string) (*models.DimensionNodeResul
 }
 
 // GetUniqueDimensionAndOptions returns a list of dimension options for an instance resource
-func (m *Mongo) GetUniqueDimensionAndOptions(id, dimension string) (*models.DimensionValues, error) {
-	s := m.Session.Copy()
-	defer s.Close()
-
-	var values []string
-	err := s.DB(m.Database).C(dimensionOptions).Find(bson.M{"instance_id": id, "name": dimension}).Distinct("option", &values)
+func (m *Mongo) GetUniqueDimensionAndOptions(ctx context.Context, id, dimension string) (*models.DimensionValues, error) {
+	distinct, err := m.collection(dimensionOptions).Distinct(ctx, "option", bson.M{"instance_id": id, "name": dimension})
 	if err != nil {
 		return nil, err
 	}
 
-	if len(values) == 0 {
+	if len(distinct) == 0 {
 		return nil, errs.ErrDimensionNodeNotFound
 	}
 
+	values := make([]string, 0, len(distinct))
+	for _, value := range distinct {
+		if option, ok := value.(string); ok {
+			values = append(values, option)
+		}
+	}
+
 	return &models.DimensionValues{Name: dimension, Options: values}, nil
 }
 
 // AddDimensionToInstance to the dimension collection
-func (m *Mongo) AddDimensionToInstance(opt *models.CachedDimensionOption) error {
-	s := m.Session.Copy()
-	defer s.Close()
-
+func (m *Mongo) AddDimensionToInstance(ctx context.Context, opt *models.CachedDimensionOption) error {
 	option := models.DimensionOption{InstanceID: opt.InstanceID, Option: opt.Option, Name: opt.Name, Label: opt.Label}
 	option.Links.CodeList = models.LinkObject{ID: opt.CodeList, HRef: fmt.Sprintf("%s/code-lists/%s", m.CodeListURL, opt.CodeList)}
 	option.Links.Code = models.LinkObject{ID: opt.Code, HRef: fmt.Sprintf("%s/code-lists/%s/codes/%s", m.CodeListURL, opt.CodeList, opt.Code)}
 
 	option.LastUpdated = time.Now().UTC()
-	_, err := s.DB(m.Database).C(dimensionOptions).Upsert(bson.M{"instance_id": option.InstanceID, "name": option.Name,
-		"option": option.Option}, &option)
+	selector := bson.M{"instance_id": option.InstanceID, "name": option.Name, "option": option.Option}
+	_, err := m.collection(dimensionOptions).ReplaceOne(ctx, selector, &option, options.Replace().SetUpsert(true))
+
+	return err
+}
+
+// AddDimensionOptions upserts a set of dimension options in bulk, replacing any option of the same instance,
+// dimension and name, so that adding the same options again does not duplicate them
+func (m *Mongo) AddDimensionOptions(ctx context.Context, options []*models.DimensionOption) error {
+	if len(options) == 0 {
+		return nil
+	}
+
+	writes := make([]mongodriver.WriteModel, len(options))
+	lastUpdated := time.Now().UTC()
+	for i, option := range options {
+		option.LastUpdated = lastUpdated
+		selector := bson.M{"instance_id": option.InstanceID, "name": option.Name, "option": option.Option}
+		writes[i] = mongodriver.NewReplaceOneModel().SetFilter(selector).SetReplacement(option).SetUpsert(true)
+	}
+
+	_, err := m.collection(dimensionOptions).BulkWrite(ctx, writes, writeUnordered)
 
 	return err
 }
 
-// GetDimensions returns a list of all dimensions from a dataset
-func (m *Mongo) GetDimensions(datasetID, versionID string) ([]bson.M, error) {
-	s := m.Session.Copy()
-	defer s.Close()
+// writeUnordered continues to write the documents following any that fail
+var writeUnordered = options.BulkWrite().SetOrdered(false)
 
+// GetDimensions returns the first option of each dimension of a version
+func (m *Mongo) GetDimensions(ctx context.Context, datasetID, versionID string) ([]models.DimensionOption, error) {
 	// To get all unique values an aggregation is needed, as using distinct() will only return the distinct values and
 	// not the documents.
 	// Match by instance_id
 	match := bson.M{"$match": bson.M{"instance_id": versionID}}
 	// Then group the values by name.
 	group := bson.M{"$group": bson.M{"_id": "$name", "doc": bson.M{"$first": "$$ROOT"}}}
-	results := []bson.M{}
-	err := s.DB(m.Database).C(dimensionOptions).Pipe([]bson.M{match, group}).All(&results)
+	cursor, err := m.collection(dimensionOptions).Aggregate(ctx, []bson.M{match, group})
 	if err != nil {
 		return nil, err
 	}
 
-	if len(results) < 1 {
+	var groups []struct {
+		Doc models.DimensionOption `bson:"doc"`
+	}
+	if err = cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	if len(groups) < 1 {
 		return nil, errs.ErrDimensionsNotFound
 	}
 
+	results := make([]models.DimensionOption, len(groups))
+	for i, group := range groups {
+		results[i] = group.Doc
+	}
+
 	return results, nil
 }
 
-// GetDimensionOptions returns all dimension options for a dimensions within a dataset.
-func (m *Mongo) GetDimensionOptions(version *models.Version, dimension string) (*models.DimensionOptionResults, error) {
-	s := m.Session.Copy()
-	defer s.Close()
+// GetDimensionOptions returns a page of dimension options for a dimension within a dataset, along with the
+// total number of options for that dimension.
+func (m *Mongo) GetDimensionOptions(ctx context.Context, version *models.Version, dimension string, offset, limit int) (*models.DimensionOptionResults, error) {
+	selector := bson.M{"instance_id": version.ID, "name": dimension}
+	c := m.collection(dimensionOptions)
 
-	var values []models.PublicDimensionOption
-	iter := s.DB(m.Database).C(dimensionOptions).Find(bson.M{"instance_id": version.ID, "name": dimension}).Iter()
-	if err := iter.All(&values); err != nil {
+	totalCount, err := c.CountDocuments(ctx, selector)
+	if err != nil {
 		return nil, err
 	}
 
-	for i := 0; i < len(values); i++ {
-		values[i].Links.Version = *version.Links.Self
+	values := []models.PublicDimensionOption{}
+	if limit > 0 {
+		// Sort by option so that pages are stable between requests
+		opts := options.Find().SetSort(bson.M{"option": 1}).SetSkip(int64(offset)).SetLimit(int64(limit))
+		cursor, err := c.Find(ctx, selector, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = cursor.All(ctx, &values); err != nil {
+			return nil, err
+		}
+	}
+
+	linkOptionsToVersion(values, version)
+
+	return &models.DimensionOptionResults{
+		Count:      len(values),
+		Items:      values,
+		Limit:      limit,
+		Offset:     offset,
+		TotalCount: int(totalCount),
+	}, nil
+}
+
+// GetDimensionOptionsFromIDs returns the dimension options for a dimension within a dataset that match the
+// provided list of option IDs. The options are returned as a single page, so the limit is the number of IDs
+// requested and the total count is the number of options found, which is fewer than the limit for IDs that do not
+// exist.
+func (m *Mongo) GetDimensionOptionsFromIDs(ctx context.Context, version *models.Version, dimension string, ids []string) (*models.DimensionOptionResults, error) {
+	selector := bson.M{"instance_id": version.ID, "name": dimension, "option": bson.M{"$in": ids}}
+
+	cursor, err := m.collection(dimensionOptions).Find(ctx, selector, options.Find().SetSort(bson.M{"option": 1}))
+	if err != nil {
+		return nil, err
 	}
 
-	return &models.DimensionOptionResults{Items: values}, nil
+	values := []models.PublicDimensionOption{}
+	if err = cursor.All(ctx, &values); err != nil {
+		return nil, err
+	}
+
+	linkOptionsToVersion(values, version)
+
+	return &models.DimensionOptionResults{
+		Count:      len(values),
+		Items:      values,
+		Limit:      len(ids),
+		Offset:     0,
+		TotalCount: len(values),
+	}, nil
+}
+
+// linkOptionsToVersion links the options to the instance of the version, leaving them unlinked if the version does
+// not link to itself
+func linkOptionsToVersion(values []models.PublicDimensionOption, version *models.Version) {
+	if version.Links == nil || version.Links.Self == nil {
+		return
+	}
+
+	for i := range values {
+		values[i].Links.Version = *version.Links.Self
+	}
 }
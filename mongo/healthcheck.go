@@ -0,0 +1,20 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+)
+
+const (
+	healthyMessage = "mongodb is OK"
+)
+
+// Checker reports the health of the mongo database to the health check, using the cached result of Ping
+func (m *Mongo) Checker(ctx context.Context, state *healthcheck.CheckState) error {
+	if _, err := m.Ping(ctx); err != nil {
+		return state.Update(healthcheck.StatusCritical, err.Error(), 0)
+	}
+
+	return state.Update(healthcheck.StatusOK, healthyMessage, 0)
+}
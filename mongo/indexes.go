@@ -0,0 +1,333 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	"go.mongodb.org/mongo-driver/bson"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Index is an index of a collection that the store relies on. The keys of a text index have the value "text", and
+// its fields are weighted by Weights.
+type Index struct {
+	Collection string
+	Name       string
+	Keys       bson.D
+	Unique     bool
+	Weights    bson.M
+}
+
+// Indexes are the indexes used by the queries of the store, along with the text indexes used by Search
+var Indexes = []Index{
+	{
+		Collection: "datasets",
+		Name:       "datasets_search",
+		Keys: bson.D{
+			{Key: "current.title", Value: "text"},
+			{Key: "current.keywords", Value: "text"},
+			{Key: "current.tables.title", Value: "text"},
+			{Key: "current.description", Value: "text"},
+		},
+		Weights: bson.M{
+			"current.title":        models.SearchFieldWeights[models.SearchFieldTitle],
+			"current.keywords":     models.SearchFieldWeights[models.SearchFieldKeywords],
+			"current.tables.title": models.SearchFieldWeights[models.SearchFieldTables],
+			"current.description":  models.SearchFieldWeights[models.SearchFieldDescription],
+		},
+	},
+	{
+		Collection: editionsCollection,
+		Name:       "editions_next",
+		Keys:       bson.D{{Key: "next.links.dataset.id", Value: 1}, {Key: "next.edition", Value: 1}},
+		Unique:     true,
+	},
+	{
+		Collection: editionsCollection,
+		Name:       "editions_current",
+		Keys:       bson.D{{Key: "current.links.dataset.id", Value: 1}, {Key: "current.edition", Value: 1}, {Key: "current.state", Value: 1}},
+	},
+	{
+		Collection: instanceCollection,
+		Name:       "instances_id",
+		Keys:       bson.D{{Key: "id", Value: 1}},
+		Unique:     true,
+	},
+	{
+		Collection: instanceCollection,
+		Name:       "instances_dataset_edition_version",
+		Keys:       bson.D{{Key: "links.dataset.id", Value: 1}, {Key: "edition", Value: 1}, {Key: "version", Value: 1}},
+	},
+	{
+		Collection: instanceCollection,
+		Name:       "instances_search",
+		Keys:       bson.D{{Key: "dimensions.label", Value: "text"}, {Key: "dimensions.name", Value: "text"}},
+		Weights: bson.M{
+			"dimensions.label": models.SearchFieldWeights[models.SearchFieldDimensions],
+			"dimensions.name":  models.SearchFieldWeights[models.SearchFieldDimensions],
+		},
+	},
+	{
+		// also serves the $match of the aggregation in GetDimensions, and the sort of options within a dimension
+		Collection: dimensionOptions,
+		Name:       "dimension_options_instance_name_option",
+		Keys:       bson.D{{Key: "instance_id", Value: 1}, {Key: "name", Value: 1}, {Key: "option", Value: 1}},
+	},
+}
+
+// Kinds of difference between the declared indexes and those of the database
+const (
+	// IndexMissing is reported for a declared index that does not exist
+	IndexMissing = "missing"
+	// IndexDifferent is reported for a declared index that exists with different keys or options, or under another
+	// name
+	IndexDifferent = "different"
+	// IndexUndeclared is reported for an index of the database that is not declared
+	IndexUndeclared = "undeclared"
+)
+
+// IndexDrift is a difference between the declared indexes and those of the database
+type IndexDrift struct {
+	Collection string
+	Name       string
+	Kind       string
+	Detail     string
+}
+
+func (d IndexDrift) String() string {
+	if d.Detail == "" {
+		return fmt.Sprintf("%s index %s.%s", d.Kind, d.Collection, d.Name)
+	}
+
+	return fmt.Sprintf("%s index %s.%s: %s", d.Kind, d.Collection, d.Name, d.Detail)
+}
+
+// indexSpec is an index as listed by the database
+type indexSpec struct {
+	Name    string `bson:"name"`
+	Key     bson.D `bson:"key"`
+	Unique  bool   `bson:"unique"`
+	Weights bson.M `bson:"weights"`
+}
+
+// CheckIndexes compares the indexes of the database with those declared, returning the differences
+func (m *Mongo) CheckIndexes(ctx context.Context, indexes []Index) ([]IndexDrift, error) {
+	var drift []IndexDrift
+	for _, collection := range indexCollections(indexes) {
+		existing, err := m.listIndexes(ctx, collection)
+		if err != nil {
+			return nil, err
+		}
+
+		drift = append(drift, indexDrift(collection, declaredIndexes(indexes, collection), existing)...)
+	}
+
+	return drift, nil
+}
+
+// EnsureIndexes creates the declared indexes that do not exist, returning the differences that remain. Indexes
+// that exist with different keys or options are left for an operator to rebuild, as that requires them to be
+// dropped first. Unique indexes are only created when unique is set, as building one fails if any documents
+// already share its keys, so they are otherwise returned as missing.
+func (m *Mongo) EnsureIndexes(ctx context.Context, indexes []Index, unique bool) ([]IndexDrift, error) {
+	drift, err := m.CheckIndexes(ctx, indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	missing, remaining := missingIndexes(indexes, drift, unique)
+	for _, collection := range indexCollections(indexes) {
+		if len(missing[collection]) == 0 {
+			continue
+		}
+
+		indexModels := make([]mongodriver.IndexModel, 0, len(missing[collection]))
+		for _, index := range missing[collection] {
+			indexModels = append(indexModels, index.model())
+		}
+
+		if _, err = m.collection(collection).Indexes().CreateMany(ctx, indexModels); err != nil {
+			return nil, err
+		}
+	}
+
+	return remaining, nil
+}
+
+// missingIndexes returns the declared indexes to create for the differences found, by collection, along with the
+// differences that creating them leaves. Missing unique indexes are left unless unique is set.
+func missingIndexes(indexes []Index, drift []IndexDrift, unique bool) (map[string][]Index, []IndexDrift) {
+	missing := make(map[string][]Index)
+	var remaining []IndexDrift
+	for _, d := range drift {
+		if d.Kind != IndexMissing {
+			remaining = append(remaining, d)
+			continue
+		}
+
+		for _, index := range indexes {
+			if index.Collection != d.Collection || index.Name != d.Name {
+				continue
+			}
+
+			if index.Unique && !unique {
+				remaining = append(remaining, d)
+				continue
+			}
+
+			missing[d.Collection] = append(missing[d.Collection], index)
+		}
+	}
+
+	return missing, remaining
+}
+
+// model returns the model used to create the index
+func (index Index) model() mongodriver.IndexModel {
+	opts := options.Index().SetName(index.Name)
+	if index.Unique {
+		opts.SetUnique(true)
+	}
+
+	if index.Weights != nil {
+		opts.SetWeights(index.Weights)
+	}
+
+	return mongodriver.IndexModel{Keys: index.Keys, Options: opts}
+}
+
+// listIndexes returns the indexes of a collection, which has none if it does not yet exist
+func (m *Mongo) listIndexes(ctx context.Context, collection string) ([]indexSpec, error) {
+	cursor, err := m.collection(collection).Indexes().List(ctx)
+	if err != nil {
+		if cmdErr, ok := err.(mongodriver.CommandError); ok && cmdErr.Name == "NamespaceNotFound" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var specs []indexSpec
+	if err = cursor.All(ctx, &specs); err != nil {
+		return nil, err
+	}
+
+	return specs, nil
+}
+
+// indexCollections returns the collections of the indexes, in the order they are first declared
+func indexCollections(indexes []Index) []string {
+	var collections []string
+	seen := make(map[string]bool)
+	for _, index := range indexes {
+		if !seen[index.Collection] {
+			seen[index.Collection] = true
+			collections = append(collections, index.Collection)
+		}
+	}
+
+	return collections
+}
+
+// declaredIndexes returns the indexes of a collection
+func declaredIndexes(indexes []Index, collection string) []Index {
+	var declared []Index
+	for _, index := range indexes {
+		if index.Collection == collection {
+			declared = append(declared, index)
+		}
+	}
+
+	return declared
+}
+
+// indexDrift compares the declared indexes of a collection with those that exist. A declared index is matched by
+// name, or failing that by its keys and options, so that an index created under another name is not duplicated.
+func indexDrift(collection string, declared []Index, existing []indexSpec) []IndexDrift {
+	var drift []IndexDrift
+	matched := make(map[string]bool)
+
+	for _, index := range declared {
+		spec, found := findIndexSpec(existing, func(spec indexSpec) bool { return spec.Name == index.Name })
+		if found {
+			matched[spec.Name] = true
+			if !index.matches(spec) {
+				drift = append(drift, IndexDrift{Collection: collection, Name: index.Name, Kind: IndexDifferent, Detail: "keys or options differ"})
+			}
+			continue
+		}
+
+		spec, found = findIndexSpec(existing, index.matches)
+		if found {
+			matched[spec.Name] = true
+			drift = append(drift, IndexDrift{Collection: collection, Name: index.Name, Kind: IndexDifferent, Detail: "exists as " + spec.Name})
+			continue
+		}
+
+		drift = append(drift, IndexDrift{Collection: collection, Name: index.Name, Kind: IndexMissing})
+	}
+
+	for _, spec := range existing {
+		if spec.Name != "_id_" && !matched[spec.Name] {
+			drift = append(drift, IndexDrift{Collection: collection, Name: spec.Name, Kind: IndexUndeclared})
+		}
+	}
+
+	return drift
+}
+
+func findIndexSpec(specs []indexSpec, match func(spec indexSpec) bool) (indexSpec, bool) {
+	for _, spec := range specs {
+		if match(spec) {
+			return spec, true
+		}
+	}
+
+	return indexSpec{}, false
+}
+
+// matches checks whether an index of the database has the keys and options of the declared index. The fields of
+// a text index are listed as its weights rather than its keys. Numbers are compared by value, as the database may
+// return them as a different type than declared.
+func (index Index) matches(spec indexSpec) bool {
+	if index.Unique != spec.Unique {
+		return false
+	}
+
+	if index.isText() {
+		if len(index.Weights) != len(spec.Weights) {
+			return false
+		}
+
+		for field, weight := range index.Weights {
+			if fmt.Sprint(spec.Weights[field]) != fmt.Sprint(weight) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	if len(index.Keys) != len(spec.Key) {
+		return false
+	}
+
+	for i, key := range index.Keys {
+		if spec.Key[i].Key != key.Key || fmt.Sprint(spec.Key[i].Value) != fmt.Sprint(key.Value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (index Index) isText() bool {
+	for _, key := range index.Keys {
+		if key.Value == "text" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,156 @@
+package mongo
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestIndexDrift(t *testing.T) {
+
+	Convey("Given a collection with a declared compound index and a declared text index", t, func() {
+		declared := []Index{
+			{
+				Collection: "instances",
+				Name:       "instances_dataset_edition_version",
+				Keys:       bson.D{{Key: "links.dataset.id", Value: 1}, {Key: "edition", Value: 1}, {Key: "version", Value: 1}},
+			},
+			{
+				Collection: "instances",
+				Name:       "instances_search",
+				Keys:       bson.D{{Key: "dimensions.label", Value: "text"}},
+				Weights:    bson.M{"dimensions.label": 2},
+			},
+		}
+
+		compound := indexSpec{
+			Name: "instances_dataset_edition_version",
+			Key:  bson.D{{Key: "links.dataset.id", Value: int32(1)}, {Key: "edition", Value: int32(1)}, {Key: "version", Value: int32(1)}},
+		}
+		text := indexSpec{
+			Name:    "instances_search",
+			Key:     bson.D{{Key: "_fts", Value: "text"}, {Key: "_ftsx", Value: int32(1)}},
+			Weights: bson.M{"dimensions.label": int32(2)},
+		}
+		id := indexSpec{Name: "_id_", Key: bson.D{{Key: "_id", Value: int32(1)}}}
+
+		Convey("When the database has the declared indexes", func() {
+			drift := indexDrift("instances", declared, []indexSpec{id, compound, text})
+
+			Convey("Then there is no drift", func() {
+				So(drift, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the database has no indexes", func() {
+			drift := indexDrift("instances", declared, nil)
+
+			Convey("Then both indexes are missing", func() {
+				So(drift, ShouldResemble, []IndexDrift{
+					{Collection: "instances", Name: "instances_dataset_edition_version", Kind: IndexMissing},
+					{Collection: "instances", Name: "instances_search", Kind: IndexMissing},
+				})
+			})
+		})
+
+		Convey("When an index exists with different keys", func() {
+			compound.Key = bson.D{{Key: "links.dataset.id", Value: int32(1)}, {Key: "edition", Value: int32(1)}}
+			text.Weights = bson.M{"dimensions.label": int32(1)}
+			drift := indexDrift("instances", declared, []indexSpec{compound, text})
+
+			Convey("Then they are reported as different", func() {
+				So(drift, ShouldHaveLength, 2)
+				So(drift[0].Kind, ShouldEqual, IndexDifferent)
+				So(drift[0].Name, ShouldEqual, "instances_dataset_edition_version")
+				So(drift[1].Kind, ShouldEqual, IndexDifferent)
+				So(drift[1].Name, ShouldEqual, "instances_search")
+			})
+		})
+
+		Convey("When an index exists with different options", func() {
+			compound.Unique = true
+			drift := indexDrift("instances", declared, []indexSpec{compound, text})
+
+			Convey("Then it is reported as different", func() {
+				So(drift, ShouldResemble, []IndexDrift{
+					{Collection: "instances", Name: "instances_dataset_edition_version", Kind: IndexDifferent, Detail: "keys or options differ"},
+				})
+			})
+		})
+
+		Convey("When an index exists under another name", func() {
+			compound.Name = "links.dataset.id_1_edition_1_version_1"
+			drift := indexDrift("instances", declared, []indexSpec{compound, text})
+
+			Convey("Then it is reported as different rather than missing or undeclared", func() {
+				So(drift, ShouldResemble, []IndexDrift{
+					{Collection: "instances", Name: "instances_dataset_edition_version", Kind: IndexDifferent, Detail: "exists as links.dataset.id_1_edition_1_version_1"},
+				})
+			})
+		})
+
+		Convey("When the database has an index that is not declared", func() {
+			state := indexSpec{Name: "state_1", Key: bson.D{{Key: "state", Value: int32(1)}}}
+			drift := indexDrift("instances", declared, []indexSpec{id, compound, text, state})
+
+			Convey("Then it is reported as undeclared", func() {
+				So(drift, ShouldResemble, []IndexDrift{
+					{Collection: "instances", Name: "state_1", Kind: IndexUndeclared},
+				})
+			})
+		})
+	})
+}
+
+func TestMissingIndexes(t *testing.T) {
+
+	Convey("Given a missing unique index, a missing index and an index that differs", t, func() {
+		indexes := []Index{
+			{Collection: "editions", Name: "editions_next", Keys: bson.D{{Key: "next.links.dataset.id", Value: 1}, {Key: "next.edition", Value: 1}}, Unique: true},
+			{Collection: "editions", Name: "editions_state", Keys: bson.D{{Key: "current.state", Value: 1}}},
+			{Collection: "instances", Name: "instances_search", Keys: bson.D{{Key: "dimensions.label", Value: "text"}}, Weights: bson.M{"dimensions.label": 2}},
+		}
+		unique := IndexDrift{Collection: "editions", Name: "editions_next", Kind: IndexMissing}
+		different := IndexDrift{Collection: "instances", Name: "instances_search", Kind: IndexDifferent, Detail: "keys or options differ"}
+		drift := []IndexDrift{unique, {Collection: "editions", Name: "editions_state", Kind: IndexMissing}, different}
+
+		Convey("When unique indexes are not to be created", func() {
+			missing, remaining := missingIndexes(indexes, drift, false)
+
+			Convey("Then only the index that is not unique is created", func() {
+				So(missing, ShouldResemble, map[string][]Index{"editions": {indexes[1]}})
+				So(remaining, ShouldResemble, []IndexDrift{unique, different})
+			})
+		})
+
+		Convey("When unique indexes are to be created", func() {
+			missing, remaining := missingIndexes(indexes, drift, true)
+
+			Convey("Then both missing indexes are created", func() {
+				So(missing, ShouldResemble, map[string][]Index{"editions": {indexes[0], indexes[1]}})
+				So(remaining, ShouldResemble, []IndexDrift{different})
+			})
+		})
+	})
+}
+
+func TestIndexes(t *testing.T) {
+
+	Convey("Given the declared indexes", t, func() {
+		Convey("Then each has a name that is unique within its collection", func() {
+			names := make(map[string]bool)
+			for _, index := range Indexes {
+				So(index.Name, ShouldNotBeEmpty)
+				So(names[index.Collection+"."+index.Name], ShouldBeFalse)
+				names[index.Collection+"."+index.Name] = true
+			}
+		})
+
+		Convey("Then only text indexes are weighted", func() {
+			for _, index := range Indexes {
+				So(index.Weights != nil, ShouldEqual, index.isText())
+			}
+		})
+	})
+}
@@ -2,21 +2,20 @@ package mongo
 
 import (
 	"context"
+	"time"
 
 	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
 	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
 	"github.com/ONSdigital/log.go/log"
-	"github.com/globalsign/mgo"
-	"github.com/globalsign/mgo/bson"
+	"go.mongodb.org/mongo-driver/bson"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const instanceCollection = "instances"
 
 // GetInstances from a mongo collection
 func (m *Mongo) GetInstances(ctx context.Context, states []string, datasets []string) (*models.InstanceResults, error) {
-	s := m.Session.Copy()
-	defer s.Close()
-
 	filter := bson.M{}
 	if len(states) > 0 {
 		filter["state"] = bson.M{"$in": states}
@@ -26,19 +25,14 @@ func (m *Mongo) GetInstances(ctx context.Context, states []string, datasets []st
 		filter["links.dataset.id"] = bson.M{"$in": datasets}
 	}
 
-	iter := s.DB(m.Database).C(instanceCollection).Find(filter).Sort("-$natural").Iter()
-	defer func() {
-		err := iter.Close()
-		if err != nil {
-			log.Event(ctx, "error closing iterator", log.ERROR, log.Error(err), log.Data{"state_query": states, "dataset_query": datasets})
-		}
-	}()
+	cursor, err := m.collection(instanceCollection).Find(ctx, filter, options.Find().SetSort(bson.M{"$natural": -1}))
+	if err != nil {
+		return nil, err
+	}
 
 	results := []models.Instance{}
-	if err := iter.All(&results); err != nil {
-		if err == mgo.ErrNotFound {
-			return nil, errs.ErrDatasetNotFound
-		}
+	if err = cursor.All(ctx, &results); err != nil {
+		log.Event(ctx, "error reading instances", log.ERROR, log.Error(err), log.Data{"state_query": states, "dataset_query": datasets})
 		return nil, err
 	}
 
@@ -46,16 +40,21 @@ func (m *Mongo) GetInstances(ctx context.Context, states []string, datasets []st
 }
 
 // GetInstance returns a single instance from an ID
-func (m *Mongo) GetInstance(ID string) (*models.Instance, error) {
-	s := m.Session.Copy()
-	defer s.Close()
-
+func (m *Mongo) GetInstance(ctx context.Context, ID string) (*models.Instance, error) {
 	var instance models.Instance
-	err := s.DB(m.Database).C(instanceCollection).Find(bson.M{"id": ID}).One(&instance)
+	err := m.collection(instanceCollection).FindOne(ctx, bson.M{"id": ID}).Decode(&instance)
 
-	if err == mgo.ErrNotFound {
+	if err == mongodriver.ErrNoDocuments {
 		return nil, errs.ErrInstanceNotFound
 	}
 
 	return &instance, err
 }
+
+// AddVersion upserts a version document into the instances collection, replacing any version with the same id
+func (m *Mongo) AddVersion(ctx context.Context, version *models.Version) error {
+	version.LastUpdated = time.Now().UTC()
+
+	_, err := m.collection(instanceCollection).ReplaceOne(ctx, bson.M{"id": version.ID}, version, options.Replace().SetUpsert(true))
+	return err
+}
@@ -0,0 +1,279 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const migrationsCollection = "migrations"
+
+// Migration is a change to the documents of the database, which is applied once, in order of version. Up returns
+// the number of documents changed, or with dryRun the number that would be changed without changing them. It must
+// be safe to run again should it fail part way, as it is only recorded as applied once it succeeds.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, m *Mongo, dryRun bool) (int, error)
+}
+
+// Migrations are the migrations of the documents of the store, in order of version. New migrations are appended
+// with the next version, and never removed or renumbered once released.
+var Migrations = []Migration{
+	{
+		Version:     1,
+		Description: "convert the sizes of version downloads from strings to int64",
+		Up:          migrateDownloadSizes,
+	},
+	{
+		Version:     2,
+		Description: "convert the release dates of versions to a single UTC layout",
+		Up:          migrateReleaseDates,
+	},
+}
+
+// MigrationResult is the outcome of running a migration
+type MigrationResult struct {
+	Version     int
+	Description string
+	Documents   int
+	DryRun      bool
+}
+
+// migrationRecord is the document recording that a migration has been applied
+type migrationRecord struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	Documents   int       `bson:"documents"`
+	AppliedAt   time.Time `bson:"applied_at"`
+}
+
+// PendingMigrations returns the migrations that have not been applied, in order of version
+func (m *Mongo) PendingMigrations(ctx context.Context, migrations []Migration) ([]Migration, error) {
+	if err := validateMigrations(migrations); err != nil {
+		return nil, err
+	}
+
+	cursor, err := m.collection(migrationsCollection).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	var records []migrationRecord
+	if err = cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]bool)
+	for _, record := range records {
+		applied[record.Version] = true
+	}
+
+	var pending []Migration
+	for _, migration := range migrations {
+		if !applied[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+
+	return pending, nil
+}
+
+// Migrate runs the migrations that have not been applied, in order of version, recording each once it succeeds.
+// With dryRun, the migrations report the documents they would change without changing them, and are not recorded.
+// Migration stops at the first that fails, returning the results of those that ran before it.
+func (m *Mongo) Migrate(ctx context.Context, migrations []Migration, dryRun bool) ([]MigrationResult, error) {
+	pending, err := m.PendingMigrations(ctx, migrations)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []MigrationResult
+	for _, migration := range pending {
+		documents, err := migration.Up(ctx, m, dryRun)
+		if err != nil {
+			return results, fmt.Errorf("migration %d failed: %v", migration.Version, err)
+		}
+
+		if !dryRun {
+			record := migrationRecord{
+				Version:     migration.Version,
+				Description: migration.Description,
+				Documents:   documents,
+				AppliedAt:   time.Now().UTC(),
+			}
+			if _, err = m.collection(migrationsCollection).InsertOne(ctx, record); err != nil {
+				return results, fmt.Errorf("failed to record migration %d: %v", migration.Version, err)
+			}
+		}
+
+		results = append(results, MigrationResult{
+			Version:     migration.Version,
+			Description: migration.Description,
+			Documents:   documents,
+			DryRun:      dryRun,
+		})
+	}
+
+	return results, nil
+}
+
+// validateMigrations checks that migrations are in strictly increasing order of version, starting from 1
+func validateMigrations(migrations []Migration) error {
+	previous := 0
+	for _, migration := range migrations {
+		if migration.Version <= previous {
+			return fmt.Errorf("migration %d is out of order, following migration %d", migration.Version, previous)
+		}
+
+		if migration.Up == nil {
+			return fmt.Errorf("migration %d has no up function", migration.Version)
+		}
+
+		previous = migration.Version
+	}
+
+	return nil
+}
+
+// downloadSizeFields are the fields holding the sizes of the downloads of a version
+var downloadSizeFields = []string{"downloads.csv.size", "downloads.csvw.size", "downloads.xls.size"}
+
+// migrateDownloadSizes converts the sizes of the downloads of versions, which were stored as strings, to int64.
+// Versions whose sizes are not numbers are reported rather than changed, so that they can be corrected by hand.
+func migrateDownloadSizes(ctx context.Context, m *Mongo, dryRun bool) (int, error) {
+	type sizes struct {
+		ID        interface{} `bson:"_id"`
+		Downloads map[string]struct {
+			Size interface{} `bson:"size"`
+		} `bson:"downloads"`
+	}
+
+	var or []bson.M
+	for _, field := range downloadSizeFields {
+		or = append(or, bson.M{field: bson.M{"$type": "string"}})
+	}
+
+	projection := bson.M{}
+	for _, field := range downloadSizeFields {
+		projection[field] = 1
+	}
+
+	cursor, err := m.collection(instanceCollection).Find(ctx, bson.M{"$or": or}, options.Find().SetProjection(projection))
+	if err != nil {
+		return 0, err
+	}
+
+	var docs []sizes
+	if err = cursor.All(ctx, &docs); err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	var invalid []string
+	for _, doc := range docs {
+		set := bson.M{}
+		filter := bson.M{"_id": doc.ID}
+		for format, download := range doc.Downloads {
+			s, ok := download.Size.(string)
+			if !ok {
+				continue
+			}
+
+			size, err := models.ParseDownloadSize(s)
+			if err != nil {
+				invalid = append(invalid, fmt.Sprintf("%v %s", doc.ID, format))
+				continue
+			}
+
+			field := "downloads." + format + ".size"
+			filter[field] = s
+			set[field] = size
+		}
+
+		if len(set) == 0 {
+			continue
+		}
+
+		if !dryRun {
+			// the current sizes are matched so that a concurrent update of the downloads is not overwritten
+			if _, err = m.collection(instanceCollection).UpdateOne(ctx, filter, bson.M{"$set": set}); err != nil {
+				return changed, err
+			}
+		}
+		changed++
+	}
+
+	if len(invalid) > 0 {
+		return changed, fmt.Errorf("download sizes are not numbers: %s", strings.Join(invalid, ", "))
+	}
+
+	return changed, nil
+}
+
+// legacyReleaseDateLayout is the layout of the release dates written by the upload-datasets script before they
+// were validated
+const legacyReleaseDateLayout = "02/01/2006"
+
+// migrateReleaseDates converts the release dates of versions into models.ReleaseDateLayout, so that they can be
+// filtered and sorted as strings. Versions whose release dates cannot be read are reported rather than changed, so
+// that they can be corrected by hand.
+func migrateReleaseDates(ctx context.Context, m *Mongo, dryRun bool) (int, error) {
+	selector := bson.M{"release_date": bson.M{"$exists": true, "$ne": ""}}
+	cursor, err := m.collection(instanceCollection).Find(ctx, selector, options.Find().SetProjection(bson.M{"release_date": 1}))
+	if err != nil {
+		return 0, err
+	}
+
+	var docs []struct {
+		ID          interface{} `bson:"_id"`
+		ReleaseDate string      `bson:"release_date"`
+	}
+	if err = cursor.All(ctx, &docs); err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	var invalid []string
+	for _, doc := range docs {
+		releaseDate, err := normaliseStoredReleaseDate(doc.ReleaseDate)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%v", doc.ID))
+			continue
+		}
+
+		if releaseDate == doc.ReleaseDate {
+			continue
+		}
+
+		if !dryRun {
+			// the current release date is matched so that a concurrent update of the version is not overwritten
+			filter := bson.M{"_id": doc.ID, "release_date": doc.ReleaseDate}
+			if _, err = m.collection(instanceCollection).UpdateOne(ctx, filter, bson.M{"$set": bson.M{"release_date": releaseDate}}); err != nil {
+				return changed, err
+			}
+		}
+		changed++
+	}
+
+	if len(invalid) > 0 {
+		return changed, fmt.Errorf("release dates are not timestamps or dates: %s", strings.Join(invalid, ", "))
+	}
+
+	return changed, nil
+}
+
+// normaliseStoredReleaseDate converts a stored release date into models.ReleaseDateLayout, reading those written in
+// legacyReleaseDateLayout as well as those the API accepts
+func normaliseStoredReleaseDate(releaseDate string) (string, error) {
+	if t, err := time.Parse(legacyReleaseDateLayout, releaseDate); err == nil {
+		return t.Format(models.ReleaseDateLayout), nil
+	}
+
+	return models.NormaliseReleaseDate(releaseDate)
+}
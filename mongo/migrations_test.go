@@ -0,0 +1,157 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestValidateMigrations(t *testing.T) {
+	up := func(ctx context.Context, m *Mongo, dryRun bool) (int, error) { return 0, nil }
+
+	Convey("Given the declared migrations", t, func() {
+		Convey("Then they are in order", func() {
+			So(validateMigrations(Migrations), ShouldBeNil)
+		})
+	})
+
+	Convey("Given migrations that are not in order of version", t, func() {
+		migrations := []Migration{{Version: 2, Up: up}, {Version: 1, Up: up}}
+
+		Convey("Then they are invalid", func() {
+			So(validateMigrations(migrations), ShouldNotBeNil)
+		})
+	})
+
+	Convey("Given migrations with the same version", t, func() {
+		migrations := []Migration{{Version: 1, Up: up}, {Version: 1, Up: up}}
+
+		Convey("Then they are invalid", func() {
+			So(validateMigrations(migrations), ShouldNotBeNil)
+		})
+	})
+
+	Convey("Given a migration without an up function", t, func() {
+		migrations := []Migration{{Version: 1}}
+
+		Convey("Then it is invalid", func() {
+			So(validateMigrations(migrations), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestNormaliseStoredReleaseDate(t *testing.T) {
+
+	Convey("Given the release dates stored by versions", t, func() {
+		Convey("Then dates in the legacy layout are read as the start of the day", func() {
+			releaseDate, err := normaliseStoredReleaseDate("22/03/2012")
+			So(err, ShouldBeNil)
+			So(releaseDate, ShouldEqual, "2012-03-22T00:00:00.000Z")
+		})
+
+		Convey("Then timestamps and dates are converted to UTC", func() {
+			releaseDate, err := normaliseStoredReleaseDate("2013-01-30T10:30:00+01:00")
+			So(err, ShouldBeNil)
+			So(releaseDate, ShouldEqual, "2013-01-30T09:30:00.000Z")
+
+			releaseDate, err = normaliseStoredReleaseDate("2021-03-21")
+			So(err, ShouldBeNil)
+			So(releaseDate, ShouldEqual, "2021-03-21T00:00:00.000Z")
+		})
+
+		Convey("Then a release date that is not a date is an error", func() {
+			_, err := normaliseStoredReleaseDate("next spring")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+// TestMigrate runs the migrations against the mongo deployment at MONGODB_TEST_BIND_ADDR
+func TestMigrate(t *testing.T) {
+	m := newTestMongo(t)
+	defer closeTestMongo(t, m)
+	ctx := context.Background()
+
+	Convey("Given a version whose download sizes are stored as strings and release date in the legacy layout", t, func() {
+		dropTestDatabase(t, m)
+		_, err := m.collection(instanceCollection).InsertOne(ctx, bson.M{
+			"id":           "instance-1",
+			"release_date": "22/03/2012",
+			"downloads": bson.M{
+				"csv": bson.M{"href": "http://localhost:23600/downloads/people.csv", "size": "1024"},
+				"xls": bson.M{"href": "http://localhost:23600/downloads/people.xls", "size": "5000000000"},
+			},
+		})
+		So(err, ShouldBeNil)
+
+		Convey("When the migrations are dry run", func() {
+			results, err := m.Migrate(ctx, Migrations, true)
+
+			Convey("Then the version would be changed but is not", func() {
+				So(err, ShouldBeNil)
+				So(results, ShouldHaveLength, len(Migrations))
+				So(results[0].Documents, ShouldEqual, 1)
+				So(results[1].Documents, ShouldEqual, 1)
+
+				pending, err := m.PendingMigrations(ctx, Migrations)
+				So(err, ShouldBeNil)
+				So(pending, ShouldHaveLength, len(Migrations))
+
+				var doc bson.M
+				So(m.collection(instanceCollection).FindOne(ctx, bson.M{"id": "instance-1"}).Decode(&doc), ShouldBeNil)
+				So(doc["downloads"].(bson.M)["csv"].(bson.M)["size"], ShouldEqual, "1024")
+			})
+		})
+
+		Convey("When the migrations are applied", func() {
+			results, err := m.Migrate(ctx, Migrations, false)
+
+			Convey("Then the sizes are numbers, the release date is normalised and the migrations are recorded", func() {
+				So(err, ShouldBeNil)
+				So(results, ShouldHaveLength, len(Migrations))
+				So(results[0].Documents, ShouldEqual, 1)
+				So(results[1].Documents, ShouldEqual, 1)
+
+				var instance models.Instance
+				So(m.collection(instanceCollection).FindOne(ctx, bson.M{"id": "instance-1"}).Decode(&instance), ShouldBeNil)
+				So(instance.Downloads.CSV.Size, ShouldEqual, int64(1024))
+				So(instance.Downloads.XLS.Size, ShouldEqual, int64(5000000000))
+				So(instance.ReleaseDate, ShouldEqual, "2012-03-22T00:00:00.000Z")
+
+				pending, err := m.PendingMigrations(ctx, Migrations)
+				So(err, ShouldBeNil)
+				So(pending, ShouldBeEmpty)
+			})
+
+			Convey("Then running them again does nothing", func() {
+				results, err := m.Migrate(ctx, Migrations, false)
+				So(err, ShouldBeNil)
+				So(results, ShouldBeEmpty)
+			})
+		})
+	})
+
+	Convey("Given a version whose download size is not a number", t, func() {
+		dropTestDatabase(t, m)
+		_, err := m.collection(instanceCollection).InsertOne(ctx, bson.M{
+			"id":        "instance-1",
+			"downloads": bson.M{"csv": bson.M{"size": "1kB"}},
+		})
+		So(err, ShouldBeNil)
+
+		Convey("When the migrations are applied", func() {
+			_, err := m.Migrate(ctx, Migrations, false)
+
+			Convey("Then an error is returned and the migration is not recorded", func() {
+				So(err, ShouldNotBeNil)
+
+				pending, err := m.PendingMigrations(ctx, Migrations)
+				So(err, ShouldBeNil)
+				So(pending, ShouldHaveLength, len(Migrations))
+			})
+		})
+	})
+}
@@ -0,0 +1,164 @@
+package mongo
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/config"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store/storertest"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+func TestNewClientOptions(t *testing.T) {
+
+	Convey("Given the configuration of a replica set requiring authentication", t, func() {
+		cfg := config.MongoConfig{
+			AuthSource:      "admin",
+			BindAddr:        "localhost:27017",
+			ConnectTimeout:  time.Second,
+			MaxConnIdleTime: time.Minute,
+			MaxPoolSize:     50,
+			MinPoolSize:     5,
+			Password:        "secret",
+			ReadPreference:  "secondaryPreferred",
+			Username:        "ftb",
+		}
+
+		Convey("When the client options are built", func() {
+			opts, err := NewClientOptions(cfg)
+
+			Convey("Then the pool, read preference and credentials are set", func() {
+				So(err, ShouldBeNil)
+				So(opts.Hosts, ShouldResemble, []string{"localhost:27017"})
+				So(*opts.MaxPoolSize, ShouldEqual, 50)
+				So(*opts.MinPoolSize, ShouldEqual, 5)
+				So(*opts.MaxConnIdleTime, ShouldEqual, time.Minute)
+				So(*opts.ConnectTimeout, ShouldEqual, time.Second)
+				So(opts.ReadPreference.Mode(), ShouldEqual, readpref.SecondaryPreferredMode)
+				So(opts.Auth.Username, ShouldEqual, "ftb")
+				So(opts.Auth.Password, ShouldEqual, "secret")
+				So(opts.Auth.AuthSource, ShouldEqual, "admin")
+				So(opts.TLSConfig, ShouldBeNil)
+			})
+		})
+
+		Convey("When the bind address is a connection string", func() {
+			cfg.BindAddr = "mongodb://mongo-1:27017,mongo-2:27017/?replicaSet=rs0"
+			opts, err := NewClientOptions(cfg)
+
+			Convey("Then the options of the connection string are kept", func() {
+				So(err, ShouldBeNil)
+				So(opts.Hosts, ShouldResemble, []string{"mongo-1:27017", "mongo-2:27017"})
+				So(*opts.ReplicaSet, ShouldEqual, "rs0")
+			})
+		})
+
+		Convey("When TLS is enabled", func() {
+			cfg.EnableTLS = true
+			cfg.TLSInsecureSkipVerify = true
+			opts, err := NewClientOptions(cfg)
+
+			Convey("Then connections are made over TLS", func() {
+				So(err, ShouldBeNil)
+				So(opts.TLSConfig, ShouldNotBeNil)
+				So(opts.TLSConfig.InsecureSkipVerify, ShouldBeTrue)
+			})
+		})
+
+		Convey("When the CA file does not exist", func() {
+			cfg.EnableTLS = true
+			cfg.CAFilePath = "does-not-exist.pem"
+			_, err := NewClientOptions(cfg)
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("When the read preference is not known", func() {
+			cfg.ReadPreference = "fastest"
+			_, err := NewClientOptions(cfg)
+
+			Convey("Then an error is returned", func() {
+				So(err, ShouldNotBeNil)
+			})
+		})
+	})
+}
+
+func TestMongoTimestamp(t *testing.T) {
+
+	Convey("Given an instance with a unique timestamp", t, func() {
+		instance := models.Instance{InstanceID: "instance-1", UniqueTimestamp: primitive.Timestamp{T: 1600000000, I: 3}}
+
+		Convey("When it is encoded", func() {
+			b, err := bson.Marshal(instance)
+			So(err, ShouldBeNil)
+
+			Convey("Then the timestamp is written as a bson timestamp", func() {
+				var doc bson.M
+				So(bson.Unmarshal(b, &doc), ShouldBeNil)
+				So(doc["unique_timestamp"], ShouldResemble, primitive.Timestamp{T: 1600000000, I: 3})
+			})
+
+			Convey("Then it is decoded unchanged", func() {
+				var decoded models.Instance
+				So(bson.Unmarshal(b, &decoded), ShouldBeNil)
+				So(decoded.UniqueTimestamp, ShouldEqual, instance.UniqueTimestamp)
+			})
+		})
+	})
+}
+
+// TestStorer runs the behaviour suite shared by every store against the mongo deployment at
+// MONGODB_TEST_BIND_ADDR, using a database that is dropped before each test
+func TestStorer(t *testing.T) {
+	m := newTestMongo(t)
+	defer closeTestMongo(t, m)
+
+	storertest.Run(t, func() store.Storer {
+		dropTestDatabase(t, m)
+		return m
+	})
+}
+
+// newTestMongo connects to the mongo deployment at MONGODB_TEST_BIND_ADDR, skipping the test if it is not set
+func newTestMongo(t *testing.T) *Mongo {
+	bindAddr := os.Getenv("MONGODB_TEST_BIND_ADDR")
+	if bindAddr == "" {
+		t.Skip("MONGODB_TEST_BIND_ADDR is not set")
+	}
+
+	cfg := config.MongoConfig{
+		BindAddr:       bindAddr,
+		ConnectTimeout: 5 * time.Second,
+		Database:       "ftb-datasets-test",
+		ReadPreference: "primary",
+	}
+
+	m := &Mongo{Database: cfg.Database}
+	if err := m.Init(context.Background(), cfg); err != nil {
+		t.Fatalf("failed to connect to mongo: %v", err)
+	}
+
+	return m
+}
+
+// closeTestMongo drops the test database and disconnects
+func closeTestMongo(t *testing.T, m *Mongo) {
+	dropTestDatabase(t, m)
+	m.Close(context.Background())
+}
+
+func dropTestDatabase(t *testing.T, m *Mongo) {
+	if err := m.Client.Database(m.Database).Drop(context.Background()); err != nil {
+		t.Fatalf("failed to drop test database: %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package mongo
+
+import (
+	"context"
+	"math"
+	"strings"
+
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Search returns the published datasets matching any of the terms of the query, using the text indexes of the
+// datasets and instances collections. A dataset matched through the dimensions of its published versions has the
+// score of those versions added to its own.
+func (m *Mongo) Search(ctx context.Context, query *models.SearchQuery) (*models.SearchResults, error) {
+	terms := models.SearchTerms(query.Q)
+	if len(terms) == 0 {
+		return models.NewSearchResults(nil, query.Offset, query.Limit), nil
+	}
+
+	text := bson.M{"$search": strings.Join(terms, " ")}
+	score := bson.M{"$meta": "textScore"}
+
+	datasetFilter := bson.M{"current": bson.M{"$exists": true}}
+	if query.FTBType != "" {
+		datasetFilter["current.ftb_type"] = query.FTBType
+	}
+
+	var datasets []struct {
+		ID      string          `bson:"_id"`
+		Current *models.Dataset `bson:"current"`
+		Score   float64         `bson:"score"`
+	}
+	filter := bson.M{"$text": text}
+	for key, value := range datasetFilter {
+		filter[key] = value
+	}
+	if err := m.find(ctx, "datasets", filter, bson.M{"current": 1, "score": score}, &datasets); err != nil {
+		return nil, err
+	}
+
+	var versions []struct {
+		Links struct {
+			Dataset models.LinkObject `bson:"dataset"`
+		} `bson:"links"`
+		Score float64 `bson:"score"`
+	}
+	filter = bson.M{"$text": text, "state": models.PublishedState}
+	if err := m.find(ctx, instanceCollection, filter, bson.M{"links.dataset": 1, "score": score}, &versions); err != nil {
+		return nil, err
+	}
+
+	scores := make(map[string]float64)
+	docs := make(map[string]*models.SearchDocument)
+	for _, dataset := range datasets {
+		scores[dataset.ID] += dataset.Score
+		docs[dataset.ID] = newSearchDocument(dataset.ID, dataset.Current)
+	}
+
+	var missing []string
+	for _, version := range versions {
+		id := version.Links.Dataset.ID
+		if _, ok := scores[id]; !ok {
+			missing = append(missing, id)
+		}
+		scores[id] += version.Score
+	}
+
+	// datasets that were only matched through the dimensions of their versions
+	if len(missing) > 0 {
+		var others []struct {
+			ID      string          `bson:"_id"`
+			Current *models.Dataset `bson:"current"`
+		}
+		datasetFilter["_id"] = bson.M{"$in": missing}
+		if err := m.find(ctx, "datasets", datasetFilter, bson.M{"current": 1}, &others); err != nil {
+			return nil, err
+		}
+
+		for _, dataset := range others {
+			docs[dataset.ID] = newSearchDocument(dataset.ID, dataset.Current)
+		}
+	}
+
+	ids := make([]string, 0, len(docs))
+	for id := range docs {
+		ids = append(ids, id)
+	}
+
+	// the dimensions of the published versions are needed to filter and highlight the results
+	var published []models.Version
+	filter = bson.M{"links.dataset.id": bson.M{"$in": ids}, "state": models.PublishedState}
+	if err := m.find(ctx, instanceCollection, filter, bson.M{"dimensions": 1, "links.dataset": 1}, &published); err != nil {
+		return nil, err
+	}
+
+	for _, version := range published {
+		if version.Links != nil && version.Links.Dataset != nil {
+			if doc, ok := docs[version.Links.Dataset.ID]; ok {
+				doc.AddDimensions(version.Dimensions)
+			}
+		}
+	}
+
+	results := []models.SearchResult{}
+	for id, doc := range docs {
+		if query.Dimension != "" && !doc.HasDimension(query.Dimension) {
+			continue
+		}
+
+		results = append(results, models.NewSearchResult(doc, terms, math.Round(scores[id]*100)/100))
+	}
+
+	return models.NewSearchResults(results, query.Offset, query.Limit), nil
+}
+
+// find reads the projection of all the documents of a collection matching filter into results
+func (m *Mongo) find(ctx context.Context, collection string, filter, projection bson.M, results interface{}) error {
+	cursor, err := m.collection(collection).Find(ctx, filter, options.Find().SetProjection(projection))
+	if err != nil {
+		return err
+	}
+
+	return cursor.All(ctx, results)
+}
+
+func newSearchDocument(id string, dataset *models.Dataset) *models.SearchDocument {
+	if dataset.ID == "" {
+		dataset.ID = id
+	}
+
+	return &models.SearchDocument{Dataset: dataset}
+}
@@ -6,8 +6,13 @@ import (
 	"flag"
 	"os"
 
+	"strings"
+
 	"github.com/ONSdigital/log.go/log"
-	"gopkg.in/mgo.v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 )
 
 const (
@@ -24,8 +29,8 @@ var bindAddr string
 
 // Mongo represents a simplistic MongoDB configuration.
 type Mongo struct {
+	Client   *mongo.Client
 	Database string
-	Session  *mgo.Session
 	URI      string
 }
 
@@ -47,35 +52,36 @@ func main() {
 		URI:      bindAddr,
 	}
 
-	session, err := mongo.Init()
+	client, err := mongo.Init(ctx)
 	if err != nil {
 		log.Event(ctx, "unable to connect to mongo database", log.ERROR, log.Error(err), log.Data{"mongodb-bind-addr": bindAddr})
 		os.Exit(1)
 	}
 
-	mongo.Session = session
+	mongo.Client = client
+	defer client.Disconnect(ctx)
 
 	var hasFailed bool
 	// Delete all dimension options
-	if err := mongo.RemoveAllDocuments(dimOptionCollection); err != nil {
+	if err := mongo.RemoveAllDocuments(ctx, dimOptionCollection); err != nil {
 		log.Event(ctx, "failed to remove all documents", log.WARN, log.Error(err), log.Data{"collection": dimOptionCollection})
 		hasFailed = true
 	}
 
 	// Delete all versions
-	if err := mongo.RemoveAllDocuments(versionCollection); err != nil {
+	if err := mongo.RemoveAllDocuments(ctx, versionCollection); err != nil {
 		log.Event(ctx, "failed to remove all documents", log.WARN, log.Error(err), log.Data{"collection": versionCollection})
 		hasFailed = true
 	}
 
 	// Delete all editions
-	if err := mongo.RemoveAllDocuments(editionCollection); err != nil {
+	if err := mongo.RemoveAllDocuments(ctx, editionCollection); err != nil {
 		log.Event(ctx, "failed to remove all documents", log.WARN, log.Error(err), log.Data{"collection": editionCollection})
 		hasFailed = true
 	}
 
 	// Delete all datasets
-	if err := mongo.RemoveAllDocuments(datasetCollection); err != nil {
+	if err := mongo.RemoveAllDocuments(ctx, datasetCollection); err != nil {
 		log.Event(ctx, "failed to remove all documents", log.WARN, log.Error(err), log.Data{"collection": datasetCollection})
 		hasFailed = true
 	}
@@ -89,27 +95,34 @@ func main() {
 	log.Event(ctx, "Successfully removed all documents from ftb collections", log.INFO)
 }
 
-// Init creates a new mgo.Session with a strong consistency and a write mode of "majortiy".
-func (m *Mongo) Init() (session *mgo.Session, err error) {
-	if session != nil {
-		return nil, errors.New("session already exists")
+// Init connects to mongo with a write concern of "majority", accepting a bind address either with or without the
+// mongodb:// scheme.
+func (m *Mongo) Init(ctx context.Context) (*mongo.Client, error) {
+	if m.Client != nil {
+		return nil, errors.New("client already exists")
+	}
+
+	uri := m.URI
+	if !strings.HasPrefix(uri, "mongodb://") && !strings.HasPrefix(uri, "mongodb+srv://") {
+		uri = "mongodb://" + uri
 	}
 
-	if session, err = mgo.Dial(m.URI); err != nil {
+	opts := options.Client().ApplyURI(uri).SetWriteConcern(writeconcern.New(writeconcern.WMajority()))
+	client, err := mongo.Connect(ctx, opts)
+	if err != nil {
 		return nil, err
 	}
 
-	session.EnsureSafe(&mgo.Safe{WMode: "majority"})
-	session.SetMode(mgo.Strong, true)
+	if err = client.Ping(ctx, nil); err != nil {
+		client.Disconnect(ctx)
+		return nil, err
+	}
 
-	return session, nil
+	return client, nil
 }
 
 // RemoveAllDocuments deletes all documents from a collection
-func (m *Mongo) RemoveAllDocuments(collection string) (err error) {
-	s := m.Session.Copy()
-	defer s.Close()
-
-	_, err = s.DB(m.Database).C(collection).RemoveAll(nil)
+func (m *Mongo) RemoveAllDocuments(ctx context.Context, collection string) (err error) {
+	_, err = m.Client.Database(m.Database).Collection(collection).DeleteMany(ctx, bson.M{})
 	return
 }
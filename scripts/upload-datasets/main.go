@@ -18,8 +18,10 @@ import (
 	dphttp "github.com/ONSdigital/dp-net/http"
 	"github.com/ONSdigital/log.go/log"
 	uuid "github.com/satori/go.uuid"
-	"gopkg.in/mgo.v2"
-	"gopkg.in/mgo.v2/bson"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 )
 
 const (
@@ -90,13 +92,14 @@ func main() {
 		URI:         bindAddr,
 	}
 
-	session, err := mongo.Init()
+	client, err := mongo.Init(ctx)
 	if err != nil {
 		log.Event(ctx, "unable to connect to mongo database", log.ERROR, log.Error(err), log.Data{"mongodb-bind-addr": bindAddr})
 		os.Exit(1)
 	}
 
-	mongo.Session = session
+	mongo.Client = client
+	defer client.Disconnect(ctx)
 
 	cli := dphttp.NewClient()
 	api := NewFTBAPI(cli, ftbHost)
@@ -252,7 +255,7 @@ func (api *API) createFTBDatasetBlob(ctx context.Context, mongo Mongo, ftbBlob *
 
 			// Do a bulk upload of 500 documents at a time to speed the process of loading data into mongo db
 			if len(options) == 500 {
-				if err = mongo.BulkInsertDimensionOptions(options); err != nil {
+				if err = mongo.BulkInsertDimensionOptions(ctx, options); err != nil {
 					log.Event(ctx, "failed to add dimension options in bulk request to mongo db", log.ERROR, log.Error(err))
 					return
 				}
@@ -263,7 +266,7 @@ func (api *API) createFTBDatasetBlob(ctx context.Context, mongo Mongo, ftbBlob *
 
 		// Add leftover docs to mongo
 		if len(options) != 0 {
-			if err = mongo.BulkInsertDimensionOptions(options); err != nil {
+			if err = mongo.BulkInsertDimensionOptions(ctx, options); err != nil {
 				log.Event(ctx, "failed to add last set of dimension options in bulk request to mongo db", log.ERROR, log.Error(err))
 				return
 			}
@@ -321,7 +324,7 @@ func (api *API) createFTBDatasetBlob(ctx context.Context, mongo Mongo, ftbBlob *
 				ID:   "1",
 			},
 		},
-		ReleaseDate: "22/03/2012",
+		ReleaseDate: "2012-03-22T00:00:00.000Z",
 		State:       "published",
 		Tables:      nil,
 		Temporal: &[]models.TemporalFrequency{
@@ -335,7 +338,7 @@ func (api *API) createFTBDatasetBlob(ctx context.Context, mongo Mongo, ftbBlob *
 	}
 
 	// Store version doc
-	if err = mongo.UpsertVersion(versionID, versionDoc); err != nil {
+	if err = mongo.UpsertVersion(ctx, versionID, versionDoc); err != nil {
 		log.Event(ctx, "failed to upload version document", log.ERROR, log.Error(err))
 		return
 	}
@@ -395,7 +398,7 @@ func (api *API) createFTBDatasetBlob(ctx context.Context, mongo Mongo, ftbBlob *
 	}
 
 	// Store dataset doc
-	if err = mongo.UpsertDataset(datasetID, datasetDoc); err != nil {
+	if err = mongo.UpsertDataset(ctx, datasetID, datasetDoc); err != nil {
 		log.Event(ctx, "failed to upload dataset document", log.ERROR, log.Error(err))
 		return
 	}
@@ -432,7 +435,7 @@ func (api *API) createFTBDatasetBlob(ctx context.Context, mongo Mongo, ftbBlob *
 	}
 
 	// Store dataset doc
-	if err = mongo.UpsertEdition(datasetID, editionDoc); err != nil {
+	if err = mongo.UpsertEdition(ctx, datasetID, editionDoc); err != nil {
 		log.Event(ctx, "failed to upload edition document", log.ERROR, log.Error(err))
 		return
 	}
@@ -542,7 +545,7 @@ func (api *API) createFTBDatasetTable(ctx context.Context, mongo Mongo, ftbBlob
 
 			// Do a bulk upload of 500 documents at a time to speed the process of loading data into mongo db
 			if len(options) == 500 {
-				if err := mongo.BulkInsertDimensionOptions(options); err != nil {
+				if err := mongo.BulkInsertDimensionOptions(ctx, options); err != nil {
 					log.Event(ctx, "failed to add dimension options in bulk request to mongo db", log.ERROR, log.Error(err))
 					return datasetFTBTable, editionFTBTable, versionFTBTable, err
 				}
@@ -553,7 +556,7 @@ func (api *API) createFTBDatasetTable(ctx context.Context, mongo Mongo, ftbBlob
 
 		// Add leftover docs to mongo
 		if len(options) != 0 {
-			if err := mongo.BulkInsertDimensionOptions(options); err != nil {
+			if err := mongo.BulkInsertDimensionOptions(ctx, options); err != nil {
 				log.Event(ctx, "failed to add last set of dimension options in bulk request to mongo db", log.ERROR, log.Error(err))
 				return datasetFTBTable, editionFTBTable, versionFTBTable, err
 			}
@@ -599,7 +602,7 @@ func (api *API) createFTBDatasetTable(ctx context.Context, mongo Mongo, ftbBlob
 				ID:   "1",
 			},
 		},
-		ReleaseDate: "22/03/2012",
+		ReleaseDate: "2012-03-22T00:00:00.000Z",
 		State:       "published",
 		Tables:      nil,
 		Temporal: &[]models.TemporalFrequency{
@@ -613,7 +616,7 @@ func (api *API) createFTBDatasetTable(ctx context.Context, mongo Mongo, ftbBlob
 	}
 
 	// Store version doc
-	if err := mongo.UpsertVersion(versionID, versionDoc); err != nil {
+	if err := mongo.UpsertVersion(ctx, versionID, versionDoc); err != nil {
 		log.Event(ctx, "failed to upload version document", log.ERROR, log.Error(err))
 		return datasetFTBTable, editionFTBTable, versionFTBTable, err
 	}
@@ -676,7 +679,7 @@ func (api *API) createFTBDatasetTable(ctx context.Context, mongo Mongo, ftbBlob
 	}
 
 	// Store dataset doc
-	if err := mongo.UpsertDataset(datasetID, datasetDoc); err != nil {
+	if err := mongo.UpsertDataset(ctx, datasetID, datasetDoc); err != nil {
 		log.Event(ctx, "failed to upload dataset document", log.ERROR, log.Error(err))
 		return datasetFTBTable, editionFTBTable, versionFTBTable, err
 	}
@@ -718,7 +721,7 @@ func (api *API) createFTBDatasetTable(ctx context.Context, mongo Mongo, ftbBlob
 	}
 
 	// Store dataset doc
-	if err := mongo.UpsertEdition(datasetID, editionDoc); err != nil {
+	if err := mongo.UpsertEdition(ctx, datasetID, editionDoc); err != nil {
 		log.Event(ctx, "failed to upload edition document", log.ERROR, log.Error(err))
 		return datasetFTBTable, editionFTBTable, versionFTBTable, err
 	}
@@ -749,7 +752,7 @@ func (api *API) updateFTBDatasetBlob(ctx context.Context, mongo Mongo, datasetID
 	datasetUpdates["next.tables"] = datasetFTBTables
 	datasetUpdates["current.tables"] = datasetFTBTables
 
-	if err := mongo.UpdateDataset(datasetID, datasetUpdates); err != nil {
+	if err := mongo.UpdateDataset(ctx, datasetID, datasetUpdates); err != nil {
 		log.Event(ctx, "failed to update dataset doc with tables", log.ERROR, log.Error(err))
 		return err
 	}
@@ -759,7 +762,7 @@ func (api *API) updateFTBDatasetBlob(ctx context.Context, mongo Mongo, datasetID
 	editionUpdates["next.tables"] = editionFTBTables
 	editionUpdates["current.tables"] = editionFTBTables
 
-	if err := mongo.UpdateEdition(datasetID, editionUpdates); err != nil {
+	if err := mongo.UpdateEdition(ctx, datasetID, editionUpdates); err != nil {
 		log.Event(ctx, "failed to update edition doc with tables", log.ERROR, log.Error(err))
 		return err
 	}
@@ -768,7 +771,7 @@ func (api *API) updateFTBDatasetBlob(ctx context.Context, mongo Mongo, datasetID
 	versionUpdates := make(bson.M)
 	versionUpdates["tables"] = versionFTBTables
 
-	if err := mongo.UpdateVersion(instanceID, versionUpdates); err != nil {
+	if err := mongo.UpdateVersion(ctx, instanceID, versionUpdates); err != nil {
 		log.Event(ctx, "failed to update version doc with tables", log.ERROR, log.Error(err))
 		return err
 	}
@@ -872,33 +875,44 @@ func (api *API) callFTBAPI(ctx context.Context, method, authToken, host, path st
 
 // Mongo represents a simplistic MongoDB configuration.
 type Mongo struct {
+	Client      *mongo.Client
 	CodeListURL string
 	Database    string
-	Session     *mgo.Session
 	URI         string
 }
 
-// Init creates a new mgo.Session with a strong consistency and a write mode of "majortiy".
-func (m *Mongo) Init() (session *mgo.Session, err error) {
-	if session != nil {
-		return nil, errors.New("session already exists")
+// Init connects to mongo with a write concern of "majority", accepting a bind address either with or without the
+// mongodb:// scheme.
+func (m *Mongo) Init(ctx context.Context) (*mongo.Client, error) {
+	if m.Client != nil {
+		return nil, errors.New("client already exists")
 	}
 
-	if session, err = mgo.Dial(m.URI); err != nil {
+	uri := m.URI
+	if !strings.HasPrefix(uri, "mongodb://") && !strings.HasPrefix(uri, "mongodb+srv://") {
+		uri = "mongodb://" + uri
+	}
+
+	opts := options.Client().ApplyURI(uri).SetWriteConcern(writeconcern.New(writeconcern.WMajority()))
+	client, err := mongo.Connect(ctx, opts)
+	if err != nil {
 		return nil, err
 	}
 
-	session.EnsureSafe(&mgo.Safe{WMode: "majority"})
-	session.SetMode(mgo.Strong, true)
+	if err = client.Ping(ctx, nil); err != nil {
+		client.Disconnect(ctx)
+		return nil, err
+	}
 
-	return session, nil
+	return client, nil
 }
 
-// UpsertVersion adds or overrides an existing version document
-func (m *Mongo) UpsertVersion(id string, version *models.Version) (err error) {
-	s := m.Session.Copy()
-	defer s.Close()
+func (m *Mongo) collection(name string) *mongo.Collection {
+	return m.Client.Database(m.Database).Collection(name)
+}
 
+// UpsertVersion adds or overrides an existing version document
+func (m *Mongo) UpsertVersion(ctx context.Context, id string, version *models.Version) (err error) {
 	update := bson.M{
 		"$set": version,
 		"$setOnInsert": bson.M{
@@ -906,24 +920,18 @@ func (m *Mongo) UpsertVersion(id string, version *models.Version) (err error) {
 		},
 	}
 
-	_, err = s.DB(m.Database).C(versionCollection).UpsertId(id, update)
+	_, err = m.collection(versionCollection).UpdateOne(ctx, bson.M{"_id": id}, update, options.Update().SetUpsert(true))
 	return err
 }
 
 // UpdateVersion updates an existing version document
-func (m *Mongo) UpdateVersion(id string, updates bson.M) (err error) {
-	s := m.Session.Copy()
-	defer s.Close()
-
-	err = s.DB(m.Database).C("instances").Update(bson.M{"id": id}, bson.M{"$set": updates})
+func (m *Mongo) UpdateVersion(ctx context.Context, id string, updates bson.M) (err error) {
+	_, err = m.collection(versionCollection).UpdateOne(ctx, bson.M{"id": id}, bson.M{"$set": updates})
 	return
 }
 
 // UpsertDataset adds or overides an existing dataset document
-func (m *Mongo) UpsertDataset(id string, datasetDoc *models.DatasetUpdate) (err error) {
-	s := m.Session.Copy()
-	defer s.Close()
-
+func (m *Mongo) UpsertDataset(ctx context.Context, id string, datasetDoc *models.DatasetUpdate) (err error) {
 	update := bson.M{
 		"$set": datasetDoc,
 		"$setOnInsert": bson.M{
@@ -931,31 +939,26 @@ func (m *Mongo) UpsertDataset(id string, datasetDoc *models.DatasetUpdate) (err
 		},
 	}
 
-	_, err = s.DB(m.Database).C(datasetCollection).UpsertId(id, update)
+	_, err = m.collection(datasetCollection).UpdateOne(ctx, bson.M{"_id": id}, update, options.Update().SetUpsert(true))
 	return
 }
 
 // UpdateDataset updates an existing dataset document
-func (m *Mongo) UpdateDataset(id string, updates bson.M) (err error) {
-	s := m.Session.Copy()
-	defer s.Close()
-
-	update := bson.M{"$set": updates}
-	if err = s.DB(m.Database).C(datasetCollection).UpdateId(id, update); err != nil {
-		if err == mgo.ErrNotFound {
-			return errors.New("dataset not found")
-		}
+func (m *Mongo) UpdateDataset(ctx context.Context, id string, updates bson.M) (err error) {
+	result, err := m.collection(datasetCollection).UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": updates})
+	if err != nil {
 		return err
 	}
 
+	if result.MatchedCount == 0 {
+		return errors.New("dataset not found")
+	}
+
 	return nil
 }
 
 // UpsertEdition adds or overides an existing edition document
-func (m *Mongo) UpsertEdition(datasetID string, editionDoc *models.EditionUpdate) (err error) {
-	s := m.Session.Copy()
-	defer s.Close()
-
+func (m *Mongo) UpsertEdition(ctx context.Context, datasetID string, editionDoc *models.EditionUpdate) (err error) {
 	selector := bson.M{
 		"next.edition":          edition,
 		"next.links.dataset.id": datasetID,
@@ -967,52 +970,41 @@ func (m *Mongo) UpsertEdition(datasetID string, editionDoc *models.EditionUpdate
 		"$set": editionDoc,
 	}
 
-	_, err = s.DB(m.Database).C(editionCollection).Upsert(selector, update)
+	_, err = m.collection(editionCollection).UpdateOne(ctx, selector, update, options.Update().SetUpsert(true))
 	return
 }
 
 // UpdateEdition updates an existing edition document
-func (m *Mongo) UpdateEdition(datasetID string, updates bson.M) (err error) {
-	s := m.Session.Copy()
-	defer s.Close()
-
+func (m *Mongo) UpdateEdition(ctx context.Context, datasetID string, updates bson.M) (err error) {
 	selector := bson.M{
 		"next.edition":          edition,
 		"next.links.dataset.id": datasetID,
 	}
 
-	update := bson.M{"$set": updates}
-	if err = s.DB(m.Database).C(editionCollection).Update(selector, update); err != nil {
-		if err == mgo.ErrNotFound {
-			return errors.New("edition not found")
-		}
+	result, err := m.collection(editionCollection).UpdateOne(ctx, selector, bson.M{"$set": updates})
+	if err != nil {
 		return err
 	}
 
+	if result.MatchedCount == 0 {
+		return errors.New("edition not found")
+	}
+
 	return nil
 }
 
 // AddDimensionToInstance to the dimension collection
-func (m *Mongo) AddDimensionToInstance(option *models.DimensionOption) error {
-	s := m.Session.Copy()
-	defer s.Close()
-
+func (m *Mongo) AddDimensionToInstance(ctx context.Context, option *models.DimensionOption) error {
 	option.LastUpdated = time.Now().UTC()
-	_, err := s.DB(m.Database).C(dimOptionCollection).Upsert(bson.M{"instance_id": option.InstanceID, "name": option.Name,
-		"option": option.Option}, &option)
+	selector := bson.M{"instance_id": option.InstanceID, "name": option.Name, "option": option.Option}
+	_, err := m.collection(dimOptionCollection).ReplaceOne(ctx, selector, option, options.Replace().SetUpsert(true))
 
 	return err
 }
 
 // BulkInsertDimensionOptions to the dimension.options collection
-func (m *Mongo) BulkInsertDimensionOptions(options []interface{}) error {
-	s := m.Session.Copy()
-	defer s.Close()
-
-	s.Clone()
-	bulk := s.Clone().DB(m.Database).C(dimOptionCollection).Bulk()
-	bulk.Insert(options...)
-	_, err := bulk.Run()
+func (m *Mongo) BulkInsertDimensionOptions(ctx context.Context, options []interface{}) error {
+	_, err := m.collection(dimOptionCollection).InsertMany(ctx, options)
 
 	return err
 }
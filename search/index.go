@@ -0,0 +1,176 @@
+// Package search provides an in-process inverted index of the published datasets, used to search them when mongodb
+// and its text indexes are not available.
+package search
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store"
+	"github.com/ONSdigital/log.go/log"
+)
+
+// pageSize is the number of datasets read from the store at a time while building the index
+const pageSize = 100
+
+// check that Index satisfies the store.Searcher interface
+var _ store.Searcher = (*Index)(nil)
+
+// Index is an inverted index of the published datasets held by a store, along with the dimensions of their
+// published versions. The index is a snapshot of the store, so it must be rebuilt to pick up changes.
+type Index struct {
+	storer store.Storer
+
+	mutex     sync.RWMutex
+	documents map[string]*models.SearchDocument
+	// postings holds the number of times each term occurs in each field of each dataset, by term then dataset id
+	postings map[string]map[string]map[string]int
+}
+
+// NewIndex creates an empty index of the datasets held by storer
+func NewIndex(storer store.Storer) *Index {
+	return &Index{
+		storer:    storer,
+		documents: make(map[string]*models.SearchDocument),
+		postings:  make(map[string]map[string]map[string]int),
+	}
+}
+
+// Build reads every published dataset from the store and replaces the contents of the index with them
+func (i *Index) Build(ctx context.Context) error {
+	documents := make(map[string]*models.SearchDocument)
+	for offset := 0; ; offset += pageSize {
+		page, err := i.storer.GetDatasets(ctx, offset, pageSize, false)
+		if err != nil {
+			return err
+		}
+
+		for _, dataset := range page.Items {
+			doc := &models.SearchDocument{Dataset: dataset.Revision(false)}
+
+			if err = i.addDimensions(ctx, doc); err != nil {
+				return err
+			}
+			documents[doc.Dataset.ID] = doc
+		}
+
+		if len(page.Items) == 0 || offset+len(page.Items) >= page.TotalCount {
+			break
+		}
+	}
+
+	postings := make(map[string]map[string]map[string]int)
+	for id, doc := range documents {
+		for field, values := range doc.Fields() {
+			for _, value := range values {
+				for _, term := range models.SearchTerms(value) {
+					if postings[term] == nil {
+						postings[term] = make(map[string]map[string]int)
+					}
+					if postings[term][id] == nil {
+						postings[term][id] = make(map[string]int)
+					}
+					postings[term][id][field]++
+				}
+			}
+		}
+	}
+
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	i.documents = documents
+	i.postings = postings
+
+	return nil
+}
+
+// Refresh rebuilds the index every interval until the context is done
+func (i *Index) Refresh(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := i.Build(ctx); err != nil {
+				log.Event(ctx, "failed to rebuild search index", log.ERROR, log.Error(err))
+			}
+		}
+	}
+}
+
+// addDimensions adds the dimensions of every published version of the dataset to the document
+func (i *Index) addDimensions(ctx context.Context, doc *models.SearchDocument) error {
+	editions, err := i.storer.GetEditions(ctx, doc.Dataset.ID, models.PublishedState, nil)
+	if err != nil {
+		if err == errs.ErrEditionNotFound {
+			return nil
+		}
+		return err
+	}
+
+	for _, edition := range editions.Items {
+		if edition.Current == nil {
+			continue
+		}
+
+		versions, err := i.storer.GetVersions(ctx, doc.Dataset.ID, edition.Current.Edition, models.PublishedState, nil)
+		if err != nil {
+			if err == errs.ErrVersionNotFound {
+				continue
+			}
+			return err
+		}
+
+		for _, version := range versions.Items {
+			doc.AddDimensions(version.Dimensions)
+		}
+	}
+
+	return nil
+}
+
+// Search returns the datasets matching any of the terms of the query, scored by the frequency of each term in
+// each field, weighted by field and by the inverse of the number of datasets containing the term
+func (i *Index) Search(ctx context.Context, query *models.SearchQuery) (*models.SearchResults, error) {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	terms := models.SearchTerms(query.Q)
+
+	scores := make(map[string]float64)
+	for _, term := range terms {
+		datasets := i.postings[term]
+		if len(datasets) == 0 {
+			continue
+		}
+
+		idf := 1 + math.Log(float64(len(i.documents))/float64(len(datasets)))
+		for id, fields := range datasets {
+			for field, frequency := range fields {
+				scores[id] += float64(models.SearchFieldWeights[field]*frequency) * idf
+			}
+		}
+	}
+
+	results := []models.SearchResult{}
+	for id, score := range scores {
+		doc := i.documents[id]
+		if query.FTBType != "" && doc.Dataset.FTBType != query.FTBType {
+			continue
+		}
+		if query.Dimension != "" && !doc.HasDimension(query.Dimension) {
+			continue
+		}
+
+		results = append(results, models.NewSearchResult(doc, terms, math.Round(score*100)/100))
+	}
+
+	return models.NewSearchResults(results, query.Offset, query.Limit), nil
+}
@@ -0,0 +1,96 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/memory"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIndex(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given an index of the People fixtures, a published table and an unpublished dataset", t, func() {
+		memoryStore := memory.New()
+		So(memoryStore.Load("../fixtures"), ShouldBeNil)
+
+		table := &models.Dataset{
+			ID:          "people-by-sex",
+			Description: "Usual residents of England and Wales",
+			FTBType:     models.FTBTableType,
+			State:       models.PublishedState,
+			Title:       "People by sex",
+		}
+		So(memoryStore.UpsertDataset(context.Background(), table.ID, &models.DatasetUpdate{ID: table.ID, Current: table, Next: table}), ShouldBeNil)
+
+		draft := &models.Dataset{ID: "households", State: models.CreatedState, Title: "Households by sex"}
+		So(memoryStore.UpsertDataset(context.Background(), draft.ID, &models.DatasetUpdate{ID: draft.ID, Next: draft}), ShouldBeNil)
+
+		index := NewIndex(memoryStore)
+		So(index.Build(ctx), ShouldBeNil)
+
+		Convey("When a term in the titles and dimension labels of datasets is searched for", func() {
+			results, err := index.Search(ctx, &models.SearchQuery{Q: "sex", Limit: 10})
+			So(err, ShouldBeNil)
+
+			Convey("Then the published datasets are returned, ranked by the fields the term is in", func() {
+				So(results.TotalCount, ShouldEqual, 2)
+				So(results.Items[0].ID, ShouldEqual, "people-by-sex")
+				So(results.Items[0].Highlights["title"], ShouldResemble, []string{"People by <em>sex</em>"})
+				So(results.Items[1].ID, ShouldEqual, "People")
+				So(results.Items[1].Highlights["dimensions"], ShouldResemble, []string{"<em>Sex</em>"})
+				So(results.Items[1].Links.Dataset.HRef, ShouldEqual, "http://localhost:10400/datasets/People")
+				So(results.Items[1].Links.LatestVersion.ID, ShouldEqual, "1")
+				So(results.Items[0].Score, ShouldBeGreaterThan, results.Items[1].Score)
+			})
+		})
+
+		Convey("When the plural of a keyword is searched for", func() {
+			results, err := index.Search(ctx, &models.SearchQuery{Q: "Populations", Limit: 10})
+			So(err, ShouldBeNil)
+
+			Convey("Then the dataset with the keyword is returned", func() {
+				So(results.TotalCount, ShouldEqual, 1)
+				So(results.Items[0].Highlights["keywords"], ShouldResemble, []string{"<em>population</em>"})
+			})
+		})
+
+		Convey("When a search is restricted by type and dimension", func() {
+			byType, err := index.Search(ctx, &models.SearchQuery{Q: "people sex", FTBType: models.FTBBlobType, Limit: 10})
+			So(err, ShouldBeNil)
+
+			byDimension, err := index.Search(ctx, &models.SearchQuery{Q: "people sex", Dimension: "age", Limit: 10})
+			So(err, ShouldBeNil)
+
+			Convey("Then only the datasets of that type and with that dimension are returned", func() {
+				So(byType.TotalCount, ShouldEqual, 1)
+				So(byType.Items[0].ID, ShouldEqual, "People")
+				So(byDimension.TotalCount, ShouldEqual, 1)
+				So(byDimension.Items[0].ID, ShouldEqual, "People")
+			})
+		})
+
+		Convey("When a page of the results is requested", func() {
+			results, err := index.Search(ctx, &models.SearchQuery{Q: "sex", Offset: 1, Limit: 5})
+			So(err, ShouldBeNil)
+
+			Convey("Then the page is returned with the total number of results", func() {
+				So(results.Count, ShouldEqual, 1)
+				So(results.TotalCount, ShouldEqual, 2)
+				So(results.Items[0].ID, ShouldEqual, "People")
+			})
+		})
+
+		Convey("When only stop words or unknown terms are searched for", func() {
+			results, err := index.Search(ctx, &models.SearchQuery{Q: "the of nothing", Limit: 10})
+
+			Convey("Then no results are returned", func() {
+				So(err, ShouldBeNil)
+				So(results.TotalCount, ShouldEqual, 0)
+				So(results.Items, ShouldBeEmpty)
+			})
+		})
+	})
+}
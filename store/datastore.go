@@ -4,31 +4,47 @@ import (
 	"context"
 
 	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
-	"github.com/globalsign/mgo/bson"
 )
 
-// DataStore provides a datastore.Storer interface used to store, retrieve, remove or update datasets
+// DataStore provides a datastore.Storer interface used to store, retrieve, remove or update datasets, and a
+// datastore.Searcher interface used to search them
 type DataStore struct {
-	Backend Storer
+	Backend  Storer
+	Searcher Searcher
 }
 
 //go:generate moq -out datastoretest/datastore.go -pkg storetest . Storer
 
 // Storer represents basic data access via Get, Remove and Upsert methods.
 type Storer interface {
-	CheckDatasetExists(ID, state string) error
-	CheckEditionExists(ID, editionID, state string) error
-	GetDataset(ID string) (*models.DatasetUpdate, error)
-	GetDatasets(ctx context.Context) ([]models.DatasetUpdate, error)
-	GetDimensionsFromInstance(ID string) (*models.DimensionNodeResults, error)
-	GetDimensions(datasetID, versionID string) ([]bson.M, error)
-	GetDimensionOptions(version *models.Version, dimension string) (*models.DimensionOptionResults, error)
-	GetEdition(ID, editionID, state string) (*models.EditionUpdate, error)
-	GetEditions(ctx context.Context, ID, state string) (*models.EditionUpdateResults, error)
+	AddDimensionOptions(ctx context.Context, options []*models.DimensionOption) error
+	AddTableLinks(ctx context.Context, datasetID, editionID, instanceID string, links *models.TableLinks) error
+	AddVersion(ctx context.Context, version *models.Version) error
+	CheckDatasetExists(ctx context.Context, ID, state string) error
+	CheckEditionExists(ctx context.Context, ID, editionID, state string) error
+	GetDataset(ctx context.Context, ID string) (*models.DatasetUpdate, error)
+	GetDatasets(ctx context.Context, offset, limit int, authorised bool) (*models.DatasetUpdateResults, error)
+	GetDimensionsFromInstance(ctx context.Context, ID string) (*models.DimensionNodeResults, error)
+	GetDimensions(ctx context.Context, datasetID, versionID string) ([]models.DimensionOption, error)
+	GetDimensionOptions(ctx context.Context, version *models.Version, dimension string, offset, limit int) (*models.DimensionOptionResults, error)
+	GetDimensionOptionsFromIDs(ctx context.Context, version *models.Version, dimension string, ids []string) (*models.DimensionOptionResults, error)
+	GetEdition(ctx context.Context, ID, editionID, state string) (*models.EditionUpdate, error)
+	GetEditions(ctx context.Context, ID, state string, query *models.EditionsQuery) (*models.EditionUpdateResults, error)
 	GetInstances(ctx context.Context, states []string, datasets []string) (*models.InstanceResults, error)
-	GetInstance(ID string) (*models.Instance, error)
-	GetNextVersion(datasetID, editionID string) (int, error)
-	GetUniqueDimensionAndOptions(ID, dimension string) (*models.DimensionValues, error)
-	GetVersion(datasetID, editionID, version, state string) (*models.Version, error)
-	GetVersions(ctx context.Context, datasetID, editionID, state string) (*models.VersionResults, error)
+	GetInstance(ctx context.Context, ID string) (*models.Instance, error)
+	GetNextVersion(ctx context.Context, datasetID, editionID string) (int, error)
+	GetUniqueDimensionAndOptions(ctx context.Context, ID, dimension string) (*models.DimensionValues, error)
+	GetVersion(ctx context.Context, datasetID, editionID, version, state string) (*models.Version, error)
+	GetVersions(ctx context.Context, datasetID, editionID, state string, query *models.VersionsQuery) (*models.VersionResults, error)
+	UpdateDataset(ctx context.Context, ID string, dataset *models.Dataset, currentState string) error
+	UpdateVersion(ctx context.Context, ID string, version *models.Version) error
+	UpsertDataset(ctx context.Context, ID string, datasetDoc *models.DatasetUpdate) error
+	UpsertEdition(ctx context.Context, datasetID, edition string, editionDoc *models.EditionUpdate) error
+}
+
+//go:generate moq -out datastoretest/searcher.go -pkg storetest . Searcher
+
+// Searcher represents a full-text search of the published datasets, returning ranked results
+type Searcher interface {
+	Search(ctx context.Context, query *models.SearchQuery) (*models.SearchResults, error)
 }
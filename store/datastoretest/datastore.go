@@ -7,16 +7,19 @@ import (
 	"context"
 	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
 	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store"
-	"github.com/globalsign/mgo/bson"
 	"sync"
 )
 
 var (
+	lockStorerMockAddDimensionOptions          sync.RWMutex
+	lockStorerMockAddTableLinks                sync.RWMutex
+	lockStorerMockAddVersion                   sync.RWMutex
 	lockStorerMockCheckDatasetExists           sync.RWMutex
 	lockStorerMockCheckEditionExists           sync.RWMutex
 	lockStorerMockGetDataset                   sync.RWMutex
 	lockStorerMockGetDatasets                  sync.RWMutex
 	lockStorerMockGetDimensionOptions          sync.RWMutex
+	lockStorerMockGetDimensionOptionsFromIDs   sync.RWMutex
 	lockStorerMockGetDimensions                sync.RWMutex
 	lockStorerMockGetDimensionsFromInstance    sync.RWMutex
 	lockStorerMockGetEdition                   sync.RWMutex
@@ -27,6 +30,10 @@ var (
 	lockStorerMockGetUniqueDimensionAndOptions sync.RWMutex
 	lockStorerMockGetVersion                   sync.RWMutex
 	lockStorerMockGetVersions                  sync.RWMutex
+	lockStorerMockUpdateDataset                sync.RWMutex
+	lockStorerMockUpdateVersion                sync.RWMutex
+	lockStorerMockUpsertDataset                sync.RWMutex
+	lockStorerMockUpsertEdition                sync.RWMutex
 )
 
 // Ensure, that StorerMock does implement store.Storer.
@@ -39,51 +46,75 @@ var _ store.Storer = &StorerMock{}
 //
 //         // make and configure a mocked store.Storer
 //         mockedStorer := &StorerMock{
-//             CheckDatasetExistsFunc: func(ID string, state string) error {
+//             AddDimensionOptionsFunc: func(ctx context.Context, options []*models.DimensionOption) error {
+// 	               panic("mock out the AddDimensionOptions method")
+//             },
+//             AddTableLinksFunc: func(ctx context.Context, datasetID string, editionID string, instanceID string, links *models.TableLinks) error {
+// 	               panic("mock out the AddTableLinks method")
+//             },
+//             AddVersionFunc: func(ctx context.Context, version *models.Version) error {
+// 	               panic("mock out the AddVersion method")
+//             },
+//             CheckDatasetExistsFunc: func(ctx context.Context, ID string, state string) error {
 // 	               panic("mock out the CheckDatasetExists method")
 //             },
-//             CheckEditionExistsFunc: func(ID string, editionID string, state string) error {
+//             CheckEditionExistsFunc: func(ctx context.Context, ID string, editionID string, state string) error {
 // 	               panic("mock out the CheckEditionExists method")
 //             },
-//             GetDatasetFunc: func(ID string) (*models.DatasetUpdate, error) {
+//             GetDatasetFunc: func(ctx context.Context, ID string) (*models.DatasetUpdate, error) {
 // 	               panic("mock out the GetDataset method")
 //             },
-//             GetDatasetsFunc: func(ctx context.Context) ([]models.DatasetUpdate, error) {
+//             GetDatasetsFunc: func(ctx context.Context, offset int, limit int, authorised bool) (*models.DatasetUpdateResults, error) {
 // 	               panic("mock out the GetDatasets method")
 //             },
-//             GetDimensionOptionsFunc: func(version *models.Version, dimension string) (*models.DimensionOptionResults, error) {
+//             GetDimensionOptionsFunc: func(ctx context.Context, version *models.Version, dimension string, offset int, limit int) (*models.DimensionOptionResults, error) {
 // 	               panic("mock out the GetDimensionOptions method")
 //             },
-//             GetDimensionsFunc: func(datasetID string, versionID string) ([]bson.M, error) {
+//             GetDimensionOptionsFromIDsFunc: func(ctx context.Context, version *models.Version, dimension string, ids []string) (*models.DimensionOptionResults, error) {
+// 	               panic("mock out the GetDimensionOptionsFromIDs method")
+//             },
+//             GetDimensionsFunc: func(ctx context.Context, datasetID string, versionID string) ([]models.DimensionOption, error) {
 // 	               panic("mock out the GetDimensions method")
 //             },
-//             GetDimensionsFromInstanceFunc: func(ID string) (*models.DimensionNodeResults, error) {
+//             GetDimensionsFromInstanceFunc: func(ctx context.Context, ID string) (*models.DimensionNodeResults, error) {
 // 	               panic("mock out the GetDimensionsFromInstance method")
 //             },
-//             GetEditionFunc: func(ID string, editionID string, state string) (*models.EditionUpdate, error) {
+//             GetEditionFunc: func(ctx context.Context, ID string, editionID string, state string) (*models.EditionUpdate, error) {
 // 	               panic("mock out the GetEdition method")
 //             },
-//             GetEditionsFunc: func(ctx context.Context, ID string, state string) (*models.EditionUpdateResults, error) {
+//             GetEditionsFunc: func(ctx context.Context, ID string, state string, query *models.EditionsQuery) (*models.EditionUpdateResults, error) {
 // 	               panic("mock out the GetEditions method")
 //             },
-//             GetInstanceFunc: func(ID string) (*models.Instance, error) {
+//             GetInstanceFunc: func(ctx context.Context, ID string) (*models.Instance, error) {
 // 	               panic("mock out the GetInstance method")
 //             },
 //             GetInstancesFunc: func(ctx context.Context, states []string, datasets []string) (*models.InstanceResults, error) {
 // 	               panic("mock out the GetInstances method")
 //             },
-//             GetNextVersionFunc: func(datasetID string, editionID string) (int, error) {
+//             GetNextVersionFunc: func(ctx context.Context, datasetID string, editionID string) (int, error) {
 // 	               panic("mock out the GetNextVersion method")
 //             },
-//             GetUniqueDimensionAndOptionsFunc: func(ID string, dimension string) (*models.DimensionValues, error) {
+//             GetUniqueDimensionAndOptionsFunc: func(ctx context.Context, ID string, dimension string) (*models.DimensionValues, error) {
 // 	               panic("mock out the GetUniqueDimensionAndOptions method")
 //             },
-//             GetVersionFunc: func(datasetID string, editionID string, version string, state string) (*models.Version, error) {
+//             GetVersionFunc: func(ctx context.Context, datasetID string, editionID string, version string, state string) (*models.Version, error) {
 // 	               panic("mock out the GetVersion method")
 //             },
-//             GetVersionsFunc: func(ctx context.Context, datasetID string, editionID string, state string) (*models.VersionResults, error) {
+//             GetVersionsFunc: func(ctx context.Context, datasetID string, editionID string, state string, query *models.VersionsQuery) (*models.VersionResults, error) {
 // 	               panic("mock out the GetVersions method")
 //             },
+//             UpdateDatasetFunc: func(ctx context.Context, ID string, dataset *models.Dataset, currentState string) error {
+// 	               panic("mock out the UpdateDataset method")
+//             },
+//             UpdateVersionFunc: func(ctx context.Context, ID string, version *models.Version) error {
+// 	               panic("mock out the UpdateVersion method")
+//             },
+//             UpsertDatasetFunc: func(ctx context.Context, ID string, datasetDoc *models.DatasetUpdate) error {
+// 	               panic("mock out the UpsertDataset method")
+//             },
+//             UpsertEditionFunc: func(ctx context.Context, datasetID string, edition string, editionDoc *models.EditionUpdate) error {
+// 	               panic("mock out the UpsertEdition method")
+//             },
 //         }
 //
 //         // use mockedStorer in code that requires store.Storer
@@ -91,55 +122,108 @@ var _ store.Storer = &StorerMock{}
 //
 //     }
 type StorerMock struct {
+	// AddDimensionOptionsFunc mocks the AddDimensionOptions method.
+	AddDimensionOptionsFunc func(ctx context.Context, options []*models.DimensionOption) error
+
+	// AddTableLinksFunc mocks the AddTableLinks method.
+	AddTableLinksFunc func(ctx context.Context, datasetID string, editionID string, instanceID string, links *models.TableLinks) error
+
+	// AddVersionFunc mocks the AddVersion method.
+	AddVersionFunc func(ctx context.Context, version *models.Version) error
+
 	// CheckDatasetExistsFunc mocks the CheckDatasetExists method.
-	CheckDatasetExistsFunc func(ID string, state string) error
+	CheckDatasetExistsFunc func(ctx context.Context, ID string, state string) error
 
 	// CheckEditionExistsFunc mocks the CheckEditionExists method.
-	CheckEditionExistsFunc func(ID string, editionID string, state string) error
+	CheckEditionExistsFunc func(ctx context.Context, ID string, editionID string, state string) error
 
 	// GetDatasetFunc mocks the GetDataset method.
-	GetDatasetFunc func(ID string) (*models.DatasetUpdate, error)
+	GetDatasetFunc func(ctx context.Context, ID string) (*models.DatasetUpdate, error)
 
 	// GetDatasetsFunc mocks the GetDatasets method.
-	GetDatasetsFunc func(ctx context.Context) ([]models.DatasetUpdate, error)
+	GetDatasetsFunc func(ctx context.Context, offset int, limit int, authorised bool) (*models.DatasetUpdateResults, error)
 
 	// GetDimensionOptionsFunc mocks the GetDimensionOptions method.
-	GetDimensionOptionsFunc func(version *models.Version, dimension string) (*models.DimensionOptionResults, error)
+	GetDimensionOptionsFunc func(ctx context.Context, version *models.Version, dimension string, offset int, limit int) (*models.DimensionOptionResults, error)
+
+	// GetDimensionOptionsFromIDsFunc mocks the GetDimensionOptionsFromIDs method.
+	GetDimensionOptionsFromIDsFunc func(ctx context.Context, version *models.Version, dimension string, ids []string) (*models.DimensionOptionResults, error)
 
 	// GetDimensionsFunc mocks the GetDimensions method.
-	GetDimensionsFunc func(datasetID string, versionID string) ([]bson.M, error)
+	GetDimensionsFunc func(ctx context.Context, datasetID string, versionID string) ([]models.DimensionOption, error)
 
 	// GetDimensionsFromInstanceFunc mocks the GetDimensionsFromInstance method.
-	GetDimensionsFromInstanceFunc func(ID string) (*models.DimensionNodeResults, error)
+	GetDimensionsFromInstanceFunc func(ctx context.Context, ID string) (*models.DimensionNodeResults, error)
 
 	// GetEditionFunc mocks the GetEdition method.
-	GetEditionFunc func(ID string, editionID string, state string) (*models.EditionUpdate, error)
+	GetEditionFunc func(ctx context.Context, ID string, editionID string, state string) (*models.EditionUpdate, error)
 
 	// GetEditionsFunc mocks the GetEditions method.
-	GetEditionsFunc func(ctx context.Context, ID string, state string) (*models.EditionUpdateResults, error)
+	GetEditionsFunc func(ctx context.Context, ID string, state string, query *models.EditionsQuery) (*models.EditionUpdateResults, error)
 
 	// GetInstanceFunc mocks the GetInstance method.
-	GetInstanceFunc func(ID string) (*models.Instance, error)
+	GetInstanceFunc func(ctx context.Context, ID string) (*models.Instance, error)
 
 	// GetInstancesFunc mocks the GetInstances method.
 	GetInstancesFunc func(ctx context.Context, states []string, datasets []string) (*models.InstanceResults, error)
 
 	// GetNextVersionFunc mocks the GetNextVersion method.
-	GetNextVersionFunc func(datasetID string, editionID string) (int, error)
+	GetNextVersionFunc func(ctx context.Context, datasetID string, editionID string) (int, error)
 
 	// GetUniqueDimensionAndOptionsFunc mocks the GetUniqueDimensionAndOptions method.
-	GetUniqueDimensionAndOptionsFunc func(ID string, dimension string) (*models.DimensionValues, error)
+	GetUniqueDimensionAndOptionsFunc func(ctx context.Context, ID string, dimension string) (*models.DimensionValues, error)
 
 	// GetVersionFunc mocks the GetVersion method.
-	GetVersionFunc func(datasetID string, editionID string, version string, state string) (*models.Version, error)
+	GetVersionFunc func(ctx context.Context, datasetID string, editionID string, version string, state string) (*models.Version, error)
 
 	// GetVersionsFunc mocks the GetVersions method.
-	GetVersionsFunc func(ctx context.Context, datasetID string, editionID string, state string) (*models.VersionResults, error)
+	GetVersionsFunc func(ctx context.Context, datasetID string, editionID string, state string, query *models.VersionsQuery) (*models.VersionResults, error)
+
+	// UpdateDatasetFunc mocks the UpdateDataset method.
+	UpdateDatasetFunc func(ctx context.Context, ID string, dataset *models.Dataset, currentState string) error
+
+	// UpdateVersionFunc mocks the UpdateVersion method.
+	UpdateVersionFunc func(ctx context.Context, ID string, version *models.Version) error
+
+	// UpsertDatasetFunc mocks the UpsertDataset method.
+	UpsertDatasetFunc func(ctx context.Context, ID string, datasetDoc *models.DatasetUpdate) error
+
+	// UpsertEditionFunc mocks the UpsertEdition method.
+	UpsertEditionFunc func(ctx context.Context, datasetID string, edition string, editionDoc *models.EditionUpdate) error
 
 	// calls tracks calls to the methods.
 	calls struct {
+		// AddDimensionOptions holds details about calls to the AddDimensionOptions method.
+		AddDimensionOptions []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Options is the options argument value.
+			Options []*models.DimensionOption
+		}
+		// AddTableLinks holds details about calls to the AddTableLinks method.
+		AddTableLinks []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// DatasetID is the datasetID argument value.
+			DatasetID string
+			// EditionID is the editionID argument value.
+			EditionID string
+			// InstanceID is the instanceID argument value.
+			InstanceID string
+			// Links is the links argument value.
+			Links *models.TableLinks
+		}
+		// AddVersion holds details about calls to the AddVersion method.
+		AddVersion []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Version is the version argument value.
+			Version *models.Version
+		}
 		// CheckDatasetExists holds details about calls to the CheckDatasetExists method.
 		CheckDatasetExists []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// ID is the ID argument value.
 			ID string
 			// State is the state argument value.
@@ -147,6 +231,8 @@ type StorerMock struct {
 		}
 		// CheckEditionExists holds details about calls to the CheckEditionExists method.
 		CheckEditionExists []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// ID is the ID argument value.
 			ID string
 			// EditionID is the editionID argument value.
@@ -156,6 +242,8 @@ type StorerMock struct {
 		}
 		// GetDataset holds details about calls to the GetDataset method.
 		GetDataset []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// ID is the ID argument value.
 			ID string
 		}
@@ -163,16 +251,41 @@ type StorerMock struct {
 		GetDatasets []struct {
 			// Ctx is the ctx argument value.
 			Ctx context.Context
+			// Offset is the offset argument value.
+			Offset int
+			// Limit is the limit argument value.
+			Limit int
+			// Authorised is the authorised argument value.
+			Authorised bool
 		}
 		// GetDimensionOptions holds details about calls to the GetDimensionOptions method.
 		GetDimensionOptions []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// Version is the version argument value.
 			Version *models.Version
 			// Dimension is the dimension argument value.
 			Dimension string
+			// Offset is the offset argument value.
+			Offset int
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// GetDimensionOptionsFromIDs holds details about calls to the GetDimensionOptionsFromIDs method.
+		GetDimensionOptionsFromIDs []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Version is the version argument value.
+			Version *models.Version
+			// Dimension is the dimension argument value.
+			Dimension string
+			// Ids is the ids argument value.
+			Ids []string
 		}
 		// GetDimensions holds details about calls to the GetDimensions method.
 		GetDimensions []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// DatasetID is the datasetID argument value.
 			DatasetID string
 			// VersionID is the versionID argument value.
@@ -180,11 +293,15 @@ type StorerMock struct {
 		}
 		// GetDimensionsFromInstance holds details about calls to the GetDimensionsFromInstance method.
 		GetDimensionsFromInstance []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// ID is the ID argument value.
 			ID string
 		}
 		// GetEdition holds details about calls to the GetEdition method.
 		GetEdition []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// ID is the ID argument value.
 			ID string
 			// EditionID is the editionID argument value.
@@ -200,9 +317,13 @@ type StorerMock struct {
 			ID string
 			// State is the state argument value.
 			State string
+			// Query is the query argument value.
+			Query *models.EditionsQuery
 		}
 		// GetInstance holds details about calls to the GetInstance method.
 		GetInstance []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// ID is the ID argument value.
 			ID string
 		}
@@ -217,6 +338,8 @@ type StorerMock struct {
 		}
 		// GetNextVersion holds details about calls to the GetNextVersion method.
 		GetNextVersion []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// DatasetID is the datasetID argument value.
 			DatasetID string
 			// EditionID is the editionID argument value.
@@ -224,6 +347,8 @@ type StorerMock struct {
 		}
 		// GetUniqueDimensionAndOptions holds details about calls to the GetUniqueDimensionAndOptions method.
 		GetUniqueDimensionAndOptions []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// ID is the ID argument value.
 			ID string
 			// Dimension is the dimension argument value.
@@ -231,6 +356,8 @@ type StorerMock struct {
 		}
 		// GetVersion holds details about calls to the GetVersion method.
 		GetVersion []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
 			// DatasetID is the datasetID argument value.
 			DatasetID string
 			// EditionID is the editionID argument value.
@@ -250,36 +377,199 @@ type StorerMock struct {
 			EditionID string
 			// State is the state argument value.
 			State string
+			// Query is the query argument value.
+			Query *models.VersionsQuery
+		}
+		// UpdateDataset holds details about calls to the UpdateDataset method.
+		UpdateDataset []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the ID argument value.
+			ID string
+			// Dataset is the dataset argument value.
+			Dataset *models.Dataset
+			// CurrentState is the currentState argument value.
+			CurrentState string
+		}
+		// UpdateVersion holds details about calls to the UpdateVersion method.
+		UpdateVersion []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the ID argument value.
+			ID string
+			// Version is the version argument value.
+			Version *models.Version
+		}
+		// UpsertDataset holds details about calls to the UpsertDataset method.
+		UpsertDataset []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the ID argument value.
+			ID string
+			// DatasetDoc is the datasetDoc argument value.
+			DatasetDoc *models.DatasetUpdate
+		}
+		// UpsertEdition holds details about calls to the UpsertEdition method.
+		UpsertEdition []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// DatasetID is the datasetID argument value.
+			DatasetID string
+			// Edition is the edition argument value.
+			Edition string
+			// EditionDoc is the editionDoc argument value.
+			EditionDoc *models.EditionUpdate
 		}
 	}
 }
 
+// AddDimensionOptions calls AddDimensionOptionsFunc.
+func (mock *StorerMock) AddDimensionOptions(ctx context.Context, options []*models.DimensionOption) error {
+	if mock.AddDimensionOptionsFunc == nil {
+		panic("StorerMock.AddDimensionOptionsFunc: method is nil but Storer.AddDimensionOptions was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Options []*models.DimensionOption
+	}{
+		Ctx:     ctx,
+		Options: options,
+	}
+	lockStorerMockAddDimensionOptions.Lock()
+	mock.calls.AddDimensionOptions = append(mock.calls.AddDimensionOptions, callInfo)
+	lockStorerMockAddDimensionOptions.Unlock()
+	return mock.AddDimensionOptionsFunc(ctx, options)
+}
+
+// AddDimensionOptionsCalls gets all the calls that were made to AddDimensionOptions.
+// Check the length with:
+//     len(mockedStorer.AddDimensionOptionsCalls())
+func (mock *StorerMock) AddDimensionOptionsCalls() []struct {
+	Ctx     context.Context
+	Options []*models.DimensionOption
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Options []*models.DimensionOption
+	}
+	lockStorerMockAddDimensionOptions.RLock()
+	calls = mock.calls.AddDimensionOptions
+	lockStorerMockAddDimensionOptions.RUnlock()
+	return calls
+}
+
+// AddTableLinks calls AddTableLinksFunc.
+func (mock *StorerMock) AddTableLinks(ctx context.Context, datasetID string, editionID string, instanceID string, links *models.TableLinks) error {
+	if mock.AddTableLinksFunc == nil {
+		panic("StorerMock.AddTableLinksFunc: method is nil but Storer.AddTableLinks was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		DatasetID  string
+		EditionID  string
+		InstanceID string
+		Links      *models.TableLinks
+	}{
+		Ctx:        ctx,
+		DatasetID:  datasetID,
+		EditionID:  editionID,
+		InstanceID: instanceID,
+		Links:      links,
+	}
+	lockStorerMockAddTableLinks.Lock()
+	mock.calls.AddTableLinks = append(mock.calls.AddTableLinks, callInfo)
+	lockStorerMockAddTableLinks.Unlock()
+	return mock.AddTableLinksFunc(ctx, datasetID, editionID, instanceID, links)
+}
+
+// AddTableLinksCalls gets all the calls that were made to AddTableLinks.
+// Check the length with:
+//     len(mockedStorer.AddTableLinksCalls())
+func (mock *StorerMock) AddTableLinksCalls() []struct {
+	Ctx        context.Context
+	DatasetID  string
+	EditionID  string
+	InstanceID string
+	Links      *models.TableLinks
+} {
+	var calls []struct {
+		Ctx        context.Context
+		DatasetID  string
+		EditionID  string
+		InstanceID string
+		Links      *models.TableLinks
+	}
+	lockStorerMockAddTableLinks.RLock()
+	calls = mock.calls.AddTableLinks
+	lockStorerMockAddTableLinks.RUnlock()
+	return calls
+}
+
+// AddVersion calls AddVersionFunc.
+func (mock *StorerMock) AddVersion(ctx context.Context, version *models.Version) error {
+	if mock.AddVersionFunc == nil {
+		panic("StorerMock.AddVersionFunc: method is nil but Storer.AddVersion was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		Version *models.Version
+	}{
+		Ctx:     ctx,
+		Version: version,
+	}
+	lockStorerMockAddVersion.Lock()
+	mock.calls.AddVersion = append(mock.calls.AddVersion, callInfo)
+	lockStorerMockAddVersion.Unlock()
+	return mock.AddVersionFunc(ctx, version)
+}
+
+// AddVersionCalls gets all the calls that were made to AddVersion.
+// Check the length with:
+//     len(mockedStorer.AddVersionCalls())
+func (mock *StorerMock) AddVersionCalls() []struct {
+	Ctx     context.Context
+	Version *models.Version
+} {
+	var calls []struct {
+		Ctx     context.Context
+		Version *models.Version
+	}
+	lockStorerMockAddVersion.RLock()
+	calls = mock.calls.AddVersion
+	lockStorerMockAddVersion.RUnlock()
+	return calls
+}
+
 // CheckDatasetExists calls CheckDatasetExistsFunc.
-func (mock *StorerMock) CheckDatasetExists(ID string, state string) error {
+func (mock *StorerMock) CheckDatasetExists(ctx context.Context, ID string, state string) error {
 	if mock.CheckDatasetExistsFunc == nil {
 		panic("StorerMock.CheckDatasetExistsFunc: method is nil but Storer.CheckDatasetExists was just called")
 	}
 	callInfo := struct {
+		Ctx   context.Context
 		ID    string
 		State string
 	}{
+		Ctx:   ctx,
 		ID:    ID,
 		State: state,
 	}
 	lockStorerMockCheckDatasetExists.Lock()
 	mock.calls.CheckDatasetExists = append(mock.calls.CheckDatasetExists, callInfo)
 	lockStorerMockCheckDatasetExists.Unlock()
-	return mock.CheckDatasetExistsFunc(ID, state)
+	return mock.CheckDatasetExistsFunc(ctx, ID, state)
 }
 
 // CheckDatasetExistsCalls gets all the calls that were made to CheckDatasetExists.
 // Check the length with:
 //     len(mockedStorer.CheckDatasetExistsCalls())
 func (mock *StorerMock) CheckDatasetExistsCalls() []struct {
+	Ctx   context.Context
 	ID    string
 	State string
 } {
 	var calls []struct {
+		Ctx   context.Context
 		ID    string
 		State string
 	}
@@ -290,15 +580,17 @@ func (mock *StorerMock) CheckDatasetExistsCalls() []struct {
 }
 
 // CheckEditionExists calls CheckEditionExistsFunc.
-func (mock *StorerMock) CheckEditionExists(ID string, editionID string, state string) error {
+func (mock *StorerMock) CheckEditionExists(ctx context.Context, ID string, editionID string, state string) error {
 	if mock.CheckEditionExistsFunc == nil {
 		panic("StorerMock.CheckEditionExistsFunc: method is nil but Storer.CheckEditionExists was just called")
 	}
 	callInfo := struct {
+		Ctx       context.Context
 		ID        string
 		EditionID string
 		State     string
 	}{
+		Ctx:       ctx,
 		ID:        ID,
 		EditionID: editionID,
 		State:     state,
@@ -306,18 +598,20 @@ func (mock *StorerMock) CheckEditionExists(ID string, editionID string, state st
 	lockStorerMockCheckEditionExists.Lock()
 	mock.calls.CheckEditionExists = append(mock.calls.CheckEditionExists, callInfo)
 	lockStorerMockCheckEditionExists.Unlock()
-	return mock.CheckEditionExistsFunc(ID, editionID, state)
+	return mock.CheckEditionExistsFunc(ctx, ID, editionID, state)
 }
 
 // CheckEditionExistsCalls gets all the calls that were made to CheckEditionExists.
 // Check the length with:
 //     len(mockedStorer.CheckEditionExistsCalls())
 func (mock *StorerMock) CheckEditionExistsCalls() []struct {
+	Ctx       context.Context
 	ID        string
 	EditionID string
 	State     string
 } {
 	var calls []struct {
+		Ctx       context.Context
 		ID        string
 		EditionID string
 		State     string
@@ -329,29 +623,33 @@ func (mock *StorerMock) CheckEditionExistsCalls() []struct {
 }
 
 // GetDataset calls GetDatasetFunc.
-func (mock *StorerMock) GetDataset(ID string) (*models.DatasetUpdate, error) {
+func (mock *StorerMock) GetDataset(ctx context.Context, ID string) (*models.DatasetUpdate, error) {
 	if mock.GetDatasetFunc == nil {
 		panic("StorerMock.GetDatasetFunc: method is nil but Storer.GetDataset was just called")
 	}
 	callInfo := struct {
-		ID string
+		Ctx context.Context
+		ID  string
 	}{
-		ID: ID,
+		Ctx: ctx,
+		ID:  ID,
 	}
 	lockStorerMockGetDataset.Lock()
 	mock.calls.GetDataset = append(mock.calls.GetDataset, callInfo)
 	lockStorerMockGetDataset.Unlock()
-	return mock.GetDatasetFunc(ID)
+	return mock.GetDatasetFunc(ctx, ID)
 }
 
 // GetDatasetCalls gets all the calls that were made to GetDataset.
 // Check the length with:
 //     len(mockedStorer.GetDatasetCalls())
 func (mock *StorerMock) GetDatasetCalls() []struct {
-	ID string
+	Ctx context.Context
+	ID  string
 } {
 	var calls []struct {
-		ID string
+		Ctx context.Context
+		ID  string
 	}
 	lockStorerMockGetDataset.RLock()
 	calls = mock.calls.GetDataset
@@ -360,29 +658,41 @@ func (mock *StorerMock) GetDatasetCalls() []struct {
 }
 
 // GetDatasets calls GetDatasetsFunc.
-func (mock *StorerMock) GetDatasets(ctx context.Context) ([]models.DatasetUpdate, error) {
+func (mock *StorerMock) GetDatasets(ctx context.Context, offset int, limit int, authorised bool) (*models.DatasetUpdateResults, error) {
 	if mock.GetDatasetsFunc == nil {
 		panic("StorerMock.GetDatasetsFunc: method is nil but Storer.GetDatasets was just called")
 	}
 	callInfo := struct {
-		Ctx context.Context
+		Ctx        context.Context
+		Offset     int
+		Limit      int
+		Authorised bool
 	}{
-		Ctx: ctx,
+		Ctx:        ctx,
+		Offset:     offset,
+		Limit:      limit,
+		Authorised: authorised,
 	}
 	lockStorerMockGetDatasets.Lock()
 	mock.calls.GetDatasets = append(mock.calls.GetDatasets, callInfo)
 	lockStorerMockGetDatasets.Unlock()
-	return mock.GetDatasetsFunc(ctx)
+	return mock.GetDatasetsFunc(ctx, offset, limit, authorised)
 }
 
 // GetDatasetsCalls gets all the calls that were made to GetDatasets.
 // Check the length with:
 //     len(mockedStorer.GetDatasetsCalls())
 func (mock *StorerMock) GetDatasetsCalls() []struct {
-	Ctx context.Context
+	Ctx        context.Context
+	Offset     int
+	Limit      int
+	Authorised bool
 } {
 	var calls []struct {
-		Ctx context.Context
+		Ctx        context.Context
+		Offset     int
+		Limit      int
+		Authorised bool
 	}
 	lockStorerMockGetDatasets.RLock()
 	calls = mock.calls.GetDatasets
@@ -391,33 +701,45 @@ func (mock *StorerMock) GetDatasetsCalls() []struct {
 }
 
 // GetDimensionOptions calls GetDimensionOptionsFunc.
-func (mock *StorerMock) GetDimensionOptions(version *models.Version, dimension string) (*models.DimensionOptionResults, error) {
+func (mock *StorerMock) GetDimensionOptions(ctx context.Context, version *models.Version, dimension string, offset int, limit int) (*models.DimensionOptionResults, error) {
 	if mock.GetDimensionOptionsFunc == nil {
 		panic("StorerMock.GetDimensionOptionsFunc: method is nil but Storer.GetDimensionOptions was just called")
 	}
 	callInfo := struct {
+		Ctx       context.Context
 		Version   *models.Version
 		Dimension string
+		Offset    int
+		Limit     int
 	}{
+		Ctx:       ctx,
 		Version:   version,
 		Dimension: dimension,
+		Offset:    offset,
+		Limit:     limit,
 	}
 	lockStorerMockGetDimensionOptions.Lock()
 	mock.calls.GetDimensionOptions = append(mock.calls.GetDimensionOptions, callInfo)
 	lockStorerMockGetDimensionOptions.Unlock()
-	return mock.GetDimensionOptionsFunc(version, dimension)
+	return mock.GetDimensionOptionsFunc(ctx, version, dimension, offset, limit)
 }
 
 // GetDimensionOptionsCalls gets all the calls that were made to GetDimensionOptions.
 // Check the length with:
 //     len(mockedStorer.GetDimensionOptionsCalls())
 func (mock *StorerMock) GetDimensionOptionsCalls() []struct {
+	Ctx       context.Context
 	Version   *models.Version
 	Dimension string
+	Offset    int
+	Limit     int
 } {
 	var calls []struct {
+		Ctx       context.Context
 		Version   *models.Version
 		Dimension string
+		Offset    int
+		Limit     int
 	}
 	lockStorerMockGetDimensionOptions.RLock()
 	calls = mock.calls.GetDimensionOptions
@@ -425,32 +747,79 @@ func (mock *StorerMock) GetDimensionOptionsCalls() []struct {
 	return calls
 }
 
+// GetDimensionOptionsFromIDs calls GetDimensionOptionsFromIDsFunc.
+func (mock *StorerMock) GetDimensionOptionsFromIDs(ctx context.Context, version *models.Version, dimension string, ids []string) (*models.DimensionOptionResults, error) {
+	if mock.GetDimensionOptionsFromIDsFunc == nil {
+		panic("StorerMock.GetDimensionOptionsFromIDsFunc: method is nil but Storer.GetDimensionOptionsFromIDs was just called")
+	}
+	callInfo := struct {
+		Ctx       context.Context
+		Version   *models.Version
+		Dimension string
+		Ids       []string
+	}{
+		Ctx:       ctx,
+		Version:   version,
+		Dimension: dimension,
+		Ids:       ids,
+	}
+	lockStorerMockGetDimensionOptionsFromIDs.Lock()
+	mock.calls.GetDimensionOptionsFromIDs = append(mock.calls.GetDimensionOptionsFromIDs, callInfo)
+	lockStorerMockGetDimensionOptionsFromIDs.Unlock()
+	return mock.GetDimensionOptionsFromIDsFunc(ctx, version, dimension, ids)
+}
+
+// GetDimensionOptionsFromIDsCalls gets all the calls that were made to GetDimensionOptionsFromIDs.
+// Check the length with:
+//     len(mockedStorer.GetDimensionOptionsFromIDsCalls())
+func (mock *StorerMock) GetDimensionOptionsFromIDsCalls() []struct {
+	Ctx       context.Context
+	Version   *models.Version
+	Dimension string
+	Ids       []string
+} {
+	var calls []struct {
+		Ctx       context.Context
+		Version   *models.Version
+		Dimension string
+		Ids       []string
+	}
+	lockStorerMockGetDimensionOptionsFromIDs.RLock()
+	calls = mock.calls.GetDimensionOptionsFromIDs
+	lockStorerMockGetDimensionOptionsFromIDs.RUnlock()
+	return calls
+}
+
 // GetDimensions calls GetDimensionsFunc.
-func (mock *StorerMock) GetDimensions(datasetID string, versionID string) ([]bson.M, error) {
+func (mock *StorerMock) GetDimensions(ctx context.Context, datasetID string, versionID string) ([]models.DimensionOption, error) {
 	if mock.GetDimensionsFunc == nil {
 		panic("StorerMock.GetDimensionsFunc: method is nil but Storer.GetDimensions was just called")
 	}
 	callInfo := struct {
+		Ctx       context.Context
 		DatasetID string
 		VersionID string
 	}{
+		Ctx:       ctx,
 		DatasetID: datasetID,
 		VersionID: versionID,
 	}
 	lockStorerMockGetDimensions.Lock()
 	mock.calls.GetDimensions = append(mock.calls.GetDimensions, callInfo)
 	lockStorerMockGetDimensions.Unlock()
-	return mock.GetDimensionsFunc(datasetID, versionID)
+	return mock.GetDimensionsFunc(ctx, datasetID, versionID)
 }
 
 // GetDimensionsCalls gets all the calls that were made to GetDimensions.
 // Check the length with:
 //     len(mockedStorer.GetDimensionsCalls())
 func (mock *StorerMock) GetDimensionsCalls() []struct {
+	Ctx       context.Context
 	DatasetID string
 	VersionID string
 } {
 	var calls []struct {
+		Ctx       context.Context
 		DatasetID string
 		VersionID string
 	}
@@ -461,29 +830,33 @@ func (mock *StorerMock) GetDimensionsCalls() []struct {
 }
 
 // GetDimensionsFromInstance calls GetDimensionsFromInstanceFunc.
-func (mock *StorerMock) GetDimensionsFromInstance(ID string) (*models.DimensionNodeResults, error) {
+func (mock *StorerMock) GetDimensionsFromInstance(ctx context.Context, ID string) (*models.DimensionNodeResults, error) {
 	if mock.GetDimensionsFromInstanceFunc == nil {
 		panic("StorerMock.GetDimensionsFromInstanceFunc: method is nil but Storer.GetDimensionsFromInstance was just called")
 	}
 	callInfo := struct {
-		ID string
+		Ctx context.Context
+		ID  string
 	}{
-		ID: ID,
+		Ctx: ctx,
+		ID:  ID,
 	}
 	lockStorerMockGetDimensionsFromInstance.Lock()
 	mock.calls.GetDimensionsFromInstance = append(mock.calls.GetDimensionsFromInstance, callInfo)
 	lockStorerMockGetDimensionsFromInstance.Unlock()
-	return mock.GetDimensionsFromInstanceFunc(ID)
+	return mock.GetDimensionsFromInstanceFunc(ctx, ID)
 }
 
 // GetDimensionsFromInstanceCalls gets all the calls that were made to GetDimensionsFromInstance.
 // Check the length with:
 //     len(mockedStorer.GetDimensionsFromInstanceCalls())
 func (mock *StorerMock) GetDimensionsFromInstanceCalls() []struct {
-	ID string
+	Ctx context.Context
+	ID  string
 } {
 	var calls []struct {
-		ID string
+		Ctx context.Context
+		ID  string
 	}
 	lockStorerMockGetDimensionsFromInstance.RLock()
 	calls = mock.calls.GetDimensionsFromInstance
@@ -492,15 +865,17 @@ func (mock *StorerMock) GetDimensionsFromInstanceCalls() []struct {
 }
 
 // GetEdition calls GetEditionFunc.
-func (mock *StorerMock) GetEdition(ID string, editionID string, state string) (*models.EditionUpdate, error) {
+func (mock *StorerMock) GetEdition(ctx context.Context, ID string, editionID string, state string) (*models.EditionUpdate, error) {
 	if mock.GetEditionFunc == nil {
 		panic("StorerMock.GetEditionFunc: method is nil but Storer.GetEdition was just called")
 	}
 	callInfo := struct {
+		Ctx       context.Context
 		ID        string
 		EditionID string
 		State     string
 	}{
+		Ctx:       ctx,
 		ID:        ID,
 		EditionID: editionID,
 		State:     state,
@@ -508,18 +883,20 @@ func (mock *StorerMock) GetEdition(ID string, editionID string, state string) (*
 	lockStorerMockGetEdition.Lock()
 	mock.calls.GetEdition = append(mock.calls.GetEdition, callInfo)
 	lockStorerMockGetEdition.Unlock()
-	return mock.GetEditionFunc(ID, editionID, state)
+	return mock.GetEditionFunc(ctx, ID, editionID, state)
 }
 
 // GetEditionCalls gets all the calls that were made to GetEdition.
 // Check the length with:
 //     len(mockedStorer.GetEditionCalls())
 func (mock *StorerMock) GetEditionCalls() []struct {
+	Ctx       context.Context
 	ID        string
 	EditionID string
 	State     string
 } {
 	var calls []struct {
+		Ctx       context.Context
 		ID        string
 		EditionID string
 		State     string
@@ -531,7 +908,7 @@ func (mock *StorerMock) GetEditionCalls() []struct {
 }
 
 // GetEditions calls GetEditionsFunc.
-func (mock *StorerMock) GetEditions(ctx context.Context, ID string, state string) (*models.EditionUpdateResults, error) {
+func (mock *StorerMock) GetEditions(ctx context.Context, ID string, state string, query *models.EditionsQuery) (*models.EditionUpdateResults, error) {
 	if mock.GetEditionsFunc == nil {
 		panic("StorerMock.GetEditionsFunc: method is nil but Storer.GetEditions was just called")
 	}
@@ -539,15 +916,17 @@ func (mock *StorerMock) GetEditions(ctx context.Context, ID string, state string
 		Ctx   context.Context
 		ID    string
 		State string
+		Query *models.EditionsQuery
 	}{
 		Ctx:   ctx,
 		ID:    ID,
 		State: state,
+		Query: query,
 	}
 	lockStorerMockGetEditions.Lock()
 	mock.calls.GetEditions = append(mock.calls.GetEditions, callInfo)
 	lockStorerMockGetEditions.Unlock()
-	return mock.GetEditionsFunc(ctx, ID, state)
+	return mock.GetEditionsFunc(ctx, ID, state, query)
 }
 
 // GetEditionsCalls gets all the calls that were made to GetEditions.
@@ -557,11 +936,13 @@ func (mock *StorerMock) GetEditionsCalls() []struct {
 	Ctx   context.Context
 	ID    string
 	State string
+	Query *models.EditionsQuery
 } {
 	var calls []struct {
 		Ctx   context.Context
 		ID    string
 		State string
+		Query *models.EditionsQuery
 	}
 	lockStorerMockGetEditions.RLock()
 	calls = mock.calls.GetEditions
@@ -570,29 +951,33 @@ func (mock *StorerMock) GetEditionsCalls() []struct {
 }
 
 // GetInstance calls GetInstanceFunc.
-func (mock *StorerMock) GetInstance(ID string) (*models.Instance, error) {
+func (mock *StorerMock) GetInstance(ctx context.Context, ID string) (*models.Instance, error) {
 	if mock.GetInstanceFunc == nil {
 		panic("StorerMock.GetInstanceFunc: method is nil but Storer.GetInstance was just called")
 	}
 	callInfo := struct {
-		ID string
+		Ctx context.Context
+		ID  string
 	}{
-		ID: ID,
+		Ctx: ctx,
+		ID:  ID,
 	}
 	lockStorerMockGetInstance.Lock()
 	mock.calls.GetInstance = append(mock.calls.GetInstance, callInfo)
 	lockStorerMockGetInstance.Unlock()
-	return mock.GetInstanceFunc(ID)
+	return mock.GetInstanceFunc(ctx, ID)
 }
 
 // GetInstanceCalls gets all the calls that were made to GetInstance.
 // Check the length with:
 //     len(mockedStorer.GetInstanceCalls())
 func (mock *StorerMock) GetInstanceCalls() []struct {
-	ID string
+	Ctx context.Context
+	ID  string
 } {
 	var calls []struct {
-		ID string
+		Ctx context.Context
+		ID  string
 	}
 	lockStorerMockGetInstance.RLock()
 	calls = mock.calls.GetInstance
@@ -640,31 +1025,35 @@ func (mock *StorerMock) GetInstancesCalls() []struct {
 }
 
 // GetNextVersion calls GetNextVersionFunc.
-func (mock *StorerMock) GetNextVersion(datasetID string, editionID string) (int, error) {
+func (mock *StorerMock) GetNextVersion(ctx context.Context, datasetID string, editionID string) (int, error) {
 	if mock.GetNextVersionFunc == nil {
 		panic("StorerMock.GetNextVersionFunc: method is nil but Storer.GetNextVersion was just called")
 	}
 	callInfo := struct {
+		Ctx       context.Context
 		DatasetID string
 		EditionID string
 	}{
+		Ctx:       ctx,
 		DatasetID: datasetID,
 		EditionID: editionID,
 	}
 	lockStorerMockGetNextVersion.Lock()
 	mock.calls.GetNextVersion = append(mock.calls.GetNextVersion, callInfo)
 	lockStorerMockGetNextVersion.Unlock()
-	return mock.GetNextVersionFunc(datasetID, editionID)
+	return mock.GetNextVersionFunc(ctx, datasetID, editionID)
 }
 
 // GetNextVersionCalls gets all the calls that were made to GetNextVersion.
 // Check the length with:
 //     len(mockedStorer.GetNextVersionCalls())
 func (mock *StorerMock) GetNextVersionCalls() []struct {
+	Ctx       context.Context
 	DatasetID string
 	EditionID string
 } {
 	var calls []struct {
+		Ctx       context.Context
 		DatasetID string
 		EditionID string
 	}
@@ -675,31 +1064,35 @@ func (mock *StorerMock) GetNextVersionCalls() []struct {
 }
 
 // GetUniqueDimensionAndOptions calls GetUniqueDimensionAndOptionsFunc.
-func (mock *StorerMock) GetUniqueDimensionAndOptions(ID string, dimension string) (*models.DimensionValues, error) {
+func (mock *StorerMock) GetUniqueDimensionAndOptions(ctx context.Context, ID string, dimension string) (*models.DimensionValues, error) {
 	if mock.GetUniqueDimensionAndOptionsFunc == nil {
 		panic("StorerMock.GetUniqueDimensionAndOptionsFunc: method is nil but Storer.GetUniqueDimensionAndOptions was just called")
 	}
 	callInfo := struct {
+		Ctx       context.Context
 		ID        string
 		Dimension string
 	}{
+		Ctx:       ctx,
 		ID:        ID,
 		Dimension: dimension,
 	}
 	lockStorerMockGetUniqueDimensionAndOptions.Lock()
 	mock.calls.GetUniqueDimensionAndOptions = append(mock.calls.GetUniqueDimensionAndOptions, callInfo)
 	lockStorerMockGetUniqueDimensionAndOptions.Unlock()
-	return mock.GetUniqueDimensionAndOptionsFunc(ID, dimension)
+	return mock.GetUniqueDimensionAndOptionsFunc(ctx, ID, dimension)
 }
 
 // GetUniqueDimensionAndOptionsCalls gets all the calls that were made to GetUniqueDimensionAndOptions.
 // Check the length with:
 //     len(mockedStorer.GetUniqueDimensionAndOptionsCalls())
 func (mock *StorerMock) GetUniqueDimensionAndOptionsCalls() []struct {
+	Ctx       context.Context
 	ID        string
 	Dimension string
 } {
 	var calls []struct {
+		Ctx       context.Context
 		ID        string
 		Dimension string
 	}
@@ -710,16 +1103,18 @@ func (mock *StorerMock) GetUniqueDimensionAndOptionsCalls() []struct {
 }
 
 // GetVersion calls GetVersionFunc.
-func (mock *StorerMock) GetVersion(datasetID string, editionID string, version string, state string) (*models.Version, error) {
+func (mock *StorerMock) GetVersion(ctx context.Context, datasetID string, editionID string, version string, state string) (*models.Version, error) {
 	if mock.GetVersionFunc == nil {
 		panic("StorerMock.GetVersionFunc: method is nil but Storer.GetVersion was just called")
 	}
 	callInfo := struct {
+		Ctx       context.Context
 		DatasetID string
 		EditionID string
 		Version   string
 		State     string
 	}{
+		Ctx:       ctx,
 		DatasetID: datasetID,
 		EditionID: editionID,
 		Version:   version,
@@ -728,19 +1123,21 @@ func (mock *StorerMock) GetVersion(datasetID string, editionID string, version s
 	lockStorerMockGetVersion.Lock()
 	mock.calls.GetVersion = append(mock.calls.GetVersion, callInfo)
 	lockStorerMockGetVersion.Unlock()
-	return mock.GetVersionFunc(datasetID, editionID, version, state)
+	return mock.GetVersionFunc(ctx, datasetID, editionID, version, state)
 }
 
 // GetVersionCalls gets all the calls that were made to GetVersion.
 // Check the length with:
 //     len(mockedStorer.GetVersionCalls())
 func (mock *StorerMock) GetVersionCalls() []struct {
+	Ctx       context.Context
 	DatasetID string
 	EditionID string
 	Version   string
 	State     string
 } {
 	var calls []struct {
+		Ctx       context.Context
 		DatasetID string
 		EditionID string
 		Version   string
@@ -753,7 +1150,7 @@ func (mock *StorerMock) GetVersionCalls() []struct {
 }
 
 // GetVersions calls GetVersionsFunc.
-func (mock *StorerMock) GetVersions(ctx context.Context, datasetID string, editionID string, state string) (*models.VersionResults, error) {
+func (mock *StorerMock) GetVersions(ctx context.Context, datasetID string, editionID string, state string, query *models.VersionsQuery) (*models.VersionResults, error) {
 	if mock.GetVersionsFunc == nil {
 		panic("StorerMock.GetVersionsFunc: method is nil but Storer.GetVersions was just called")
 	}
@@ -762,16 +1159,18 @@ func (mock *StorerMock) GetVersions(ctx context.Context, datasetID string, editi
 		DatasetID string
 		EditionID string
 		State     string
+		Query     *models.VersionsQuery
 	}{
 		Ctx:       ctx,
 		DatasetID: datasetID,
 		EditionID: editionID,
 		State:     state,
+		Query:     query,
 	}
 	lockStorerMockGetVersions.Lock()
 	mock.calls.GetVersions = append(mock.calls.GetVersions, callInfo)
 	lockStorerMockGetVersions.Unlock()
-	return mock.GetVersionsFunc(ctx, datasetID, editionID, state)
+	return mock.GetVersionsFunc(ctx, datasetID, editionID, state, query)
 }
 
 // GetVersionsCalls gets all the calls that were made to GetVersions.
@@ -782,15 +1181,181 @@ func (mock *StorerMock) GetVersionsCalls() []struct {
 	DatasetID string
 	EditionID string
 	State     string
+	Query     *models.VersionsQuery
 } {
 	var calls []struct {
 		Ctx       context.Context
 		DatasetID string
 		EditionID string
 		State     string
+		Query     *models.VersionsQuery
 	}
 	lockStorerMockGetVersions.RLock()
 	calls = mock.calls.GetVersions
 	lockStorerMockGetVersions.RUnlock()
 	return calls
 }
+
+// UpdateDataset calls UpdateDatasetFunc.
+func (mock *StorerMock) UpdateDataset(ctx context.Context, ID string, dataset *models.Dataset, currentState string) error {
+	if mock.UpdateDatasetFunc == nil {
+		panic("StorerMock.UpdateDatasetFunc: method is nil but Storer.UpdateDataset was just called")
+	}
+	callInfo := struct {
+		Ctx          context.Context
+		ID           string
+		Dataset      *models.Dataset
+		CurrentState string
+	}{
+		Ctx:          ctx,
+		ID:           ID,
+		Dataset:      dataset,
+		CurrentState: currentState,
+	}
+	lockStorerMockUpdateDataset.Lock()
+	mock.calls.UpdateDataset = append(mock.calls.UpdateDataset, callInfo)
+	lockStorerMockUpdateDataset.Unlock()
+	return mock.UpdateDatasetFunc(ctx, ID, dataset, currentState)
+}
+
+// UpdateDatasetCalls gets all the calls that were made to UpdateDataset.
+// Check the length with:
+//     len(mockedStorer.UpdateDatasetCalls())
+func (mock *StorerMock) UpdateDatasetCalls() []struct {
+	Ctx          context.Context
+	ID           string
+	Dataset      *models.Dataset
+	CurrentState string
+} {
+	var calls []struct {
+		Ctx          context.Context
+		ID           string
+		Dataset      *models.Dataset
+		CurrentState string
+	}
+	lockStorerMockUpdateDataset.RLock()
+	calls = mock.calls.UpdateDataset
+	lockStorerMockUpdateDataset.RUnlock()
+	return calls
+}
+
+// UpdateVersion calls UpdateVersionFunc.
+func (mock *StorerMock) UpdateVersion(ctx context.Context, ID string, version *models.Version) error {
+	if mock.UpdateVersionFunc == nil {
+		panic("StorerMock.UpdateVersionFunc: method is nil but Storer.UpdateVersion was just called")
+	}
+	callInfo := struct {
+		Ctx     context.Context
+		ID      string
+		Version *models.Version
+	}{
+		Ctx:     ctx,
+		ID:      ID,
+		Version: version,
+	}
+	lockStorerMockUpdateVersion.Lock()
+	mock.calls.UpdateVersion = append(mock.calls.UpdateVersion, callInfo)
+	lockStorerMockUpdateVersion.Unlock()
+	return mock.UpdateVersionFunc(ctx, ID, version)
+}
+
+// UpdateVersionCalls gets all the calls that were made to UpdateVersion.
+// Check the length with:
+//     len(mockedStorer.UpdateVersionCalls())
+func (mock *StorerMock) UpdateVersionCalls() []struct {
+	Ctx     context.Context
+	ID      string
+	Version *models.Version
+} {
+	var calls []struct {
+		Ctx     context.Context
+		ID      string
+		Version *models.Version
+	}
+	lockStorerMockUpdateVersion.RLock()
+	calls = mock.calls.UpdateVersion
+	lockStorerMockUpdateVersion.RUnlock()
+	return calls
+}
+
+// UpsertDataset calls UpsertDatasetFunc.
+func (mock *StorerMock) UpsertDataset(ctx context.Context, ID string, datasetDoc *models.DatasetUpdate) error {
+	if mock.UpsertDatasetFunc == nil {
+		panic("StorerMock.UpsertDatasetFunc: method is nil but Storer.UpsertDataset was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		ID         string
+		DatasetDoc *models.DatasetUpdate
+	}{
+		Ctx:        ctx,
+		ID:         ID,
+		DatasetDoc: datasetDoc,
+	}
+	lockStorerMockUpsertDataset.Lock()
+	mock.calls.UpsertDataset = append(mock.calls.UpsertDataset, callInfo)
+	lockStorerMockUpsertDataset.Unlock()
+	return mock.UpsertDatasetFunc(ctx, ID, datasetDoc)
+}
+
+// UpsertDatasetCalls gets all the calls that were made to UpsertDataset.
+// Check the length with:
+//     len(mockedStorer.UpsertDatasetCalls())
+func (mock *StorerMock) UpsertDatasetCalls() []struct {
+	Ctx        context.Context
+	ID         string
+	DatasetDoc *models.DatasetUpdate
+} {
+	var calls []struct {
+		Ctx        context.Context
+		ID         string
+		DatasetDoc *models.DatasetUpdate
+	}
+	lockStorerMockUpsertDataset.RLock()
+	calls = mock.calls.UpsertDataset
+	lockStorerMockUpsertDataset.RUnlock()
+	return calls
+}
+
+// UpsertEdition calls UpsertEditionFunc.
+func (mock *StorerMock) UpsertEdition(ctx context.Context, datasetID string, edition string, editionDoc *models.EditionUpdate) error {
+	if mock.UpsertEditionFunc == nil {
+		panic("StorerMock.UpsertEditionFunc: method is nil but Storer.UpsertEdition was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		DatasetID  string
+		Edition    string
+		EditionDoc *models.EditionUpdate
+	}{
+		Ctx:        ctx,
+		DatasetID:  datasetID,
+		Edition:    edition,
+		EditionDoc: editionDoc,
+	}
+	lockStorerMockUpsertEdition.Lock()
+	mock.calls.UpsertEdition = append(mock.calls.UpsertEdition, callInfo)
+	lockStorerMockUpsertEdition.Unlock()
+	return mock.UpsertEditionFunc(ctx, datasetID, edition, editionDoc)
+}
+
+// UpsertEditionCalls gets all the calls that were made to UpsertEdition.
+// Check the length with:
+//     len(mockedStorer.UpsertEditionCalls())
+func (mock *StorerMock) UpsertEditionCalls() []struct {
+	Ctx        context.Context
+	DatasetID  string
+	Edition    string
+	EditionDoc *models.EditionUpdate
+} {
+	var calls []struct {
+		Ctx        context.Context
+		DatasetID  string
+		Edition    string
+		EditionDoc *models.EditionUpdate
+	}
+	lockStorerMockUpsertEdition.RLock()
+	calls = mock.calls.UpsertEdition
+	lockStorerMockUpsertEdition.RUnlock()
+	return calls
+}
@@ -0,0 +1,711 @@
+// Package storertest provides the behaviour expected of every store.Storer, so that the in-memory and mongo stores
+// are checked against the same expectations.
+package storertest
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"testing"
+
+	errs "github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/apierrors"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/models"
+	"github.com/ONSdigital/dp-census-alpha-ftb-dataset-api/store"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const (
+	host = "http://localhost:10400"
+
+	publishedVersionID   = "instance-1"
+	confirmedVersionID   = "instance-2"
+	unconfirmedVersionID = "instance-3"
+)
+
+// Run runs the behaviour suite against the stores returned by newStorer, which is called before each test and
+// must return a store without any documents
+func Run(t *testing.T, newStorer func() store.Storer) {
+	ctx := context.Background()
+
+	Convey("Given a store holding a published dataset and a dataset that has not been published", t, func() {
+		s := newStorer()
+		So(seed(ctx, s), ShouldBeNil)
+
+		Convey("When every dataset is requested", func() {
+			results, err := s.GetDatasets(ctx, 0, 20, true)
+
+			Convey("Then both datasets are returned, sorted by id", func() {
+				So(err, ShouldBeNil)
+				So(results.TotalCount, ShouldEqual, 2)
+				So(results.Count, ShouldEqual, 2)
+				So(results.Items[0].ID, ShouldEqual, "Households")
+				So(results.Items[1].ID, ShouldEqual, "People")
+			})
+		})
+
+		Convey("When the published datasets are requested", func() {
+			results, err := s.GetDatasets(ctx, 0, 20, false)
+
+			Convey("Then only the published dataset is returned", func() {
+				So(err, ShouldBeNil)
+				So(results.TotalCount, ShouldEqual, 1)
+				So(results.Items[0].ID, ShouldEqual, "People")
+			})
+		})
+
+		Convey("When there is a dataset with only a current revision", func() {
+			regions := &models.Dataset{ID: "Regions", State: models.PublishedState, Title: "Regions"}
+			So(s.UpsertDataset(ctx, "Regions", &models.DatasetUpdate{ID: "Regions", Current: regions}), ShouldBeNil)
+
+			Convey("Then it is only listed and counted among the published datasets", func() {
+				results, err := s.GetDatasets(ctx, 0, 20, true)
+				So(err, ShouldBeNil)
+				So(results.TotalCount, ShouldEqual, 2)
+				So(results.Count, ShouldEqual, 2)
+				for _, dataset := range results.Items {
+					So(dataset.Next, ShouldNotBeNil)
+				}
+
+				results, err = s.GetDatasets(ctx, 0, 20, false)
+				So(err, ShouldBeNil)
+				So(results.TotalCount, ShouldEqual, 2)
+				So(results.Items[0].ID, ShouldEqual, "People")
+				So(results.Items[1].ID, ShouldEqual, "Regions")
+			})
+		})
+
+		Convey("When a page of datasets is requested", func() {
+			results, err := s.GetDatasets(ctx, 1, 1, true)
+
+			Convey("Then the page is returned along with the total number of datasets", func() {
+				So(err, ShouldBeNil)
+				So(results.Count, ShouldEqual, 1)
+				So(results.TotalCount, ShouldEqual, 2)
+				So(results.Items[0].ID, ShouldEqual, "People")
+			})
+		})
+
+		Convey("When an empty page of datasets is requested", func() {
+			results, err := s.GetDatasets(ctx, 0, 0, true)
+
+			Convey("Then no datasets are returned, but they are counted", func() {
+				So(err, ShouldBeNil)
+				So(results.Items, ShouldNotBeNil)
+				So(results.Items, ShouldBeEmpty)
+				So(results.TotalCount, ShouldEqual, 2)
+			})
+		})
+
+		Convey("When a dataset is requested", func() {
+			dataset, err := s.GetDataset(ctx, "People")
+
+			Convey("Then both of its revisions are returned", func() {
+				So(err, ShouldBeNil)
+				So(dataset.ID, ShouldEqual, "People")
+				So(dataset.Current.Title, ShouldEqual, "People")
+				So(dataset.Next.Title, ShouldEqual, "People")
+			})
+		})
+
+		Convey("When a dataset that does not exist is requested", func() {
+			_, err := s.GetDataset(ctx, "Cars")
+
+			Convey("Then dataset not found is returned", func() {
+				So(err, ShouldEqual, errs.ErrDatasetNotFound)
+			})
+		})
+
+		Convey("When the existence of the datasets is checked", func() {
+			Convey("Then only the published dataset exists in the published state", func() {
+				So(s.CheckDatasetExists(ctx, "People", models.PublishedState), ShouldBeNil)
+				So(s.CheckDatasetExists(ctx, "Households", models.PublishedState), ShouldEqual, errs.ErrDatasetNotFound)
+			})
+
+			Convey("Then both datasets exist in any state", func() {
+				So(s.CheckDatasetExists(ctx, "People", ""), ShouldBeNil)
+				So(s.CheckDatasetExists(ctx, "Households", ""), ShouldBeNil)
+				So(s.CheckDatasetExists(ctx, "Cars", ""), ShouldEqual, errs.ErrDatasetNotFound)
+			})
+		})
+
+		Convey("When a published dataset is updated", func() {
+			err := s.UpdateDataset(ctx, "People", &models.Dataset{Title: "People and places"}, models.PublishedState)
+			So(err, ShouldBeNil)
+
+			Convey("Then a new revision is created with the fields provided", func() {
+				dataset, err := s.GetDataset(ctx, "People")
+				So(err, ShouldBeNil)
+				So(dataset.Next.Title, ShouldEqual, "People and places")
+				So(dataset.Next.State, ShouldEqual, models.CreatedState)
+				So(dataset.Next.Description, ShouldEqual, "Everyone counted by the census")
+				So(dataset.Current.Title, ShouldEqual, "People")
+			})
+		})
+
+		Convey("When a dataset that does not exist is updated", func() {
+			err := s.UpdateDataset(ctx, "Cars", &models.Dataset{Title: "Cars"}, "")
+
+			Convey("Then dataset not found is returned", func() {
+				So(err, ShouldEqual, errs.ErrDatasetNotFound)
+			})
+		})
+
+		Convey("When a dataset is upserted again", func() {
+			err := s.UpsertDataset(ctx, "Households", &models.DatasetUpdate{
+				ID:   "Households",
+				Next: &models.Dataset{State: models.CreatedState, Title: "Households and families"},
+			})
+			So(err, ShouldBeNil)
+
+			Convey("Then the dataset is replaced rather than added", func() {
+				dataset, err := s.GetDataset(ctx, "Households")
+				So(err, ShouldBeNil)
+				So(dataset.Next.Title, ShouldEqual, "Households and families")
+
+				results, err := s.GetDatasets(ctx, 0, 20, true)
+				So(err, ShouldBeNil)
+				So(results.TotalCount, ShouldEqual, 2)
+			})
+		})
+	})
+
+	Convey("Given a store holding a published edition", t, func() {
+		s := newStorer()
+		So(seed(ctx, s), ShouldBeNil)
+
+		Convey("When the editions of the dataset are requested", func() {
+			Convey("Then the published edition is returned in either state", func() {
+				results, err := s.GetEditions(ctx, "People", models.PublishedState, nil)
+				So(err, ShouldBeNil)
+				So(results.Items, ShouldHaveLength, 1)
+				So(results.Items[0].Current.Edition, ShouldEqual, "2011")
+
+				results, err = s.GetEditions(ctx, "People", "", nil)
+				So(err, ShouldBeNil)
+				So(results.Items, ShouldHaveLength, 1)
+			})
+
+			Convey("Then a dataset without editions has none returned", func() {
+				_, err := s.GetEditions(ctx, "Households", "", nil)
+				So(err, ShouldEqual, errs.ErrEditionNotFound)
+			})
+		})
+
+		Convey("When the editions of the dataset are filtered by state", func() {
+			Convey("Then only editions in that state are returned", func() {
+				results, err := s.GetEditions(ctx, "People", "", &models.EditionsQuery{State: models.PublishedState})
+				So(err, ShouldBeNil)
+				So(results.Items, ShouldHaveLength, 1)
+
+				_, err = s.GetEditions(ctx, "People", "", &models.EditionsQuery{State: models.CreatedState})
+				So(err, ShouldEqual, errs.ErrEditionNotFound)
+			})
+
+			Convey("Then a filter cannot select editions outside the state requested", func() {
+				_, err := s.GetEditions(ctx, "People", models.PublishedState, &models.EditionsQuery{State: models.CreatedState})
+				So(err, ShouldEqual, errs.ErrEditionNotFound)
+			})
+		})
+
+		Convey("When an edition is requested", func() {
+			edition, err := s.GetEdition(ctx, "People", "2011", models.PublishedState)
+
+			Convey("Then it is returned", func() {
+				So(err, ShouldBeNil)
+				So(edition.ID, ShouldEqual, "people-2011")
+				So(edition.Current.State, ShouldEqual, models.PublishedState)
+			})
+		})
+
+		Convey("When an edition that does not exist is requested", func() {
+			_, err := s.GetEdition(ctx, "People", "2021", "")
+
+			Convey("Then edition not found is returned", func() {
+				So(err, ShouldEqual, errs.ErrEditionNotFound)
+				So(s.CheckEditionExists(ctx, "People", "2021", ""), ShouldEqual, errs.ErrEditionNotFound)
+			})
+		})
+
+		Convey("When the existence of the edition is checked", func() {
+			Convey("Then it exists in the published state and in any state", func() {
+				So(s.CheckEditionExists(ctx, "People", "2011", models.PublishedState), ShouldBeNil)
+				So(s.CheckEditionExists(ctx, "People", "2011", ""), ShouldBeNil)
+			})
+		})
+
+		Convey("When the edition is upserted again", func() {
+			edition, err := s.GetEdition(ctx, "People", "2011", "")
+			So(err, ShouldBeNil)
+			edition.Next.State = models.EditionConfirmedState
+			So(s.UpsertEdition(ctx, "People", "2011", edition), ShouldBeNil)
+
+			Convey("Then the edition is replaced rather than added", func() {
+				results, err := s.GetEditions(ctx, "People", "", nil)
+				So(err, ShouldBeNil)
+				So(results.Items, ShouldHaveLength, 1)
+				So(results.Items[0].Next.State, ShouldEqual, models.EditionConfirmedState)
+				So(results.Items[0].Current.State, ShouldEqual, models.PublishedState)
+			})
+		})
+	})
+
+	Convey("Given a store holding a published, a confirmed and an unconfirmed version of an edition", t, func() {
+		s := newStorer()
+		So(seed(ctx, s), ShouldBeNil)
+
+		Convey("When the next version number is requested", func() {
+			Convey("Then it follows the latest version", func() {
+				next, err := s.GetNextVersion(ctx, "People", "2011")
+				So(err, ShouldBeNil)
+				So(next, ShouldEqual, 4)
+			})
+
+			Convey("Then the first version of an edition without versions is 1", func() {
+				next, err := s.GetNextVersion(ctx, "People", "2021")
+				So(err, ShouldBeNil)
+				So(next, ShouldEqual, 1)
+			})
+		})
+
+		Convey("When the versions of the edition are requested", func() {
+			results, err := s.GetVersions(ctx, "People", "2011", "", nil)
+
+			Convey("Then only the confirmed and published versions are returned, linked to themselves", func() {
+				So(err, ShouldBeNil)
+				So(results.Items, ShouldHaveLength, 2)
+				for _, version := range results.Items {
+					So(version.State, ShouldNotEqual, models.CreatedState)
+					So(version.Links.Self.HRef, ShouldEqual, version.Links.Version.HRef)
+				}
+			})
+		})
+
+		Convey("When the published versions of the edition are requested", func() {
+			results, err := s.GetVersions(ctx, "People", "2011", models.PublishedState, nil)
+
+			Convey("Then only the published version is returned", func() {
+				So(err, ShouldBeNil)
+				So(results.Items, ShouldHaveLength, 1)
+				So(results.Items[0].ID, ShouldEqual, publishedVersionID)
+			})
+		})
+
+		Convey("When the versions of the edition are filtered by state", func() {
+			Convey("Then only versions in that state are returned", func() {
+				results, err := s.GetVersions(ctx, "People", "2011", "", &models.VersionsQuery{State: models.EditionConfirmedState})
+				So(err, ShouldBeNil)
+				So(results.Items, ShouldHaveLength, 1)
+				So(results.Items[0].ID, ShouldEqual, confirmedVersionID)
+			})
+
+			Convey("Then a filter cannot select versions outside the state requested", func() {
+				_, err := s.GetVersions(ctx, "People", "2011", models.PublishedState, &models.VersionsQuery{State: models.EditionConfirmedState})
+				So(err, ShouldEqual, errs.ErrVersionNotFound)
+
+				_, err = s.GetVersions(ctx, "People", "2011", "", &models.VersionsQuery{State: models.CreatedState})
+				So(err, ShouldEqual, errs.ErrVersionNotFound)
+			})
+		})
+
+		Convey("When the versions of the edition are filtered by release date", func() {
+			Convey("Then only versions released within the inclusive bounds are returned", func() {
+				results, err := s.GetVersions(ctx, "People", "2011", "", &models.VersionsQuery{ReleaseDateFrom: "2013-01-30T09:30:00.000Z"})
+				So(err, ShouldBeNil)
+				So(results.Items, ShouldHaveLength, 1)
+				So(results.Items[0].ID, ShouldEqual, publishedVersionID)
+
+				results, err = s.GetVersions(ctx, "People", "2011", "", &models.VersionsQuery{ReleaseDateTo: "2013-01-30T09:29:59.999Z"})
+				So(err, ShouldBeNil)
+				So(results.Items, ShouldHaveLength, 1)
+				So(results.Items[0].ID, ShouldEqual, confirmedVersionID)
+
+				_, err = s.GetVersions(ctx, "People", "2011", "", &models.VersionsQuery{
+					ReleaseDateFrom: "2012-06-02T00:00:00.000Z",
+					ReleaseDateTo:   "2013-01-29T23:59:59.999Z",
+				})
+				So(err, ShouldEqual, errs.ErrVersionNotFound)
+			})
+		})
+
+		Convey("When the versions of the edition are filtered by version", func() {
+			results, err := s.GetVersions(ctx, "People", "2011", "", &models.VersionsQuery{Version: 2})
+
+			Convey("Then only that version is returned", func() {
+				So(err, ShouldBeNil)
+				So(results.Items, ShouldHaveLength, 1)
+				So(results.Items[0].ID, ShouldEqual, confirmedVersionID)
+			})
+		})
+
+		Convey("When the versions of the edition are sorted", func() {
+			Convey("Then they are returned in the order requested", func() {
+				for order, expected := range map[string][]string{
+					models.VersionSortVersion:         {publishedVersionID, confirmedVersionID},
+					models.VersionSortVersionDesc:     {confirmedVersionID, publishedVersionID},
+					models.VersionSortReleaseDate:     {confirmedVersionID, publishedVersionID},
+					models.VersionSortReleaseDateDesc: {publishedVersionID, confirmedVersionID},
+				} {
+					results, err := s.GetVersions(ctx, "People", "2011", "", &models.VersionsQuery{Sort: order})
+					So(err, ShouldBeNil)
+
+					var ids []string
+					for _, version := range results.Items {
+						ids = append(ids, version.ID)
+					}
+					So(ids, ShouldResemble, expected)
+				}
+			})
+		})
+
+		Convey("When the versions of an edition without versions are requested", func() {
+			_, err := s.GetVersions(ctx, "People", "2021", "", nil)
+
+			Convey("Then version not found is returned", func() {
+				So(err, ShouldEqual, errs.ErrVersionNotFound)
+			})
+		})
+
+		Convey("When a version is requested", func() {
+			Convey("Then the published version is returned in the published state", func() {
+				version, err := s.GetVersion(ctx, "People", "2011", "1", models.PublishedState)
+				So(err, ShouldBeNil)
+				So(version.ID, ShouldEqual, publishedVersionID)
+			})
+
+			Convey("Then the confirmed version is only returned without a state", func() {
+				version, err := s.GetVersion(ctx, "People", "2011", "2", "")
+				So(err, ShouldBeNil)
+				So(version.ID, ShouldEqual, confirmedVersionID)
+
+				_, err = s.GetVersion(ctx, "People", "2011", "2", models.PublishedState)
+				So(err, ShouldEqual, errs.ErrVersionNotFound)
+			})
+
+			Convey("Then a version that does not exist is not found", func() {
+				_, err := s.GetVersion(ctx, "People", "2011", "9", "")
+				So(err, ShouldEqual, errs.ErrVersionNotFound)
+			})
+		})
+
+		Convey("When a version is published", func() {
+			err := s.UpdateVersion(ctx, confirmedVersionID, &models.Version{State: models.PublishedState, ReleaseDate: "2021-03-21T00:00:00.000Z"})
+			So(err, ShouldBeNil)
+
+			Convey("Then it is returned in the published state and no longer belongs to a collection", func() {
+				version, err := s.GetVersion(ctx, "People", "2011", "2", models.PublishedState)
+				So(err, ShouldBeNil)
+				So(version.ReleaseDate, ShouldEqual, "2021-03-21T00:00:00.000Z")
+				So(version.CollectionID, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When a version that does not exist is updated", func() {
+			err := s.UpdateVersion(ctx, "instance-9", &models.Version{State: models.PublishedState})
+
+			Convey("Then version not found is returned", func() {
+				So(err, ShouldEqual, errs.ErrVersionNotFound)
+			})
+		})
+
+		Convey("When the instances are requested", func() {
+			Convey("Then every instance is returned, most recently added first", func() {
+				results, err := s.GetInstances(ctx, nil, nil)
+				So(err, ShouldBeNil)
+				So(results.Items, ShouldHaveLength, 3)
+				So(results.Items[0].InstanceID, ShouldEqual, unconfirmedVersionID)
+				So(results.Items[2].InstanceID, ShouldEqual, publishedVersionID)
+			})
+
+			Convey("Then they can be filtered by state and dataset", func() {
+				results, err := s.GetInstances(ctx, []string{models.PublishedState}, []string{"People"})
+				So(err, ShouldBeNil)
+				So(results.Items, ShouldHaveLength, 1)
+
+				results, err = s.GetInstances(ctx, nil, []string{"Households"})
+				So(err, ShouldBeNil)
+				So(results.Items, ShouldBeEmpty)
+			})
+		})
+
+		Convey("When an instance is requested", func() {
+			Convey("Then it is returned", func() {
+				instance, err := s.GetInstance(ctx, publishedVersionID)
+				So(err, ShouldBeNil)
+				So(instance.Version, ShouldEqual, 1)
+			})
+
+			Convey("Then an instance that does not exist is not found", func() {
+				_, err := s.GetInstance(ctx, "instance-9")
+				So(err, ShouldEqual, errs.ErrInstanceNotFound)
+			})
+		})
+
+		Convey("When the links to a table are added", func() {
+			links := &models.TableLinks{
+				Dataset: models.Table{HRef: host + "/datasets/people-by-sex", Title: "People by sex"},
+				Edition: models.Table{HRef: host + "/datasets/people-by-sex/editions/2011", Title: "People by sex"},
+				Version: models.Table{HRef: host + "/datasets/people-by-sex/editions/2011/versions/1", Title: "People by sex"},
+			}
+			err := s.AddTableLinks(ctx, "People", "2011", publishedVersionID, links)
+
+			Convey("Then the table is appended to the current and next dataset, edition and version", func() {
+				So(err, ShouldBeNil)
+
+				dataset, err := s.GetDataset(ctx, "People")
+				So(err, ShouldBeNil)
+				So(*dataset.Current.Tables, ShouldResemble, []models.Table{links.Dataset})
+				So(*dataset.Next.Tables, ShouldResemble, []models.Table{links.Dataset})
+
+				edition, err := s.GetEdition(ctx, "People", "2011", models.PublishedState)
+				So(err, ShouldBeNil)
+				So(*edition.Current.Tables, ShouldResemble, []models.Table{links.Edition})
+				So(*edition.Next.Tables, ShouldResemble, []models.Table{links.Edition})
+
+				version, err := s.GetVersion(ctx, "People", "2011", "1", "")
+				So(err, ShouldBeNil)
+				So(*version.Tables, ShouldResemble, []models.Table{links.Version})
+			})
+
+			Convey("Then links to an edition or version that does not exist are not added", func() {
+				So(s.AddTableLinks(ctx, "People", "2021", publishedVersionID, links), ShouldEqual, errs.ErrEditionNotFound)
+				So(s.AddTableLinks(ctx, "People", "2011", "instance-9", links), ShouldEqual, errs.ErrVersionNotFound)
+				So(s.AddTableLinks(ctx, "Cars", "2011", publishedVersionID, links), ShouldEqual, errs.ErrDatasetNotFound)
+			})
+
+			Convey("Then adding them again leaves the table linked once", func() {
+				So(s.AddTableLinks(ctx, "People", "2011", publishedVersionID, links), ShouldBeNil)
+
+				dataset, err := s.GetDataset(ctx, "People")
+				So(err, ShouldBeNil)
+				So(*dataset.Current.Tables, ShouldHaveLength, 1)
+				So(*dataset.Next.Tables, ShouldHaveLength, 1)
+
+				edition, err := s.GetEdition(ctx, "People", "2011", models.PublishedState)
+				So(err, ShouldBeNil)
+				So(*edition.Next.Tables, ShouldHaveLength, 1)
+
+				version, err := s.GetVersion(ctx, "People", "2011", "1", "")
+				So(err, ShouldBeNil)
+				So(*version.Tables, ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When a version is added with the id of an existing version", func() {
+			version, err := s.GetVersion(ctx, "People", "2011", "1", "")
+			So(err, ShouldBeNil)
+			version.ReleaseDate = "2013-02-01T09:30:00.000Z"
+			So(s.AddVersion(ctx, version), ShouldBeNil)
+
+			Convey("Then the existing version is replaced", func() {
+				instances, err := s.GetInstances(ctx, nil, []string{"People"})
+				So(err, ShouldBeNil)
+				So(instances.Items, ShouldHaveLength, 3)
+
+				version, err := s.GetVersion(ctx, "People", "2011", "1", "")
+				So(err, ShouldBeNil)
+				So(version.ReleaseDate, ShouldEqual, "2013-02-01T09:30:00.000Z")
+			})
+		})
+	})
+
+	Convey("Given a store holding the dimension options of a version", t, func() {
+		s := newStorer()
+		So(seed(ctx, s), ShouldBeNil)
+
+		version, err := s.GetVersion(ctx, "People", "2011", "1", models.PublishedState)
+		So(err, ShouldBeNil)
+
+		Convey("When the dimensions of the version are requested", func() {
+			results, err := s.GetDimensions(ctx, "People", publishedVersionID)
+
+			Convey("Then each dimension is returned once", func() {
+				So(err, ShouldBeNil)
+
+				var names []string
+				for _, result := range results {
+					names = append(names, result.Name)
+				}
+				sort.Strings(names)
+				So(names, ShouldResemble, []string{"age", "sex"})
+			})
+		})
+
+		Convey("When the dimensions of a version without options are requested", func() {
+			_, err := s.GetDimensions(ctx, "People", confirmedVersionID)
+
+			Convey("Then dimensions not found is returned", func() {
+				So(err, ShouldEqual, errs.ErrDimensionsNotFound)
+			})
+		})
+
+		Convey("When the options of the version are requested", func() {
+			results, err := s.GetDimensionsFromInstance(ctx, publishedVersionID)
+
+			Convey("Then every option is returned without the fields used to store it", func() {
+				So(err, ShouldBeNil)
+				So(results.Items, ShouldHaveLength, 5)
+				for _, option := range results.Items {
+					So(option.InstanceID, ShouldBeEmpty)
+					So(option.LastUpdated.IsZero(), ShouldBeTrue)
+				}
+			})
+		})
+
+		Convey("When the distinct options of a dimension are requested", func() {
+			Convey("Then each option is returned once", func() {
+				values, err := s.GetUniqueDimensionAndOptions(ctx, publishedVersionID, "sex")
+				So(err, ShouldBeNil)
+				So(values.Name, ShouldEqual, "sex")
+				So(values.Options, ShouldHaveLength, 2)
+				So(values.Options, ShouldContain, "F")
+				So(values.Options, ShouldContain, "M")
+			})
+
+			Convey("Then a dimension without options is not found", func() {
+				_, err := s.GetUniqueDimensionAndOptions(ctx, publishedVersionID, "siblings")
+				So(err, ShouldEqual, errs.ErrDimensionNodeNotFound)
+			})
+		})
+
+		Convey("When a page of the options of a dimension is requested", func() {
+			results, err := s.GetDimensionOptions(ctx, version, "age", 1, 1)
+
+			Convey("Then the page is taken from the options sorted by option, linked to the version", func() {
+				So(err, ShouldBeNil)
+				So(results.TotalCount, ShouldEqual, 3)
+				So(results.Count, ShouldEqual, 1)
+				So(results.Items[0].Option, ShouldEqual, "2")
+				So(results.Items[0].Links.Version, ShouldResemble, *version.Links.Self)
+			})
+		})
+
+		Convey("When an empty page of the options of a dimension is requested", func() {
+			results, err := s.GetDimensionOptions(ctx, version, "age", 0, 0)
+
+			Convey("Then no options are returned, but they are counted", func() {
+				So(err, ShouldBeNil)
+				So(results.Items, ShouldNotBeNil)
+				So(results.Items, ShouldBeEmpty)
+				So(results.TotalCount, ShouldEqual, 3)
+			})
+		})
+
+		Convey("When options of a dimension are requested by id", func() {
+			results, err := s.GetDimensionOptionsFromIDs(ctx, version, "age", []string{"3", "1", "9"})
+
+			Convey("Then the options that exist are returned, sorted by option", func() {
+				So(err, ShouldBeNil)
+				So(results.Count, ShouldEqual, 2)
+				So(results.Limit, ShouldEqual, 3)
+				So(results.Items[0].Option, ShouldEqual, "1")
+				So(results.Items[1].Option, ShouldEqual, "3")
+			})
+		})
+
+		Convey("When options that exist are added again", func() {
+			err := s.AddDimensionOptions(ctx, []*models.DimensionOption{{InstanceID: publishedVersionID, Label: "Aged 1", Name: "age", Option: "1"}})
+
+			Convey("Then they replace the existing options", func() {
+				So(err, ShouldBeNil)
+				results, err := s.GetDimensionOptions(ctx, version, "age", 0, 1)
+				So(err, ShouldBeNil)
+				So(results.TotalCount, ShouldEqual, 3)
+				So(results.Items[0].Label, ShouldEqual, "Aged 1")
+			})
+		})
+
+		Convey("When options of a dimension are requested for a version that does not link to itself", func() {
+			unlinked := *version
+			unlinked.Links = nil
+
+			Convey("Then the options are returned without a link to the version", func() {
+				results, err := s.GetDimensionOptions(ctx, &unlinked, "age", 0, 1)
+				So(err, ShouldBeNil)
+				So(results.Items, ShouldHaveLength, 1)
+				So(results.Items[0].Links.Version, ShouldResemble, models.LinkObject{})
+
+				results, err = s.GetDimensionOptionsFromIDs(ctx, &unlinked, "age", []string{"1"})
+				So(err, ShouldBeNil)
+				So(results.Items, ShouldHaveLength, 1)
+				So(results.Items[0].Links.Version, ShouldResemble, models.LinkObject{})
+			})
+		})
+	})
+}
+
+// seed adds the documents the suite is run against through the write methods of the store
+func seed(ctx context.Context, s store.Storer) error {
+	people := &models.Dataset{
+		Description: "Everyone counted by the census",
+		ID:          "People",
+		State:       models.PublishedState,
+		Title:       "People",
+	}
+	if err := s.UpsertDataset(ctx, "People", &models.DatasetUpdate{ID: "People", Current: people, Next: people}); err != nil {
+		return err
+	}
+
+	households := &models.Dataset{ID: "Households", State: models.CreatedState, Title: "Households"}
+	if err := s.UpsertDataset(ctx, "Households", &models.DatasetUpdate{ID: "Households", Next: households}); err != nil {
+		return err
+	}
+
+	edition := &models.Edition{
+		Edition: "2011",
+		ID:      "people-2011",
+		Links: &models.EditionUpdateLinks{
+			Dataset: &models.LinkObject{ID: "People", HRef: host + "/datasets/People"},
+			Self:    &models.LinkObject{HRef: host + "/datasets/People/editions/2011"},
+		},
+		State: models.PublishedState,
+	}
+	if err := s.UpsertEdition(ctx, "People", "2011", &models.EditionUpdate{ID: "people-2011", Current: edition, Next: edition}); err != nil {
+		return err
+	}
+
+	// the confirmed version is released before the published version, so that sorting by release date differs
+	// from sorting by version
+	for i, version := range []struct {
+		id           string
+		state        string
+		collectionID string
+		releaseDate  string
+	}{
+		{id: publishedVersionID, state: models.PublishedState, releaseDate: "2013-01-30T09:30:00.000Z"},
+		{id: confirmedVersionID, state: models.EditionConfirmedState, collectionID: "collection-1", releaseDate: "2012-06-01T09:30:00.000Z"},
+		{id: unconfirmedVersionID, state: models.CreatedState, releaseDate: "2014-01-01T09:30:00.000Z"},
+	} {
+		self := &models.LinkObject{HRef: host + "/instances/" + version.id}
+		versionLink := &models.LinkObject{HRef: host + "/datasets/People/editions/2011/versions/" + strconv.Itoa(i+1)}
+		err := s.AddVersion(ctx, &models.Version{
+			CollectionID: version.collectionID,
+			Edition:      "2011",
+			ID:           version.id,
+			Links: &models.VersionLinks{
+				Dataset: &models.LinkObject{ID: "People", HRef: host + "/datasets/People"},
+				Self:    self,
+				Version: versionLink,
+			},
+			ReleaseDate: version.releaseDate,
+			State:       version.state,
+			Version:     i + 1,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	var options []*models.DimensionOption
+	for name, values := range map[string][]string{"sex": {"M", "F"}, "age": {"3", "1", "2"}} {
+		for _, value := range values {
+			options = append(options, &models.DimensionOption{
+				InstanceID: publishedVersionID,
+				Label:      name + " " + value,
+				Name:       name,
+				Option:     value,
+			})
+		}
+	}
+
+	return s.AddDimensionOptions(ctx, options)
+}